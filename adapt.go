@@ -0,0 +1,145 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// WithPointerAdaptation lets a field or constructor parameter of type T get the
+// value of injection type *T, dereferenced, and one of type *T get the address
+// of the value of injection type T, when nothing was added for its own type.
+// Otherwise such fields are passed over, and such parameters have no value,
+// which is easy to miss when a constructor returns *Config but a field is a
+// Config.
+//
+// Injection types added for the exact type, or bound to it by interface
+// binding, always take precedence. If both T and **T were added, a *T gets the
+// address of T's value. Every field and parameter getting the address of the
+// same value gets the same pointer, to a copy of the value made the first time
+// it is needed, unless the constructor is transient, when each gets its own.
+// Dereferencing a nil pointer fails with a NilPointerAdapted error.
+func WithPointerAdaptation() Option {
+	return func(p *Psyringe) { p.adaptPointers = true }
+}
+
+// NilPointerAdapted is the error returned when injecting a value of type T
+// from the value of injection type *T, see WithPointerAdaptation, and that
+// value is nil.
+type NilPointerAdapted struct {
+	// Type is the pointer injection type, *T.
+	Type reflect.Type
+}
+
+// Error returns a message like "cannot inject T: value of injection type *T is
+// nil".
+func (e NilPointerAdapted) Error() string {
+	return fmt.Sprintf("cannot inject %s: value of injection type %s is nil", e.Type.Elem(), e.Type)
+}
+
+// adaptation is how the value of one injection type is adapted to the type of
+// a field or parameter, see WithPointerAdaptation.
+type adaptation int
+
+const (
+	// notAdapted uses the value as it is.
+	notAdapted adaptation = iota
+	// addressOf gets a *T from the value of injection type T.
+	addressOf
+	// dereference gets a T from the value of injection type *T.
+	dereference
+)
+
+// adaptedType returns the injection type, added to p or any of its ancestors,
+// whose value can be adapted to type t, and how, if p adapts pointers. p and
+// its ancestors must be read-locked.
+func (p *Psyringe) adaptedType(t reflect.Type) (reflect.Type, adaptation) {
+	if !p.adaptPointers {
+		return nil, notAdapted
+	}
+	if t.Kind() == reflect.Ptr {
+		if it, _ := p.find(t.Elem()); it != nil {
+			return t.Elem(), addressOf
+		}
+	}
+	if it, _ := p.find(reflect.PtrTo(t)); it != nil {
+		return reflect.PtrTo(t), dereference
+	}
+	return nil, notAdapted
+}
+
+// adapt returns v, the value of it, which was added to p, adapted to type t.
+func (p *Psyringe) adapt(v reflect.Value, it *injectionType, t reflect.Type, how adaptation) (reflect.Value, error) {
+	switch how {
+	case dereference:
+		if v.IsNil() {
+			return reflect.Value{}, NilPointerAdapted{Type: reflect.PtrTo(t)}
+		}
+		return v.Elem(), nil
+	case addressOf:
+		if it.Ctor != nil && it.Ctor.transient {
+			ptr := reflect.New(t.Elem())
+			ptr.Elem().Set(v)
+			return ptr, nil
+		}
+		return p.calls.address(it, t, v), nil
+	}
+	return v, nil
+}
+
+// address returns a pointer of type t to a copy of v, the value of it, which
+// is the same pointer each time.
+func (cs *ctorCalls) address(it *injectionType, t reflect.Type, v reflect.Value) reflect.Value {
+	cs.Lock()
+	defer cs.Unlock()
+	if ptr, ok := cs.addressed[it]; ok {
+		return ptr
+	}
+	ptr := reflect.New(t.Elem())
+	ptr.Elem().Set(v)
+	cs.addressed[it] = ptr
+	return ptr
+}
+
+// resolveAdapted is like resolveExact, adapting the value of another
+// injection type to t, if p adapts pointers.
+func (p *Psyringe) resolveAdapted(ctx context.Context, t reflect.Type) (reflect.Value, bool, error) {
+	at, how := p.adaptedType(t)
+	if how == notAdapted {
+		return reflect.Value{}, false, nil
+	}
+	v, _, err := p.resolveExact(ctx, at)
+	if err != nil {
+		return v, true, err
+	}
+	it, depth := p.find(at)
+	v, err = p.ancestor(depth).adapt(v, it, t, how)
+	return v, true, err
+}
+
+// adaptedValue is like fieldPlan.value, for a field whose source is adapted.
+func (fp *fieldPlan) adaptedValue(ctx context.Context, p *Psyringe) (reflect.Value, bool, error) {
+	s := p.ancestor(fp.depth)
+	v := fp.source.Value
+	var err error
+	if c := fp.source.Ctor; c != nil {
+		v, err = c.getValue(ctx, s, ctorPath(ctx))
+	}
+	if err == nil {
+		v, err = s.adapt(v, fp.source, fp.field.Type, fp.adapt)
+	}
+	return v, true, withPathStep(err, fp.step(), "getting field %s (%s) failed", fp.field.Name, fp.field.Type)
+}
+
+// getAdaptedValueForConstructor is like getValueForConstructor, adapting the
+// value of injection type at to t, the type of the parameter.
+func (p *Psyringe) getAdaptedValueForConstructor(ctx context.Context, forCtor *ctor, paramIndex int, t, at reflect.Type, how adaptation, path []*ctor) (reflect.Value, error) {
+	v, err := p.getValueForConstructor(ctx, forCtor, paramIndex, at, path)
+	if err != nil {
+		return v, err
+	}
+	it, depth := p.find(at)
+	v, err = p.ancestor(depth).adapt(v, it, t, how)
+	return v, withPathStep(err, PathStep{Constructor: forCtor.funcType, Param: paramIndex},
+		"getting argument %d failed", paramIndex)
+}
@@ -0,0 +1,96 @@
+package psyringe
+
+import (
+	"errors"
+	"testing"
+)
+
+type adaptConfig struct{ Name string }
+
+type adaptTarget struct {
+	Config    adaptConfig
+	ConfigPtr *adaptConfig
+	Port      *int
+	Other     *int
+}
+
+func TestWithPointerAdaptation(t *testing.T) {
+	p := New(
+		WithPointerAdaptation(),
+		func() *adaptConfig { return &adaptConfig{Name: "app"} },
+		8080,
+		func(port *int, c adaptConfig) string { return c.Name },
+	)
+	var target, other adaptTarget
+	if err := p.Inject(&target, &other); err != nil {
+		t.Fatal(err)
+	}
+	if target.Config.Name != "app" {
+		t.Errorf("got Config %+v; want the dereferenced *adaptConfig", target.Config)
+	}
+	if target.Port == nil || *target.Port != 8080 {
+		t.Fatalf("got Port %v; want address of 8080", target.Port)
+	}
+	if target.Port != target.Other || target.Port != other.Port {
+		t.Errorf("got different pointers to the same value")
+	}
+	var s string
+	if err := p.Realise(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "app" {
+		t.Errorf("got %q; want %q", s, "app")
+	}
+	if err := p.Test(); err != nil {
+		t.Errorf("Test: %s", err)
+	}
+	if err := p.TestTargets(&adaptTarget{}); err != nil {
+		t.Errorf("TestTargets: %s", err)
+	}
+}
+
+func TestWithPointerAdaptation_exactFirst(t *testing.T) {
+	p := New(
+		WithPointerAdaptation(),
+		&adaptConfig{Name: "pointer"},
+		adaptConfig{Name: "value"},
+	)
+	var target adaptTarget
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Config.Name != "value" || target.ConfigPtr.Name != "pointer" {
+		t.Errorf("got %+v and %+v; want exact registrations", target.Config, target.ConfigPtr)
+	}
+}
+
+func TestWithPointerAdaptation_nil(t *testing.T) {
+	p := New(
+		WithPointerAdaptation(),
+		func() *adaptConfig { return nil },
+	)
+	var target struct{ Config adaptConfig }
+	err := p.Inject(&target)
+	want := "inject into *struct { Config psyringe.adaptConfig } target failed: getting field Config (psyringe.adaptConfig) failed: cannot inject psyringe.adaptConfig: value of injection type *psyringe.adaptConfig is nil"
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+	var nilErr NilPointerAdapted
+	if !errors.As(err, &nilErr) {
+		t.Errorf("got %T; want NilPointerAdapted", err)
+	}
+}
+
+func TestWithPointerAdaptation_off(t *testing.T) {
+	p := New(func() *adaptConfig { return &adaptConfig{Name: "app"} })
+	var target adaptTarget
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Config.Name != "" {
+		t.Errorf("got Config %+v; want it left alone", target.Config)
+	}
+}
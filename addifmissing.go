@@ -0,0 +1,76 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AddIfMissing is like AddErr, but skips any constructor or value whose
+// injection type has already been added to p or any of its ancestors, so that
+// libraries can add defaults which applications may have overridden. A
+// constructor with multiple outputs is skipped if any of its injection types
+// has been added. It returns the injection types it added.
+//
+// It is still an error for two arguments to have the same injection type. If
+// any error is returned, nothing is added.
+func (p *Psyringe) AddIfMissing(constructorsAndValues ...interface{}) (added []reflect.Type, err error) {
+	return p.addIfMissing(constructorsAndValues...)
+}
+
+// addIfMissing just exists to make callerinfo consistent in Psyringe.add.
+func (p *Psyringe) addIfMissing(constructorsAndValues ...interface{}) ([]reflect.Type, error) {
+	if err := p.frozenErr(); err != nil {
+		return nil, err
+	}
+	defer p.lock()()
+	argTypes, err := argInjectionTypes(constructorsAndValues)
+	if err != nil {
+		return nil, err
+	}
+	rollback := p.unshare()
+	var added []reflect.Type
+	for i, thing := range constructorsAndValues {
+		if p.anyRegistered(argTypes[i]) {
+			p.debug(DebugEvent{Kind: DebugAddSkipped, Type: reflect.TypeOf(thing)})
+			continue
+		}
+		if err := p.add(thing); err != nil {
+			rollback()
+			return nil, err
+		}
+		added = append(added, argTypes[i]...)
+	}
+	return added, nil
+}
+
+// argInjectionTypes returns the injection types of each of
+// constructorsAndValues, or an error if any is nil, or any two have the same
+// injection type.
+func argInjectionTypes(constructorsAndValues []interface{}) ([][]reflect.Type, error) {
+	argTypes := make([][]reflect.Type, len(constructorsAndValues))
+	argIndex := map[reflect.Type]int{}
+	for i, thing := range constructorsAndValues {
+		if thing == nil {
+			return nil, fmt.Errorf("cannot add nil (argument %d)", i)
+		}
+		argTypes[i] = injectionTypesOf(thing)
+		for _, t := range argTypes[i] {
+			if j, ok := argIndex[t]; ok {
+				return nil, fmt.Errorf("injection type %s provided by arguments %d and %d", t, j, i)
+			}
+			argIndex[t] = i
+		}
+	}
+	return argTypes, nil
+}
+
+// anyRegistered returns true if any of types is registered in p or its
+// ancestors.
+func (p *Psyringe) anyRegistered(types []reflect.Type) bool {
+	for _, t := range types {
+		if _, ok := p.injectionTypeRegistrationScope(t); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestPsyringe_AddIfMissing(t *testing.T) {
+	p := New(1)
+	child := p.Scope("child")
+	child.Add("app")
+
+	added, err := child.AddIfMissing(
+		"default",
+		func() *bytes.Buffer { return bytes.NewBufferString("default") },
+		func() (io.Reader, int) { return nil, 2 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(added), "[*bytes.Buffer]"; got != want {
+		t.Errorf("got added %s; want %s", got, want)
+	}
+	var d dependent
+	child.MustInject(&d)
+	if d.Int != 1 || d.String != "app" || d.Buffer.String() != "default" {
+		t.Errorf("got %+v", d)
+	}
+	if child.Has((*io.Reader)(nil)) {
+		t.Errorf("added io.Reader from constructor with a skipped output")
+	}
+}
+
+func TestPsyringe_AddIfMissing_collision(t *testing.T) {
+	p := New("app")
+	const want = "injection type string provided by arguments 0 and 2"
+	added, err := p.AddIfMissing("default", 1, func() string { return "" })
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if added != nil || p.Has(0) {
+		t.Errorf("added %s after error", added)
+	}
+}
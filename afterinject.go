@@ -0,0 +1,96 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// AddAfterInjectPolicy is what happens when an injection type is added to a
+// Psyringe which has already injected, see SetAddAfterInjectPolicy.
+type AddAfterInjectPolicy int
+
+const (
+	// AllowAddAfterInject adds injection types as usual, whenever they are
+	// added. This is the default.
+	AllowAddAfterInject AddAfterInjectPolicy = iota
+	// WarnAddAfterInject adds injection types as usual, but calls the
+	// AddedAfterInject hook for each one added after injecting.
+	WarnAddAfterInject
+	// RejectAddAfterInject refuses to add injection types after injecting,
+	// returning an AddedAfterInject error from AddErr and the like instead.
+	RejectAddAfterInject
+)
+
+// SetAddAfterInjectPolicy sets what happens when an injection type is added
+// to this Psyringe after it has injected, see Injected. Targets injected
+// before and after such an addition may see different values, which is
+// rarely intended once an application is running. The policy applies to
+// anything added, including by AddNamed, AddToSlice, AddOverride and
+// Replace, and is inherited by clones and scopes subsequently created from
+// this Psyringe.
+func (p *Psyringe) SetAddAfterInjectPolicy(policy AddAfterInjectPolicy) {
+	defer p.lock()()
+	p.addAfterInject = policy
+}
+
+// WithAddAfterInjectPolicy is an Option doing the same as
+// SetAddAfterInjectPolicy.
+func WithAddAfterInjectPolicy(policy AddAfterInjectPolicy) Option {
+	return func(p *Psyringe) { p.addAfterInject = policy }
+}
+
+// Injected reports whether this Psyringe has injected into any target, or
+// called any of its constructors, since it was created. Clones have not
+// injected, even if the Psyringe they were cloned from has.
+func (p *Psyringe) Injected() bool {
+	defer p.rlockChain()()
+	return p.injected()
+}
+
+// injected is Injected, for when p is already locked. It is recorded with
+// p's calls, so that clones, which have calls of their own, start afresh.
+func (p *Psyringe) injected() bool {
+	return atomic.LoadInt32(&p.calls.injected) == 1
+}
+
+// markInjected records that p has injected, see Injected. p must be locked.
+func (p *Psyringe) markInjected() {
+	// Loading first saves every injection writing to the same memory.
+	if atomic.LoadInt32(&p.calls.injected) == 0 {
+		atomic.StoreInt32(&p.calls.injected, 1)
+	}
+}
+
+// AddedAfterInject is the error returned when adding injection type Type to
+// a Psyringe which has already injected, with RejectAddAfterInject.
+type AddedAfterInject struct {
+	Type reflect.Type
+	// Scope is the path of the scope added to, like "<root>/child", or empty
+	// for an unnamed root Psyringe.
+	Scope string
+}
+
+// Error returns a message like "cannot add int after injecting (scope
+// <root>/child)".
+func (e AddedAfterInject) Error() string {
+	if e.Scope != "" {
+		return fmt.Sprintf("cannot add %s after injecting (scope %s)", e.Type, e.Scope)
+	}
+	return fmt.Sprintf("cannot add %s after injecting", e.Type)
+}
+
+// checkAddAfterInject applies p's AddAfterInjectPolicy to adding injection
+// type t. p must be write-locked.
+func (p *Psyringe) checkAddAfterInject(t reflect.Type) error {
+	if p.addAfterInject == AllowAddAfterInject || !p.injected() {
+		return nil
+	}
+	if p.addAfterInject == RejectAddAfterInject {
+		return AddedAfterInject{Type: t, Scope: p.errScope()}
+	}
+	if p.Hooks.AddedAfterInject != nil {
+		p.Hooks.AddedAfterInject(t)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package psyringe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type (
+	lateA string
+	lateB string
+)
+
+func TestPsyringe_SetAddAfterInjectPolicy(t *testing.T) {
+	p := New(WithAddAfterInjectPolicy(RejectAddAfterInject), lateA("a"))
+	if err := p.AddErr(func() lateB { return "b" }); err != nil {
+		t.Fatalf("adding before injecting failed: %s", err)
+	}
+	if p.Injected() {
+		t.Fatalf("Injected before injecting")
+	}
+	var target struct{ A lateA }
+	p.MustInject(&target)
+	if !p.Injected() {
+		t.Fatalf("not Injected after injecting")
+	}
+	const want = "adding int value failed: cannot add int after injecting"
+	if err := p.AddErr(0); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if err := p.AddNamedErr("n", 0); !errors.As(err, &AddedAfterInject{}) {
+		t.Errorf("got AddNamedErr error %v; want AddedAfterInject", err)
+	}
+	if err := p.AddToSlice(0); !errors.As(err, &AddedAfterInject{}) {
+		t.Errorf("got AddToSlice error %v; want AddedAfterInject", err)
+	}
+
+	clone := p.Clone()
+	if clone.Injected() {
+		t.Errorf("clone Injected before injecting")
+	}
+	if err := clone.AddErr(0); err != nil {
+		t.Errorf("adding to clone failed: %s", err)
+	}
+	if !p.Injected() {
+		t.Errorf("original not Injected after cloning")
+	}
+}
+
+func TestPsyringe_SetAddAfterInjectPolicy_warn(t *testing.T) {
+	var added []reflect.Type
+	p := New(WithAddAfterInjectPolicy(WarnAddAfterInject), func() lateA { return "a" })
+	p.Hooks.AddedAfterInject = func(t reflect.Type) { added = append(added, t) }
+	p.Add(lateB("early"))
+	// Getting a constructor's value counts as injecting.
+	if _, err := Get[lateA](p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddErr(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || added[0] != reflect.TypeOf(0) {
+		t.Errorf("got hook calls for %v; want [int]", added)
+	}
+}
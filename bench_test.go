@@ -97,6 +97,17 @@ func BenchmarkMustInject_BestCase(b *testing.B) {
 	}
 }
 
+// BenchmarkMustInject_Frozen is like BenchmarkMustInject_WorstCase, with the
+// psyringe frozen first, so that injecting need not lock it.
+func BenchmarkMustInject_Frozen(b *testing.B) {
+	P = New(worstCaseConstructors...)
+	P.Freeze()
+	S = BenchStruct{}
+	for i := 0; i < b.N; i++ {
+		P.MustInject(&S)
+	}
+}
+
 // BenchmarkNewMustInject is testing the complete cycle of creating a new
 // psyringe and injecting with it. This benchmark exists primarily to compare
 // New with Clone.
@@ -134,3 +145,28 @@ func BenchmarkCloneMustInject_BestCase(b *testing.B) {
 		P.Clone().MustInject(&S)
 	}
 }
+
+// BenchmarkClonePoolMustInject is like BenchmarkCloneMustInject, getting each
+// clone from a ClonePool and putting it back afterwards, as a web server
+// might for each request.
+func BenchmarkClonePoolMustInject_WorstCase(b *testing.B) {
+	P = New(worstCaseConstructors...)
+	S = BenchStruct{}
+	pool := P.NewPool()
+	for i := 0; i < b.N; i++ {
+		q := pool.Get()
+		q.MustInject(&S)
+		pool.Put(q)
+	}
+}
+
+func BenchmarkClonePoolMustInject_BestCase(b *testing.B) {
+	P = New(bestCaseConstructors...)
+	S = BenchStruct{}
+	pool := P.NewPool()
+	for i := 0; i < b.N; i++ {
+		q := pool.Get()
+		q.MustInject(&S)
+		pool.Put(q)
+	}
+}
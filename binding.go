@@ -0,0 +1,204 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EnableInterfaceBinding turns on interface binding for this Psyringe, and any
+// clones or scopes subsequently created from it.
+//
+// With interface binding enabled, when a value of some interface type is
+// needed, either for a struct field or a constructor parameter, and that exact
+// interface type has not been added, the Psyringe looks for an added injection
+// type which implements the interface instead. If there is exactly one such
+// type, its value is used. If there is more than one, an
+// AmbiguousInterfaceBinding error is returned. If there are none, the field or
+// parameter is treated as if interface binding were off.
+func (p *Psyringe) EnableInterfaceBinding() {
+	defer p.lock()()
+	p.interfaceBinding = true
+	p.invalidatePlans()
+}
+
+// AmbiguousInterfaceBinding is the error returned when interface binding is
+// enabled, and more than one injection type implements a needed interface.
+type AmbiguousInterfaceBinding struct {
+	// Interface is the interface type needed.
+	Interface reflect.Type
+	// Candidates are the injection types implementing Interface, sorted by
+	// name.
+	Candidates []reflect.Type
+}
+
+// Error returns a message listing the candidate types.
+func (e AmbiguousInterfaceBinding) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, t := range e.Candidates {
+		names[i] = t.String()
+	}
+	return "ambiguous interface binding for " + e.Interface.String() +
+		": implemented by " + strings.Join(names, ", ")
+}
+
+// boundType returns the single injection type in its, which implements t when
+// interface binding is enabled. It returns nil if binding is disabled, if t is
+// not an interface, or no injection type implements t. If more than one
+// injection type implements t, it returns an AmbiguousInterfaceBinding error.
+func (p *Psyringe) boundType(t reflect.Type, its ...injectionTypes) (reflect.Type, error) {
+	if !p.interfaceBinding || t.Kind() != reflect.Interface {
+		return nil, nil
+	}
+	var candidates []reflect.Type
+	found := map[reflect.Type]bool{}
+	for _, types := range its {
+		for it := range types {
+			// Note: a child scope may shadow the same type as its parent.
+			if it.Implements(t) && !found[it] {
+				found[it] = true
+				candidates = append(candidates, it)
+			}
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	default:
+		sortTypes(candidates)
+		return nil, AmbiguousInterfaceBinding{Interface: t, Candidates: candidates}
+	}
+}
+
+// scopeChainInjectionTypes returns the injection types of p and each of its
+// ancestors, starting with p.
+func (p *Psyringe) scopeChainInjectionTypes() []injectionTypes {
+	var its []injectionTypes
+	for q := p; q != nil; q = q.parent {
+		its = append(its, q.injectionTypes)
+	}
+	return its
+}
+
+// scopedInjectionType is an injection type visible from a Psyringe, see
+// visibleInjectionTypes.
+type scopedInjectionType struct {
+	// name is the name of a named injection type, or "".
+	name string
+	t    reflect.Type
+	it   *injectionType
+	// scope is the Psyringe t was added to.
+	scope *Psyringe
+}
+
+// visibleInjectionTypes returns each injection type added to p or its
+// ancestors, including slice types, with the scope which added it, unless a
+// nearer scope added the same one. Named injection types are included too if
+// named is true. p and its ancestors must be read-locked.
+func (p *Psyringe) visibleInjectionTypes(named bool) []scopedInjectionType {
+	type key struct {
+		name string
+		t    reflect.Type
+	}
+	var visible []scopedInjectionType
+	seen := map[key]bool{}
+	add := func(q *Psyringe, name string, its injectionTypes) {
+		for t, it := range its {
+			// A child scope's injection type shadows its parent's.
+			if k := (key{name, t}); !seen[k] {
+				seen[k] = true
+				visible = append(visible, scopedInjectionType{name: name, t: t, it: it, scope: q})
+			}
+		}
+	}
+	for q := p; q != nil; q = q.parent {
+		add(q, "", q.injectionTypesWithSlices())
+		if !named {
+			continue
+		}
+		for name, its := range q.named {
+			add(q, name, its)
+		}
+	}
+	return visible
+}
+
+// Binding is a constructor or value to be added to a Psyringe under an
+// interface injection type, rather than the type it actually produces. Create
+// Bindings using Bind or BindAs, and pass them to New, Add, etc.
+type Binding struct {
+	iface reflect.Type
+	thing interface{}
+	err   error
+}
+
+// Bind returns a Binding for constructorOrValue, whose injection type will be
+// the interface type ifacePtr points to. For example, the following adds a
+// constructor with injection type io.Writer, rather than *bytes.Buffer:
+//
+//     p.Add(psyringe.Bind((*io.Writer)(nil), func() *bytes.Buffer {
+//         return &bytes.Buffer{}
+//     }))
+//
+// Adding the Binding fails if the value or constructor's output type does not
+// implement the interface.
+func Bind(ifacePtr interface{}, constructorOrValue interface{}) Binding {
+	t := reflect.TypeOf(ifacePtr)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		return Binding{err: fmt.Errorf("cannot bind to %T: want a pointer to an interface type", ifacePtr)}
+	}
+	return Binding{iface: t.Elem(), thing: constructorOrValue}
+}
+
+// BindAs is a generic version of Bind, binding constructorOrValue to the
+// interface type I.
+func BindAs[I any](constructorOrValue interface{}) Binding {
+	return Bind((*I)(nil), constructorOrValue)
+}
+
+// bound returns a ctor or value for b, whose type is b's interface type.
+func (b Binding) bound() (*ctor, reflect.Value, error) {
+	if b.err != nil {
+		return nil, reflect.Value{}, b.err
+	}
+	if b.thing == nil {
+		return nil, reflect.Value{}, fmt.Errorf("cannot bind nil to %s", b.iface)
+	}
+	v := reflect.ValueOf(b.thing)
+	ctors := newCtors(v.Type(), v)
+	if len(ctors) > 1 {
+		return nil, reflect.Value{}, fmt.Errorf("cannot bind %s to %s: constructor has %d outputs",
+			v.Type(), b.iface, len(ctors))
+	}
+	t := v.Type()
+	var c *ctor
+	if len(ctors) == 1 {
+		c = ctors[0]
+		t = c.outType
+	}
+	if !t.Implements(b.iface) {
+		return nil, reflect.Value{}, fmt.Errorf("cannot bind %s to %s: %s does not implement %s",
+			v.Type(), b.iface, t, b.iface)
+	}
+	if c == nil {
+		iv := reflect.New(b.iface).Elem()
+		iv.Set(v)
+		return nil, iv, nil
+	}
+	construct := c.construct
+	c.outType = b.iface
+	c.outTypes = []reflect.Type{b.iface}
+	c.construct = func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error) {
+		out, cleanup, err := construct(ctx, in)
+		if err != nil && (out == nil || !isWarning(err)) {
+			return out, cleanup, err
+		}
+		iv := reflect.New(b.iface).Elem()
+		iv.Set(out[0])
+		return []reflect.Value{iv}, cleanup, err
+	}
+	return c, reflect.Value{}, nil
+}
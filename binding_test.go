@@ -0,0 +1,184 @@
+package psyringe
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type writerTarget struct {
+	Writer io.Writer
+}
+
+func TestPsyringe_EnableInterfaceBinding(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	// Without binding, nothing is injected.
+	p := New(buf)
+	var target writerTarget
+	p.MustInject(&target)
+	if target.Writer != nil {
+		t.Fatalf("got %v; want nil before EnableInterfaceBinding", target.Writer)
+	}
+
+	p.EnableInterfaceBinding()
+	p.MustInject(&target)
+	if target.Writer != buf {
+		t.Errorf("got %v; want the *bytes.Buffer", target.Writer)
+	}
+}
+
+func TestPsyringe_EnableInterfaceBinding_constructorParams(t *testing.T) {
+	p := New()
+	p.EnableInterfaceBinding()
+	p.Add(
+		func() *strings.Reader { return strings.NewReader("hello") },
+		func(r io.Reader) (string, error) {
+			b, err := io.ReadAll(r)
+			return string(b), err
+		},
+	)
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct{ String string }
+	p.MustInject(&target)
+	if target.String != "hello" {
+		t.Errorf("got %q; want %q", target.String, "hello")
+	}
+}
+
+func TestPsyringe_EnableInterfaceBinding_scopes(t *testing.T) {
+	root := New(&bytes.Buffer{})
+	root.EnableInterfaceBinding()
+	var target writerTarget
+	root.Scope("child").Clone().MustInject(&target)
+	if target.Writer == nil {
+		t.Errorf("io.Writer not injected from parent scope")
+	}
+}
+
+func TestPsyringe_EnableInterfaceBinding_exactWins(t *testing.T) {
+	exact := &bytes.Buffer{}
+	p := New(&strings.Builder{}, func() io.Writer { return exact })
+	p.EnableInterfaceBinding()
+	var target writerTarget
+	p.MustInject(&target)
+	if target.Writer != exact {
+		t.Errorf("got %v; want exact io.Writer registration", target.Writer)
+	}
+}
+
+func TestPsyringe_EnableInterfaceBinding_ambiguous(t *testing.T) {
+	p := New(&bytes.Buffer{}, &strings.Builder{}, func(io.Writer) int { return 1 })
+	p.EnableInterfaceBinding()
+
+	const wantCause = "ambiguous interface binding for io.Writer: implemented by *bytes.Buffer, *strings.Builder"
+
+	err := p.Inject(&writerTarget{})
+	if err == nil {
+		t.Fatalf("got nil error; want %q", wantCause)
+	}
+	ab, ok := errors.Cause(err).(AmbiguousInterfaceBinding)
+	if !ok {
+		t.Fatalf("got error %q; want AmbiguousInterfaceBinding", err)
+	}
+	if got := ab.Error(); got != wantCause {
+		t.Errorf("got %q; want %q", got, wantCause)
+	}
+
+	err = p.Test()
+	want := "unable to satisfy constructor func(io.Writer) int: unable to satisfy param 0: " + wantCause
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+}
+
+func TestBind(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var calls Counter
+	p := New(
+		Bind((*io.Writer)(nil), func() *bytes.Buffer {
+			calls.Increment()
+			return buf
+		}),
+		BindAs[io.Reader](strings.NewReader("hello")),
+		func(w io.Writer, r io.Reader) (int64, error) { return io.Copy(w, r) },
+	)
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Writer io.Writer
+		Buffer *bytes.Buffer
+		Int64  int64
+	}
+	p.MustInject(&target)
+	if target.Writer != buf {
+		t.Errorf("got %v; want the bound *bytes.Buffer", target.Writer)
+	}
+	if target.Buffer != nil {
+		t.Errorf("*bytes.Buffer injected; want it only bound to io.Writer")
+	}
+	if target.Int64 != 5 || buf.String() != "hello" {
+		t.Errorf("got %d bytes %q; want 5 bytes %q", target.Int64, buf, "hello")
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+	if w := MustGet[io.Writer](p); w != buf {
+		t.Errorf("Get returned %v; want the bound *bytes.Buffer", w)
+	}
+}
+
+func TestBind_errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		binding Binding
+		wantErr string
+	}{
+		{
+			desc:    "not an interface pointer",
+			binding: Bind((*bytes.Buffer)(nil), 1),
+			wantErr: "cannot bind to *bytes.Buffer: want a pointer to an interface type",
+		},
+		{
+			desc:    "value does not implement",
+			binding: BindAs[io.Reader](1),
+			wantErr: "cannot bind int to io.Reader: int does not implement io.Reader",
+		},
+		{
+			desc:    "constructor does not implement",
+			binding: BindAs[io.Closer](func() *bytes.Buffer { return nil }),
+			wantErr: "cannot bind func() *bytes.Buffer to io.Closer: *bytes.Buffer does not implement io.Closer",
+		},
+		{
+			desc:    "nil",
+			binding: BindAs[io.Reader](nil),
+			wantErr: "cannot bind nil to io.Reader",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := New().AddErr(tc.binding)
+			if err == nil {
+				t.Fatalf("got nil error; want %q", tc.wantErr)
+			}
+			if got := err.Error(); got != tc.wantErr {
+				t.Errorf("got error %q; want %q", got, tc.wantErr)
+			}
+		})
+	}
+
+	err := New(func() io.Writer { return nil }).AddErr(BindAs[io.Writer](&bytes.Buffer{}))
+	want := "adding *bytes.Buffer value bound to io.Writer failed: injection type io.Writer already registered at "
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got error %v; want prefix %q", err, want)
+	}
+}
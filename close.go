@@ -0,0 +1,137 @@
+package psyringe
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Close tears down values created by constructors of this Psyringe. For each
+// constructor which has been called successfully, Close calls the cleanup func
+// it returned, if any, or else calls Close on each of its outputs which
+// implements io.Closer. Values added directly are never closed, since the
+// Psyringe did not create them.
+//
+// Values are closed in reverse dependency order, so that each value is closed
+// before any value it was constructed from. Constructors not yet called, or
+// still being called, are skipped.
+//
+// A Psyringe only closes values it created itself: values shared with the
+// Psyringe it was cloned from, and values belonging to parent scopes, are left
+// alone.
+//
+// Close is idempotent, each value is closed at most once. All errors are
+// collected and returned as CloseErrors if there is more than one. If ctx is
+// done before all values are closed, Close stops and returns ctx.Err(); the
+// remaining values can be closed by calling Close again.
+func (p *Psyringe) Close(ctx context.Context) error {
+	defer p.rlockChain()()
+	var errs CloseErrors
+	for _, c := range p.closeOrder() {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := p.calls.lookup(c.ctorFunc).close(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "closing %s failed", c.outTypes[0]))
+		}
+	}
+	return errs.errOrNil()
+}
+
+// closeOrder returns the constructors whose realised calls are owned by p, one
+// for each call, each appearing before any of the constructors it depends on.
+func (p *Psyringe) closeOrder() []*ctor {
+	var ctors []*ctor
+	add := func(its injectionTypes) {
+		for _, t := range its.AddedAsCtors().Keys() {
+			if c := its[t].Ctor; p.ownsRealised(c) {
+				ctors = append(ctors, c)
+			}
+		}
+	}
+	add(p.injectionTypes)
+	add(p.slices.injectionTypes())
+	for _, name := range sortedNames(p.named) {
+		add(p.named[name])
+	}
+	// Dependencies are visited before dependents, then the order is reversed.
+	var order []*ctor
+	visited := map[*ctorFunc]bool{}
+	var visit func(c *ctor)
+	visit = func(c *ctor) {
+		if visited[c.ctorFunc] {
+			return
+		}
+		visited[c.ctorFunc] = true
+		for _, t := range c.inTypes {
+			if dep := p.dependency(t); dep != nil && p.ownsRealised(dep) {
+				visit(dep)
+			}
+		}
+		order = append(order, c)
+	}
+	for _, c := range ctors {
+		visit(c)
+	}
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
+
+// ownsRealised returns true if p called c, and c succeeded.
+func (p *Psyringe) ownsRealised(c *ctor) bool {
+	call := p.calls.lookup(c.ctorFunc)
+	return call != nil && call.realised() && call.owner == p
+}
+
+// dependency returns the constructor p uses for a parameter of type t, or nil
+// if t is not provided by a constructor of p.
+func (p *Psyringe) dependency(t reflect.Type) *ctor {
+	if it, ok := p.injectionTypes[t]; ok {
+		return it.Ctor
+	}
+	if s, ok := p.slices[t]; ok {
+		return s.it.Ctor
+	}
+	if bt, err := p.boundType(t, p.injectionTypes); err == nil && bt != nil {
+		return p.injectionTypes[bt].Ctor
+	}
+	return nil
+}
+
+// close calls the cleanup func of c if it has one, or else closes each of its
+// values implementing io.Closer. Only the first call to close has any effect.
+func (c *ctorCall) close() error {
+	var errs CloseErrors
+	c.closeOnce.Do(func() {
+		if c.cleanup != nil {
+			c.cleanup()
+			return
+		}
+		for _, v := range c.values {
+			if !v.IsValid() || !v.CanInterface() || isNil(v) {
+				continue
+			}
+			if closer, ok := v.Interface().(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	})
+	return errs.errOrNil()
+}
+
+// isNil reports whether v holds a nil pointer, interface, or other nillable
+// value.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type (
+	closeDB     struct{ log *closeLog }
+	closeRepo   struct{ log *closeLog }
+	closeServer struct{ log *closeLog }
+	closeLog    struct {
+		sync.Mutex
+		closed []string
+	}
+	closeFailer         struct{ name string }
+	closeFailerStringer struct{ closeFailer }
+)
+
+func (l *closeLog) add(name string) {
+	l.Lock()
+	defer l.Unlock()
+	l.closed = append(l.closed, name)
+}
+
+func (db *closeDB) Close() error {
+	db.log.add("db")
+	return nil
+}
+
+func (s *closeServer) Close() error {
+	s.log.add("server")
+	return nil
+}
+
+func (f *closeFailer) Close() error {
+	return fmt.Errorf("%s failed", f.name)
+}
+
+func (*closeFailerStringer) String() string { return "" }
+
+func newClosePsyringe(log *closeLog) *Psyringe {
+	return New(
+		log,
+		func(log *closeLog) *closeDB { return &closeDB{log} },
+		func(db *closeDB) (*closeRepo, func(), error) {
+			return &closeRepo{db.log}, func() { db.log.add("repo") }, nil
+		},
+		func(r *closeRepo) *closeServer { return &closeServer{r.log} },
+	)
+}
+
+func TestPsyringe_Close(t *testing.T) {
+	log := &closeLog{}
+	p := newClosePsyringe(log)
+	MustGet[*closeServer](p)
+
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"server", "repo", "db"}
+	if !reflect.DeepEqual(log.closed, want) {
+		t.Errorf("got closed %v; want %v", log.closed, want)
+	}
+}
+
+func TestPsyringe_Close_onlyRealised(t *testing.T) {
+	log := &closeLog{}
+	p := newClosePsyringe(log)
+	MustGet[*closeDB](p)
+
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"db"}
+	if !reflect.DeepEqual(log.closed, want) {
+		t.Errorf("got closed %v; want %v", log.closed, want)
+	}
+}
+
+func TestPsyringe_Close_clone(t *testing.T) {
+	log := &closeLog{}
+	p := newClosePsyringe(log)
+	MustGet[*closeDB](p)
+	clone := p.Clone()
+	MustGet[*closeServer](clone)
+
+	if err := clone.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"server", "repo"}
+	if !reflect.DeepEqual(log.closed, want) {
+		t.Errorf("clone closed %v; want %v", log.closed, want)
+	}
+
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, "db")
+	if !reflect.DeepEqual(log.closed, want) {
+		t.Errorf("got closed %v; want %v", log.closed, want)
+	}
+}
+
+func TestPsyringe_Close_errors(t *testing.T) {
+	p := New(
+		func() *closeFailer { return &closeFailer{"pointer"} },
+		func() (int, io.Closer, error) { return 1, &closeFailer{"second"}, nil },
+	)
+	MustGet[*closeFailer](p)
+
+	err := p.Close(context.Background())
+	if err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	want := "closing *psyringe.closeFailer failed: pointer failed"
+	if err.Error() != want {
+		t.Errorf("got error %q; want %q", err, want)
+	}
+
+	MustGet[int](p)
+	err = p.Close(context.Background())
+	if err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	want = "closing int failed: second failed"
+	if err.Error() != want {
+		t.Errorf("got error %q; want %q", err, want)
+	}
+}
+
+func TestPsyringe_Close_multipleErrors(t *testing.T) {
+	p := New(
+		func() *closeFailer { return &closeFailer{"dependency"} },
+		func(*closeFailer) string { return "" },
+		func(*closeFailer) (int, func(), error) { return 0, func() {}, nil },
+		func(string) fmt.Stringer { return &closeFailerStringer{closeFailer{"dependent"}} },
+	)
+	MustGet[fmt.Stringer](p)
+	MustGet[int](p)
+
+	err := p.Close(context.Background())
+	ce, ok := err.(CloseErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want CloseErrors", err, err)
+	}
+	want := []string{
+		"closing fmt.Stringer failed: dependent failed",
+		"closing *psyringe.closeFailer failed: dependency failed",
+	}
+	if len(ce.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ce.Errors()), len(want), ce)
+	}
+	for i, err := range ce.Errors() {
+		if err.Error() != want[i] {
+			t.Errorf("got error %d %q; want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestPsyringe_Close_cancelled(t *testing.T) {
+	log := &closeLog{}
+	p := newClosePsyringe(log)
+	MustGet[*closeServer](p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Close(ctx); err != context.Canceled {
+		t.Errorf("got error %v; want %v", err, context.Canceled)
+	}
+	if len(log.closed) != 0 {
+		t.Errorf("got closed %v; want nothing", log.closed)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"server", "repo", "db"}
+	if !reflect.DeepEqual(log.closed, want) {
+		t.Errorf("got closed %v; want %v", log.closed, want)
+	}
+}
@@ -0,0 +1,29 @@
+package psyringe
+
+import "time"
+
+// Collector receives metrics about constructor calls and injections, for
+// example to export them for monitoring. See the expvarcollector package for
+// an implementation using expvar. Collectors may be called concurrently.
+type Collector interface {
+	// CtorInvoked is called after each constructor returns, with the name of
+	// the injection type constructed, how long it took, and any error it
+	// returned.
+	CtorInvoked(outType string, duration time.Duration, err error)
+	// Injected is called after injecting into each target passed to Inject,
+	// with the name of the target's type, how long it took, and any error.
+	Injected(targetType string, duration time.Duration, err error)
+}
+
+// SetCollector sets the Collector for this Psyringe, and any clones or scopes
+// subsequently created from it. Pass nil to stop collecting. When no Collector
+// is set, collecting costs nothing more than checking for one.
+func (p *Psyringe) SetCollector(c Collector) {
+	defer p.lock()()
+	p.collector = c
+}
+
+// WithCollector is an Option doing the same as SetCollector.
+func WithCollector(c Collector) Option {
+	return func(p *Psyringe) { p.collector = c }
+}
@@ -0,0 +1,46 @@
+package psyringe
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testCollector struct {
+	sync.Mutex
+	ctors, injected []string
+}
+
+func (c *testCollector) CtorInvoked(outType string, d time.Duration, err error) {
+	c.Lock()
+	defer c.Unlock()
+	c.ctors = append(c.ctors, outType)
+}
+
+func (c *testCollector) Injected(targetType string, d time.Duration, err error) {
+	c.Lock()
+	defer c.Unlock()
+	c.injected = append(c.injected, targetType)
+}
+
+func TestPsyringe_SetCollector(t *testing.T) {
+	c := &testCollector{}
+	p := New(func() int { return 1 })
+	p.SetCollector(c)
+	child := p.Scope("child")
+	child.Add(func(i int) string { return "" })
+	var target struct {
+		Int    int
+		String string
+	}
+	child.Clone().MustInject(&target)
+	if len(c.ctors) != 2 || len(c.injected) != 1 || c.injected[0] != "*struct { Int int; String string }" {
+		t.Errorf("got constructors %q and injections %q", c.ctors, c.injected)
+	}
+
+	p.SetCollector(nil)
+	p.Clone().MustInject(&target)
+	if len(c.injected) != 1 {
+		t.Errorf("collected after SetCollector(nil)")
+	}
+}
@@ -0,0 +1,60 @@
+package psyringe
+
+import (
+	"context"
+	"reflect"
+)
+
+// contextKey is the key NewContext stores a Psyringe under.
+type contextKey struct{}
+
+// tpsyringe is the type "*psyringe.Psyringe".
+var tpsyringe = reflect.TypeOf((*Psyringe)(nil))
+
+// NewContext returns a copy of ctx carrying p, which can be got back with
+// FromContext. This lets middleware pass the Psyringe made for a request down
+// to code which needs to inject or get more values.
+func NewContext(ctx context.Context, p *Psyringe) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the Psyringe carried by ctx, see NewContext. It returns
+// false if ctx carries none.
+func FromContext(ctx context.Context) (*Psyringe, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Psyringe)
+	return p, ok
+}
+
+// WithContextValues makes the context.Context passed to InjectCtx, and the
+// *Psyringe injecting, available as values for the duration of that call, as
+// if added to a scope made for it. Fields and constructor parameters of type
+// context.Context or *Psyringe which have no other injection type then get
+// them. The context also carries the Psyringe, see FromContext.
+//
+// As with a leading context.Context parameter, a constructor is only called
+// with the context of the first call needing its value, so this is best used
+// with a Clone of the Psyringe made for each context.
+func WithContextValues() Option {
+	return func(p *Psyringe) { p.contextValues = true }
+}
+
+// contextValue returns the value of t provided by ctx, if p was created
+// WithContextValues and t is context.Context or *Psyringe.
+func (p *Psyringe) contextValue(ctx context.Context, t reflect.Type) (reflect.Value, bool) {
+	if !p.providesFromContext(t) {
+		return reflect.Value{}, false
+	}
+	if t == tcontext {
+		return reflect.ValueOf(&ctx).Elem(), true
+	}
+	if q, ok := FromContext(ctx); ok {
+		return reflect.ValueOf(q), true
+	}
+	return reflect.ValueOf(p), true
+}
+
+// providesFromContext reports whether values of t are provided by the context
+// passed to InjectCtx, see WithContextValues.
+func (p *Psyringe) providesFromContext(t reflect.Type) bool {
+	return p.contextValues && (t == tcontext || t == tpsyringe)
+}
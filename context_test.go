@@ -0,0 +1,86 @@
+package psyringe
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Errorf("got a Psyringe from an empty context")
+	}
+	p := New()
+	got, ok := FromContext(NewContext(context.Background(), p))
+	if !ok || got != p {
+		t.Errorf("got %p, %t; want %p, true", got, ok, p)
+	}
+}
+
+type requestID string
+
+func TestWithContextValues(t *testing.T) {
+	type carried struct{ P *Psyringe }
+	p := New(WithContextValues(),
+		// Not a leading parameter, so resolved as an injection type.
+		func(s string, ctx context.Context) requestID {
+			id, _ := ctx.Value(ctxKey{}).(string)
+			return requestID(s + id)
+		},
+		func(q *Psyringe) (carried, error) {
+			return carried{q}, nil
+		},
+		"request-",
+	)
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	q := p.Clone()
+	ctx := context.WithValue(context.Background(), ctxKey{}, "42")
+	var target struct {
+		ID      requestID
+		Ctx     context.Context
+		Carried carried
+	}
+	if err := q.InjectCtx(ctx, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.ID != "request-42" {
+		t.Errorf("got ID %q; want %q", target.ID, "request-42")
+	}
+	if got, _ := target.Ctx.Value(ctxKey{}).(string); got != "42" {
+		t.Errorf("got context carrying %q; want %q", got, "42")
+	}
+	if got, ok := FromContext(target.Ctx); !ok || got != q {
+		t.Errorf("context carries %p; want the clone %p", got, q)
+	}
+	if target.Carried.P != q {
+		t.Errorf("got Psyringe %p; want the clone %p", target.Carried.P, q)
+	}
+}
+
+func TestWithContextValues_registeredWins(t *testing.T) {
+	type key struct{}
+	registered := context.WithValue(context.Background(), key{}, "registered")
+	p := New(WithContextValues(), Bind((*context.Context)(nil), registered))
+	var target struct{ Ctx context.Context }
+	if err := p.InjectCtx(context.Background(), &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Ctx != registered {
+		t.Errorf("got a context other than the one added")
+	}
+}
+
+func TestWithContextValues_off(t *testing.T) {
+	p := New(func(q *Psyringe) requestID { return "" })
+	if err := p.Test(); err == nil {
+		t.Errorf("got nil error; want *Psyringe parameter to be unsatisfied")
+	}
+	var target struct{ Ctx context.Context }
+	if err := p.InjectCtx(context.Background(), &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Ctx != nil {
+		t.Errorf("got context injected without WithContextValues")
+	}
+}
@@ -1,129 +1,612 @@
 package psyringe
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-// ctor is a constructor for a single value.
+// ctor is a constructor for a single value. Constructors with multiple outputs
+// are represented by one ctor per output, all sharing a single ctorFunc so
+// that the constructor is still called at most once. A ctor never changes once
+// created, so it can be shared between clones; the state of calling it is kept
+// separately by each Psyringe, see ctorCalls.
 type ctor struct {
 	outType, funcType reflect.Type
 	inTypes           []reflect.Type
-	construct         func(in []reflect.Value) (reflect.Value, error)
-	errChan           chan error
-	onceManifest      *sync.Once
-	onceResult        *sync.Once
-	value             *reflect.Value
+	// outIndex is the index of outType in the outputs of the constructor.
+	outIndex int
+	*ctorFunc
 }
 
-// terror is the type "error"
-var terror = reflect.TypeOf((*error)(nil)).Elem()
+// ctorFunc is a constructor function, shared by the ctors for each of its
+// outputs.
+type ctorFunc struct {
+	// outTypes are the injection types of all the constructor's outputs.
+	outTypes  []reflect.Type
+	construct func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error)
+	// returnsCleanup is true if the constructor returns a cleanup func.
+	returnsCleanup bool
+	// transient is true if the constructor is called every time one of its
+	// values is needed, see AddTransient.
+	transient bool
+	// optionalIn is true for each parameter which is passed its zero value
+	// if it has no injection type, like a variadic parameter, or see
+	// AddStruct. It is nil if there are none.
+	optionalIn []bool
+}
+
+// ctorCall is the state of a single call to a constructor.
+type ctorCall struct {
+	// started is set to 1 by start, and only ever accessed atomically.
+	started    int32
+	onceResult sync.Once
+	// done is closed once values, cleanup, err, owner and at have been set,
+	// after which none change for the lifetime of this ctorCall.
+	done    chan struct{}
+	values  []reflect.Value
+	cleanup func()
+	err     error
+	// owner is the Psyringe which called the constructor, and is therefore
+	// responsible for closing its values, see Psyringe.Close.
+	owner *Psyringe
+	// at is when the constructor returned, see Psyringe.SetTTL.
+	at time.Time
+	// duration is how long the constructor took, or 0 if it was not called,
+	// see DebugHandler. It is set before done is closed.
+	duration  time.Duration
+	closeOnce sync.Once
+	// waitingOn are the calls the constructor's arguments are waiting for,
+	// guarded by waits.
+	waitingOn []waitEdge
+}
+
+// waits guards the waitingOn field of every ctorCall, so that a cycle of
+// calls waiting for each other is seen whole, whichever goroutines they are
+// waiting on.
+var waits sync.Mutex
+
+// waitEdge is a call being waited for, to get the value of c.
+type waitEdge struct {
+	call *ctorCall
+	c    *ctor
+}
+
+var (
+	// terror is the type "error"
+	terror = reflect.TypeOf((*error)(nil)).Elem()
+	// tcleanup is the type of cleanup funcs returned by constructors.
+	tcleanup = reflect.TypeOf(func() {})
+	// tcontext is the type "context.Context"
+	tcontext = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
 
-// newCtor creates a new ctor for the return type of constructor.
-func newCtor(constructor reflect.Type, v reflect.Value) *ctor {
-	if constructor.Kind() != reflect.Func || constructor.IsVariadic() {
+// newCtors creates a new ctor for each output of constructor, except for a
+// trailing error, and a trailing cleanup func() preceding it (if the
+// constructor has some other output). A leading context.Context parameter is
+// not an injection type, it is passed the context of the current injection. A
+// variadic parameter is optional: it is passed the value of its slice type, if
+// that is an injection type, or nothing otherwise. A panic in the constructor
+// is returned as a ConstructorPanic. It returns nil if constructor is not a
+// constructor.
+func newCtors(constructor reflect.Type, v reflect.Value) []*ctor {
+	if constructor.Kind() != reflect.Func {
 		return nil
 	}
 	numOut := constructor.NumOut()
-	if numOut == 0 || numOut > 2 || (numOut == 2 && constructor.Out(1) != terror) {
+	if numOut == 0 {
 		return nil
 	}
-	outType := constructor.Out(0)
+	returnsErr := numOut > 1 && constructor.Out(numOut-1) == terror
+	if returnsErr {
+		numOut--
+	}
+	returnsCleanup := numOut > 1 && constructor.Out(numOut-1) == tcleanup
+	if returnsCleanup {
+		numOut--
+	}
+	outTypes := make([]reflect.Type, numOut)
+	for i := range outTypes {
+		outTypes[i] = constructor.Out(i)
+	}
 	numIn := constructor.NumIn()
-	inTypes := make([]reflect.Type, numIn)
+	takesContext := numIn > 0 && constructor.In(0) == tcontext
+	var firstIn int
+	if takesContext {
+		firstIn = 1
+	}
+	inTypes := make([]reflect.Type, numIn-firstIn)
 	for i := range inTypes {
-		inTypes[i] = constructor.In(i)
+		inTypes[i] = constructor.In(firstIn + i)
+	}
+	// call calls the constructor, recovering from any panic.
+	call := func(in []reflect.Value) (out []reflect.Value, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = ConstructorPanic{OutType: outTypes[0], FuncType: constructor, Recovered: r, Stack: debug.Stack()}
+			}
+		}()
+		if constructor.IsVariadic() {
+			return v.CallSlice(in), nil
+		}
+		return v.Call(in), nil
 	}
-	construct := func(in []reflect.Value) (reflect.Value, error) {
+	construct := func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error) {
 		for i, arg := range in {
 			if arg.IsValid() {
 				continue
 			}
 			const format = "unable to create arg %d (%s) of %s constructor"
-			return reflect.Value{}, fmt.Errorf(format, i, inTypes[i], outType)
+			return nil, nil, fmt.Errorf(format, i, inTypes[i], constructor)
 		}
-		out := v.Call(in)
-		var err error
-		if len(out) == 2 && !out[1].IsNil() {
-			err = out[1].Interface().(error)
+		if takesContext {
+			in = append([]reflect.Value{reflect.ValueOf(&ctx).Elem()}, in...)
+		}
+		out, err := call(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		var cleanup func()
+		if returnsCleanup {
+			cleanup = out[numOut].Interface().(func())
+		}
+		if last := out[len(out)-1]; returnsErr && !last.IsNil() {
+			err = last.Interface().(error)
+		}
+		return out[:numOut], cleanup, err
+	}
+
+	f := &ctorFunc{
+		outTypes:       outTypes,
+		construct:      construct,
+		returnsCleanup: returnsCleanup,
+	}
+	if constructor.IsVariadic() {
+		f.optionalIn = make([]bool, len(inTypes))
+		f.optionalIn[len(inTypes)-1] = true
+	}
+	ctors := make([]*ctor, numOut)
+	for i, outType := range outTypes {
+		ctors[i] = &ctor{
+			funcType: constructor,
+			outType:  outType,
+			inTypes:  inTypes,
+			outIndex: i,
+			ctorFunc: f,
+		}
+	}
+	return ctors
+}
+
+// ctorCalls holds the calls a Psyringe has made to its constructors.
+type ctorCalls struct {
+	sync.RWMutex
+	calls map[*ctorFunc]*ctorCall
+	// addressed holds the pointer to a copy of the value of each injection
+	// type whose address has been injected, see WithPointerAdaptation.
+	addressed map[*injectionType]reflect.Value
+	// injected is set to 1 once the Psyringe owning the calls has injected,
+	// see Psyringe.Injected, and only ever accessed atomically.
+	injected int32
+}
+
+func newCtorCalls() *ctorCalls {
+	return &ctorCalls{calls: map[*ctorFunc]*ctorCall{}, addressed: map[*injectionType]reflect.Value{}}
+}
+
+// get returns the call for f, creating it if there is not one yet.
+func (cs *ctorCalls) get(f *ctorFunc) *ctorCall {
+	if call := cs.lookup(f); call != nil {
+		return call
+	}
+	cs.Lock()
+	defer cs.Unlock()
+	call, ok := cs.calls[f]
+	if !ok {
+		call = &ctorCall{done: make(chan struct{})}
+		cs.calls[f] = call
+	}
+	return call
+}
+
+// lookup returns the call for f, or nil if there is not one yet.
+func (cs *ctorCalls) lookup(f *ctorFunc) *ctorCall {
+	cs.RLock()
+	defer cs.RUnlock()
+	return cs.calls[f]
+}
+
+// discard removes the calls for each of fs, so that they are called afresh.
+func (cs *ctorCalls) discard(fs map[*ctorFunc]bool) {
+	cs.Lock()
+	defer cs.Unlock()
+	for f := range fs {
+		delete(cs.calls, f)
+	}
+	for it := range cs.addressed {
+		if it.Ctor != nil && fs[it.Ctor.ctorFunc] {
+			delete(cs.addressed, it)
 		}
-		return out[0], err
 	}
+}
 
-	return &ctor{
-		funcType:     constructor,
-		outType:      outType,
-		inTypes:      inTypes,
-		construct:    construct,
-		errChan:      make(chan error),
-		onceManifest: &sync.Once{},
-		onceResult:   &sync.Once{},
+// merge adds the calls in other which cs does not have.
+func (cs *ctorCalls) merge(other *ctorCalls) {
+	cs.Lock()
+	defer cs.Unlock()
+	for f, call := range other.calls {
+		if _, ok := cs.calls[f]; !ok {
+			cs.calls[f] = call
+		}
 	}
 }
 
-func (c ctor) clone() *ctor {
-	if c.value == nil {
-		c.onceManifest = &sync.Once{}
-		c.onceResult = &sync.Once{}
-		c.errChan = make(chan error)
+// finished returns true if the call for f has finished, successfully or not,
+// so getting its value will not call or wait for the constructor.
+func (cs *ctorCalls) finished(f *ctorFunc) bool {
+	call := cs.lookup(f)
+	return call != nil && call.finished()
+}
+
+// cloneRealised returns new ctorCalls sharing only the calls in cs which have
+// successfully generated their values, so that all other constructors are
+// called afresh.
+func (cs *ctorCalls) cloneRealised() *ctorCalls {
+	clone := newCtorCalls()
+	clone.resetRealised(cs)
+	return clone
+}
+
+// realised returns true if c has finished and successfully generated a value.
+func (c *ctorCall) realised() bool {
+	select {
+	case <-c.done:
+		return c.err == nil
+	default:
+		return false
 	}
-	return &c
 }
 
-func (c *ctor) testParametersAreRegisteredIn(s *Psyringe) error {
+// optional reports whether parameter i may be left zero, see optionalIn.
+func (c *ctor) optional(i int) bool {
+	return c.optionalIn != nil && c.optionalIn[i]
+}
+
+// testParametersAreRegisteredIn returns an error for each parameter of c which
+// s has no value or constructor for.
+func (c *ctor) testParametersAreRegisteredIn(s *Psyringe) []error {
+	var errs []error
 	for paramIndex, paramType := range c.inTypes {
-		if err := s.testValueOrConstructorIsRegistered(paramType); err != nil {
-			return errors.Wrapf(err, "unable to satisfy param %d", paramIndex)
+		if c.optional(paramIndex) {
+			continue
+		}
+		err := s.testValueOrConstructorIsRegistered(paramType)
+		if missing, ok := missingParam(err, c, paramIndex); ok {
+			errs = append(errs, missing)
+		} else if err != nil {
+			errs = append(errs, errors.Wrapf(err, "unable to satisfy param %d", paramIndex))
 		}
 	}
-	return nil
+	return errs
 }
 
-func (c *ctor) getValue(p *Psyringe) (reflect.Value, error) {
-	c.onceManifest.Do(func() { go c.manifest(p) })
-	err := <-c.errChan
-	if err == nil {
-		return *c.value, nil
+// getValue returns the value of this constructor, calling it if it has not yet
+// been called. path is the chain of constructors currently being called which
+// led to this call; if c is already on that path then waiting for it would
+// never finish, so a DependencyCycle is returned. The same is true if the
+// call of c is already waiting, through calls on other goroutines, for the
+// constructor ctx was passed through, see waitFor. If ctx is done before the
+// value is available, getValue stops waiting and returns ctx.Err(), wrapped,
+// leaving p read-locked until the constructor has returned. p and its
+// ancestors must be read-locked.
+func (c *ctor) getValue(ctx context.Context, p *Psyringe, path []*ctor) (reflect.Value, error) {
+	for _, pc := range path {
+		if pc.ctorFunc == c.ctorFunc {
+			dc := newDependencyCycle(appendPath(path, c))
+			dc.Scope = p.errScope()
+			return reflect.Value{}, dc
+		}
+	}
+	var call *ctorCall
+	if c.transient {
+		// Transient calls are not shared, so there is nothing to wait for.
+		call = &ctorCall{done: make(chan struct{})}
+	} else {
+		call = p.calls.get(c.ctorFunc)
+		if len(p.ttls) != 0 {
+			call = p.calls.renewExpired(c.ctorFunc, call, p.ttls[c.outType])
+		}
+	}
+	if !call.finished() {
+		if waiter := constructingCall(ctx); waiter != nil {
+			if cycle := waiter.waitFor(call, c); cycle != nil {
+				dc := newDependencyCycle(append(appendPath(path, c), cycle...))
+				dc.Scope = p.errScope()
+				return reflect.Value{}, dc
+			}
+			defer waiter.stopWaiting(call)
+		}
+	}
+	if c.transient {
+		c.manifest(ctx, p, call, appendPath(path, c))
+	} else if call.start() {
+		// The caller holds p's read locks, but may stop waiting for the
+		// value before the constructor returns, so the manifest keeps its
+		// own until it has finished reading p.
+		unlock := p.rlockChain()
+		spawn(ctx, func() {
+			defer unlock()
+			c.manifest(ctx, p, call, appendPath(path, c))
+		})
+	}
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		select {
+		case <-call.done:
+			// Finished at the same time, prefer the result.
+		default:
+			return reflect.Value{}, errors.Wrapf(ctx.Err(), "constructing %s", c.outType)
+		}
+	}
+	if call.err == nil {
+		return call.values[c.outIndex], nil
+	}
+	if missing, ok := call.err.(NoConstructorOrValue); ok && missing.Constructor == c.funcType {
+		// The constructor was never invoked, and the error already names it.
+		return reflect.Value{}, missing
+	}
+	if panicked, ok := call.err.(ConstructorPanic); ok && panicked.FuncType == c.funcType {
+		// The error already names the constructor.
+		return reflect.Value{}, panicked
+	}
+	if timeout, ok := call.err.(ConstructorTimeout); ok && timeout.FuncType == c.funcType {
+		return reflect.Value{}, timeout
 	}
 	const format = "invoking %s constructor (%s) failed"
-	return reflect.Value{}, errors.Wrapf(err, format, c.outType, c.funcType)
+	return reflect.Value{}, errors.Wrapf(call.err, format, c.outType, c.funcType)
 }
 
-// manifest is called exactly once for each constructor to generate its value.
-func (c *ctor) manifest(s *Psyringe) {
-	defer c.finishWithError(nil)
-	wg := sync.WaitGroup{}
+// manifest is called exactly once for each call to generate its values. path
+// is the chain of constructors being called, ending with c. If ctx is done
+// before all arguments are available, the constructor is not called, and the
+// context's error is recorded as its outcome.
+func (c *ctor) manifest(ctx context.Context, s *Psyringe, call *ctorCall, path []*ctor) {
+	if s.Hooks.TraceConstructor != nil {
+		var end func(error)
+		ctx, end = s.Hooks.TraceConstructor(ctx, c.funcType, c.outType)
+		defer func() {
+			<-call.done
+			end(call.err)
+		}()
+	}
+	s.markInjected()
+	ctx = s.withConstructing(ctx, path, call)
 	numArgs := len(c.inTypes)
-	wg.Add(numArgs)
 	args := make([]reflect.Value, numArgs)
-	for i, t := range c.inTypes {
-		s, c, i, t := s, c, i, t
-		go func() {
-			defer wg.Done()
-			v, err := s.getValueForConstructor(c, i, t)
-			if err != nil {
-				c.finishWithError(err)
+	results := newArgResults(numArgs)
+	each(ctx, numArgs, func(i int) {
+		t := c.inTypes[i]
+		v, err := s.getValueForConstructor(ctx, c, i, t, path)
+		if _, missing := err.(NoConstructorOrValue); missing && c.optional(i) {
+			v, err = reflect.Zero(t), nil
+		}
+		if err := results.add(i, err); err != nil {
+			// Fail fast, there is no need to wait for the other args.
+			call.finish(s, nil, nil, err)
+		}
+		args[i] = v
+	})
+	select {
+	case <-call.done:
+		// Already failed getting an argument.
+		return
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		call.finish(s, nil, nil, errors.Wrapf(err, "constructing %s", c.outType))
+		return
+	}
+	if s.Hooks.ConstructorStarted != nil {
+		s.Hooks.ConstructorStarted(c.funcType, c.outType)
+	}
+	start := time.Now()
+	values, cleanup, err := c.constructWithin(ctx, s.constructorTimeout, args)
+	duration := time.Since(start)
+	var warning error
+	if err != nil && values != nil && isWarning(err) {
+		warning, err = err, nil
+	}
+	s.debug(DebugEvent{Kind: DebugConstructorCalled, Type: c.outType, Constructor: c.funcType, Duration: duration, Err: err})
+	if s.Hooks.ConstructorFinished != nil {
+		s.Hooks.ConstructorFinished(c.outType, duration, err)
+	}
+	if s.collector != nil {
+		s.collector.CtorInvoked(c.outType.String(), duration, err)
+	}
+	if warning != nil {
+		c.warn(ctx, s, warning)
+	}
+	if err == nil && s.Hooks.NilValueConstructed != nil {
+		if hookErr := c.checkNil(s.Hooks.NilValueConstructed, values); hookErr != nil {
+			if cleanup != nil {
+				cleanup()
 			}
-			args[i] = v
-		}()
+			values, cleanup, err = nil, nil, hookErr
+		}
+	}
+	if err == nil && !s.skipLifecycle {
+		if initErr := c.initValues(values); initErr != nil {
+			if cleanup != nil {
+				cleanup()
+			}
+			values, cleanup, err = nil, nil, initErr
+		}
+	}
+	if s.Hooks.ValueConstructed != nil {
+		var v reflect.Value
+		if err == nil {
+			v = values[c.outIndex]
+		}
+		if hookErr := s.Hooks.ValueConstructed(c.outType, v, duration, err); hookErr != nil {
+			if err == nil && cleanup != nil {
+				cleanup()
+			}
+			values, cleanup, err = nil, nil, hookErr
+		}
+	}
+	// Only this goroutine can finish call once the constructor is called.
+	call.duration = duration
+	call.finish(s, values, cleanup, err)
+}
+
+// checkNil calls hook for each of values, the outputs of c's constructor, which
+// is nil, returning the first error it returns.
+func (c *ctor) checkNil(hook NilValueConstructedFunc, values []reflect.Value) error {
+	for i, v := range values {
+		if !holdsNil(v) {
+			continue
+		}
+		if err := hook(c.outTypes[i], c.funcType); err != nil {
+			return err
+		}
 	}
-	wg.Wait()
-	v, err := c.construct(args)
-	if err != nil {
-		c.finishWithError(err)
+	return nil
+}
+
+// holdsNil reports whether v is a nil pointer, interface, map or slice, or an
+// interface holding one. Unlike isNil, it looks inside interfaces, since a
+// typed nil in an interface is not itself nil.
+func holdsNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || holdsNil(v.Elem())
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// argResults records which of a constructor's arguments have been got, so
+// that when more than one fails, the error reported is always that of the
+// lowest parameter index.
+type argResults struct {
+	sync.Mutex
+	done []bool
+	errs []error
+}
+
+func newArgResults(n int) *argResults {
+	return &argResults{done: make([]bool, n), errs: make([]error, n)}
+}
+
+// add records the outcome of getting argument i. It returns the error of the
+// lowest failed argument once every argument before it has been got, or nil.
+func (r *argResults) add(i int, err error) error {
+	r.Lock()
+	defer r.Unlock()
+	r.done[i], r.errs[i] = true, err
+	for j := 0; j < len(r.done) && r.done[j]; j++ {
+		if r.errs[j] != nil {
+			return r.errs[j]
+		}
 	}
-	c.value = &v
+	return nil
 }
 
-func (c *ctor) finishWithError(err error) {
+// finish records the outcome of calling this constructor, and unblocks all
+// callers of getValue. Only the first call to finish has any effect, so the
+// values or error are memoized for the lifetime of c.
+func (c *ctorCall) finish(owner *Psyringe, values []reflect.Value, cleanup func(), err error) {
 	c.onceResult.Do(func() {
-		go func() {
-			for {
-				c.errChan <- err
-			}
-		}()
+		c.owner, c.values, c.cleanup, c.err = owner, values, cleanup, err
+		c.at = time.Now()
+		close(c.done)
 	})
 }
+
+// start reports whether this is the first call to start, in which case the
+// caller must manifest the call. Unlike a sync.Once, it never blocks, so that
+// goroutines needing the value wait for done, where waitFor can see them.
+func (c *ctorCall) start() bool {
+	return atomic.CompareAndSwapInt32(&c.started, 0, 1)
+}
+
+// finished reports whether c is done, without waiting.
+func (c *ctorCall) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitFor records that the arguments of c are about to wait for call, to get
+// the value of forCtor, and returns nil. If call is already waiting for c,
+// directly or through other calls, neither would ever finish, so it records
+// nothing, and instead returns the constructors of the calls waited for from
+// call back to c, which with forCtor make up the cycle. Each call to waitFor
+// returning nil must be followed by a call to stopWaiting.
+func (c *ctorCall) waitFor(call *ctorCall, forCtor *ctor) (cycle []*ctor) {
+	waits.Lock()
+	defer waits.Unlock()
+	if cycle := call.waitPath(c, map[*ctorCall]bool{}); cycle != nil {
+		return cycle
+	}
+	c.waitingOn = append(c.waitingOn, waitEdge{call: call, c: forCtor})
+	return nil
+}
+
+// waitPath returns the constructors of the calls waited for from c to to, or
+// nil if c is not waiting for to. waits must be held.
+func (c *ctorCall) waitPath(to *ctorCall, visited map[*ctorCall]bool) []*ctor {
+	if visited[c] {
+		return nil
+	}
+	visited[c] = true
+	for _, e := range c.waitingOn {
+		if e.call == to {
+			return []*ctor{e.c}
+		}
+		if path := e.call.waitPath(to, visited); path != nil {
+			return append([]*ctor{e.c}, path...)
+		}
+	}
+	return nil
+}
+
+// stopWaiting removes a record made by waitFor of c waiting for call.
+func (c *ctorCall) stopWaiting(call *ctorCall) {
+	waits.Lock()
+	defer waits.Unlock()
+	for i, e := range c.waitingOn {
+		if e.call == call {
+			c.waitingOn = append(c.waitingOn[:i], c.waitingOn[i+1:]...)
+			return
+		}
+	}
+}
+
+// appendPath returns a new path with c appended, leaving path untouched so it
+// can be safely shared between concurrent branches of the graph.
+func appendPath(path []*ctor, c *ctor) []*ctor {
+	p := make([]*ctor, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, c)
+}
+
+// newDependencyCycle returns a DependencyCycle for a path of constructors.
+func newDependencyCycle(path []*ctor) DependencyCycle {
+	types := make([]reflect.Type, len(path))
+	for i, c := range path {
+		types[i] = c.outType
+	}
+	return DependencyCycle{Path: types}
+}
@@ -0,0 +1,161 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DebugEventKind is the kind of a DebugEvent.
+type DebugEventKind int
+
+const (
+	// DebugAdded is sent when a constructor or value is added, for each of
+	// its injection types.
+	DebugAdded DebugEventKind = iota + 1
+	// DebugAddSkipped is sent by AddIfMissing for each constructor or value
+	// not added because its injection type already was.
+	DebugAddSkipped
+	// DebugOverrode is sent by AddOverride for each injection type it
+	// replaces.
+	DebugOverrode
+	// DebugInjecting is sent when injecting into a target starts.
+	DebugInjecting
+	// DebugFieldInjecting is sent before getting the value of a field.
+	DebugFieldInjecting
+	// DebugFieldSkipped is sent for each field not injected, with the reason.
+	DebugFieldSkipped
+	// DebugArgumentNeeded is sent before getting each constructor argument.
+	DebugArgumentNeeded
+	// DebugConstructorCalled is sent after each constructor returns.
+	DebugConstructorCalled
+	// DebugRealising is sent for each target passed to Realise.
+	DebugRealising
+	// DebugWarmingUp is sent for each injection type passed to Warmup.
+	DebugWarmingUp
+	// DebugDecorated is sent by Decorate for each decorator added.
+	DebugDecorated
+)
+
+// DebugEvent describes something a Psyringe did, for debugging. Which fields
+// are set depends on Kind.
+type DebugEvent struct {
+	Kind DebugEventKind
+	// Type is the injection type concerned, or for DebugAddSkipped the type of
+	// the constructor or value not added.
+	Type reflect.Type
+	// Name is the name of a named injection type, see AddNamed.
+	Name string
+	// Constructor is the type of the constructor concerned, if any.
+	Constructor reflect.Type
+	// Param is the index of the constructor argument, for DebugArgumentNeeded.
+	Param int
+	// Target is the type of the target being injected into, for events about
+	// targets and fields.
+	Target reflect.Type
+	// Field is the field concerned, for events about fields.
+	Field reflect.StructField
+	// Reason is why a field was skipped, for DebugFieldSkipped.
+	Reason string
+	// Location is where Type was added, for DebugAdded and DebugOverrode, or
+	// where the decorator was added, for DebugDecorated.
+	Location string
+	// Scope is the path of the scope the overridden injection type was added
+	// to, for DebugOverrode when it is not the Psyringe overriding it.
+	Scope string
+	// Duration is how long the constructor took, for DebugConstructorCalled.
+	Duration time.Duration
+	// Err is the error returned by the constructor, for
+	// DebugConstructorCalled.
+	Err error
+}
+
+// String returns a message describing e, like "injecting field *pkg.T.F (int)".
+func (e DebugEvent) String() string {
+	switch e.Kind {
+	case DebugAdded:
+		what := "value"
+		if e.Constructor != nil {
+			what = "constructor " + e.Constructor.String()
+		}
+		return fmt.Sprintf("added %s for %s at %s", what, e.typeName(), e.Location)
+	case DebugAddSkipped:
+		return fmt.Sprintf("not adding %s: injection type already added", e.Type)
+	case DebugOverrode:
+		what := "value"
+		if e.Constructor != nil {
+			what = "constructor"
+		}
+		if e.Scope != "" {
+			return fmt.Sprintf("overrode %s for %s (was registered at %s in scope %s)", what, e.Type, e.Location, e.Scope)
+		}
+		return fmt.Sprintf("overrode %s for %s (was registered at %s)", what, e.Type, e.Location)
+	case DebugInjecting:
+		return fmt.Sprintf("injecting into a %s", e.Target)
+	case DebugFieldInjecting:
+		return fmt.Sprintf("injecting field %s.%s (%s)", e.Target, e.Field.Name, e.Field.Type)
+	case DebugFieldSkipped:
+		return fmt.Sprintf("not injecting field %s.%s (%s): %s", e.Target, e.Field.Name, e.Field.Type, e.Reason)
+	case DebugArgumentNeeded:
+		return fmt.Sprintf("getting a %s for arg %d of constructor %s", e.Type, e.Param, e.Constructor)
+	case DebugConstructorCalled:
+		if e.Err != nil {
+			return fmt.Sprintf("called constructor %s for %s in %s: %s", e.Constructor, e.Type, e.Duration, e.Err)
+		}
+		return fmt.Sprintf("called constructor %s for %s in %s", e.Constructor, e.Type, e.Duration)
+	case DebugRealising:
+		return fmt.Sprintf("realising a %s", e.Type)
+	case DebugWarmingUp:
+		return fmt.Sprintf("warming up %s", e.typeName())
+	case DebugDecorated:
+		return fmt.Sprintf("decorated %s with %s at %s", e.Type, e.Constructor, e.Location)
+	default:
+		return fmt.Sprintf("unknown debug event %d", e.Kind)
+	}
+}
+
+// addedEvent returns the DebugAdded event for it, added as injection type t
+// with name.
+func addedEvent(t reflect.Type, name string, it *injectionType) DebugEvent {
+	e := DebugEvent{Kind: DebugAdded, Type: t, Name: name, Location: it.DebugAddedLocation}
+	if it.Ctor != nil {
+		e.Constructor = it.Ctor.funcType
+	}
+	return e
+}
+
+// typeName returns Type, followed by Name if set.
+func (e DebugEvent) typeName() string {
+	if e.Name == "" {
+		return e.Type.String()
+	}
+	return fmt.Sprintf("%s named %q", e.Type, e.Name)
+}
+
+// SetDebugEventFunc sets a func to be called with each DebugEvent from this
+// Psyringe, and any clones or scopes subsequently created from it, so that
+// they can be sent to a structured logger. Events are still written to any
+// func set by WithDebugFunc as well. Pass nil to stop.
+func (p *Psyringe) SetDebugEventFunc(f func(DebugEvent)) {
+	defer p.lock()()
+	p.debugEventFunc = f
+}
+
+// WithDebugEventFunc is an Option doing the same as SetDebugEventFunc.
+func WithDebugEventFunc(f func(DebugEvent)) Option {
+	return func(p *Psyringe) { p.debugEventFunc = f }
+}
+
+// debug sends e to p's debug event func, if any, and writes its message to
+// p's debug func, if set by WithDebugFunc, or the package level one otherwise.
+func (p *Psyringe) debug(e DebugEvent) {
+	if p.debugEventFunc != nil {
+		p.debugEventFunc(e)
+	}
+	switch {
+	case p.debugFunc != nil:
+		p.debugFunc("%s", p.withName(e.String()))
+	case debugf != nil:
+		debugf("%s", p.withName(e.String()))
+	}
+}
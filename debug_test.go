@@ -0,0 +1,147 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPsyringe_SetDebugEventFunc(t *testing.T) {
+	var mu sync.Mutex
+	var events []DebugEvent
+	p := New()
+	p.SetDebugEventFunc(func(e DebugEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	p.Add(func(s string) (int, error) { return 0, fmt.Errorf("int error") }, "a")
+	kinds := func() []DebugEventKind {
+		mu.Lock()
+		defer mu.Unlock()
+		kinds := make([]DebugEventKind, len(events))
+		for i, e := range events {
+			kinds[i] = e.Kind
+		}
+		events = nil
+		return kinds
+	}
+	if got, want := kinds(), []DebugEventKind{DebugAdded, DebugAdded}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got added events %v; want %v", got, want)
+	}
+
+	var target struct {
+		Int  int
+		skip string
+	}
+	if err := p.Clone().Inject(&target); err == nil {
+		t.Fatal("got nil error")
+	}
+	want := []DebugEventKind{DebugInjecting, DebugFieldInjecting, DebugFieldSkipped, DebugArgumentNeeded, DebugConstructorCalled}
+	got := kinds()
+	if len(got) != len(want) {
+		t.Fatalf("got inject events %v; want %v in any order", got, want)
+	}
+	for _, k := range want {
+		found := false
+		for _, g := range got {
+			found = found || g == k
+		}
+		if !found {
+			t.Errorf("got inject events %v; want %v in any order", got, want)
+		}
+	}
+
+	child := p.Scope("child")
+	child.Realise(new(string))
+	if got, want := kinds(), []DebugEventKind{DebugRealising}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got realise events %v; want %v", got, want)
+	}
+}
+
+func TestDebugEvent_String(t *testing.T) {
+	field := reflect.StructField{Name: "F", Type: reflect.TypeOf(1)}
+	testCases := []struct {
+		event DebugEvent
+		want  string
+	}{
+		{
+			DebugEvent{Kind: DebugAdded, Type: reflect.TypeOf(1), Name: "n", Location: "a.go:1"},
+			`added value for int named "n" at a.go:1`,
+		},
+		{
+			DebugEvent{Kind: DebugFieldSkipped, Target: reflect.TypeOf(&struct{ F int }{}), Field: field, Reason: "unexported"},
+			"not injecting field *struct { F int }.F (int): unexported",
+		},
+		{
+			DebugEvent{Kind: DebugArgumentNeeded, Type: reflect.TypeOf(""), Param: 1, Constructor: reflect.TypeOf(func(int, string) bool { return false })},
+			"getting a string for arg 1 of constructor func(int, string) bool",
+		},
+		{
+			DebugEvent{Kind: DebugConstructorCalled, Type: reflect.TypeOf(true), Constructor: reflect.TypeOf(func() bool { return false }), Err: fmt.Errorf("oops")},
+			"called constructor func() bool for bool in 0s: oops",
+		},
+	}
+	for _, tc := range testCases {
+		if got := tc.event.String(); got != tc.want {
+			t.Errorf("got %q; want %q", got, tc.want)
+		}
+	}
+}
+
+// TestWithDebugFunc_formatted ensures every debug message is fully formatted,
+// naming actual types rather than containing format verbs.
+func TestWithDebugFunc_formatted(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	p := New(WithDebugFunc(func(format string, a ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, fmt.Sprintf(format, a...))
+	}))
+	p.Add(func(s string) int { return len(s) }, "hello")
+	p.AddNamed("name", 1.5)
+	if _, err := p.AddIfMissing("other"); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Int   int
+		Float float64 `psyringe:"name"`
+		Never bool    `inject:"-"`
+	}
+	p.MustInject(&target)
+	if err := p.Realise(new(string)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Warmup(""); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, want := range []string{
+		"added constructor func(string) int for int at ",
+		`added value for float64 named "name" at `,
+		"not adding string: injection type already added",
+		"injecting into a *struct { Int int; Float float64 \"psyringe:\\\"name\\\"\"; Never bool \"inject:\\\"-\\\"\" }",
+		"getting a string for arg 0 of constructor func(string) int",
+		"called constructor func(string) int for int in ",
+		"realising a string",
+		"warming up string",
+	} {
+		found := false
+		for _, m := range messages {
+			found = found || strings.HasPrefix(m, want)
+		}
+		if !found {
+			t.Errorf("no debug message starting %q in:\n%s", want, strings.Join(messages, "\n"))
+		}
+	}
+	for _, m := range messages {
+		if strings.Contains(m, "%") {
+			t.Errorf("unformatted debug message %q", m)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package psyringe
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// debugSnapshot is the state of a Psyringe served by DebugHandler, and the
+// schema of its JSON endpoint.
+type debugSnapshot struct {
+	Name  string      `json:"name,omitempty"`
+	Scope string      `json:"scope"`
+	Types []debugType `json:"types"`
+}
+
+type debugType struct {
+	Type         string   `json:"type"`
+	Name         string   `json:"name,omitempty"`
+	Kind         string   `json:"kind"`
+	Constructor  string   `json:"constructor,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	RegisteredAt string   `json:"registeredAt,omitempty"`
+	Scope        string   `json:"scope"`
+	Realized     bool     `json:"realized"`
+	LastDuration string   `json:"lastDuration,omitempty"`
+}
+
+// DebugHandler returns an http.Handler serving a page describing p, for
+// inspecting a running program, in the manner of net/http/pprof:
+//
+//	http.Handle("/debug/psyringe/", psyringe.DebugHandler(p))
+//
+// The page lists each injection type added to p or its ancestors, including
+// named ones, with its constructor's signature and the injection types of its
+// parameters, as in Graph.Edges, where it was added, as in Describe, whether
+// its value has been realized, and how long its constructor took, if it has
+// been called. Requests to a path ending in "/json", or with the query
+// "format=json", get the same information as JSON.
+//
+// Each response is a consistent snapshot of p, so it is safe to serve while
+// p is in use. Information which is not available, like the duration of a
+// constructor which has not been called, is left blank.
+func DebugHandler(p *Psyringe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := p.debugSnapshot()
+		if strings.HasSuffix(r.URL.Path, "/json") || r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(s); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugPage.Execute(w, s); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// debugSnapshot copies what DebugHandler serves from p and its ancestors,
+// holding their read locks.
+func (p *Psyringe) debugSnapshot() debugSnapshot {
+	defer p.rlockChain()()
+	snap := debugSnapshot{Name: p.name, Scope: p.scopePath(), Types: []debugType{}}
+	its := p.scopeChainInjectionTypes()
+	for _, v := range p.visibleInjectionTypes(true) {
+		dt := debugType{
+			Type:         v.t.String(),
+			Name:         v.name,
+			Kind:         "value",
+			RegisteredAt: v.it.DebugAddedLocation,
+			Scope:        v.scope.scopePath(),
+			Realized:     true,
+		}
+		if v.it.Ctor != nil {
+			dt.Kind = "ctor"
+			dt.Constructor = v.it.Ctor.funcType.String()
+			for _, dep := range p.dependencies(v.it.Ctor, its) {
+				dt.Dependencies = append(dt.Dependencies, dep.String())
+			}
+			dt.Realized = v.scope.realised(v.it.Ctor)
+			if d, ok := v.scope.lastDuration(v.it.Ctor); ok {
+				dt.LastDuration = d.String()
+			}
+		}
+		snap.Types = append(snap.Types, dt)
+	}
+	sort.Slice(snap.Types, func(i, j int) bool {
+		a, b := snap.Types[i], snap.Types[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+	return snap
+}
+
+// lastDuration returns how long c took when last called by p, or false if p
+// has not finished calling it.
+func (p *Psyringe) lastDuration(c *ctor) (time.Duration, bool) {
+	call := p.calls.lookup(c.ctorFunc)
+	if call == nil {
+		return 0, false
+	}
+	select {
+	case <-call.done:
+		return call.duration, call.duration != 0
+	default:
+		return 0, false
+	}
+}
+
+var debugPage = template.Must(template.New("psyringe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>psyringe{{with .Name}} {{.}}{{end}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.5em; text-align: left; vertical-align: top; }
+code { white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>psyringe{{with .Name}} {{printf "%q" .}}{{end}} (scope {{.Scope}})</h1>
+<p><a href="?format=json">JSON</a></p>
+<table>
+<tr><th>Name</th><th>Type</th><th>Kind</th><th>Constructor</th><th>Dependencies</th><th>Registered at</th><th>Scope</th><th>Realized</th><th>Last duration</th></tr>
+{{range .Types}}<tr>
+<td>{{with .Name}}{{printf "%q" .}}{{end}}</td>
+<td><code>{{.Type}}</code></td>
+<td>{{.Kind}}</td>
+<td><code>{{.Constructor}}</code></td>
+<td>{{range .Dependencies}}<code>{{.}}</code><br>{{end}}</td>
+<td>{{.RegisteredAt}}</td>
+<td>{{.Scope}}</td>
+<td>{{if .Realized}}yes{{else}}no{{end}}</td>
+<td>{{.LastDuration}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
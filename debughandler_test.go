@@ -0,0 +1,118 @@
+package psyringe
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler_json(t *testing.T) {
+	p := New(
+		1,
+		func(int) *bytes.Buffer {
+			time.Sleep(time.Millisecond)
+			return &bytes.Buffer{}
+		},
+		func(*bytes.Buffer) string { return "" },
+	)
+	p.SetName("app")
+	p.AddNamed("primary", func() *strings.Builder { return &strings.Builder{} })
+	var target struct{ Buffer *bytes.Buffer }
+	p.MustInject(&target)
+
+	for _, path := range []string{"/debug/psyringe/json", "/debug/psyringe/?format=json"} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			DebugHandler(p).ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("got Content-Type %q; want application/json", ct)
+			}
+			var got debugSnapshot
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%s:\n%s", err, rec.Body)
+			}
+			if got.Name != "app" || got.Scope != "app" {
+				t.Errorf("got name %q, scope %q; want app, app", got.Name, got.Scope)
+			}
+			var types []string
+			for _, dt := range got.Types {
+				types = append(types, dt.Name+" "+dt.Type)
+			}
+			want := []string{" *bytes.Buffer", " int", " string", "primary *strings.Builder"}
+			if strings.Join(types, ",") != strings.Join(want, ",") {
+				t.Fatalf("got types %q; want %q", types, want)
+			}
+
+			buffer := got.Types[0]
+			if buffer.Kind != "ctor" || !buffer.Realized || buffer.LastDuration == "" {
+				t.Errorf("got %+v; want realized ctor with a duration", buffer)
+			}
+			if buffer.Constructor != "func(int) *bytes.Buffer" {
+				t.Errorf("got constructor %q", buffer.Constructor)
+			}
+			if len(buffer.Dependencies) != 1 || buffer.Dependencies[0] != "int" {
+				t.Errorf("got dependencies %q; want [int]", buffer.Dependencies)
+			}
+			if !strings.Contains(buffer.RegisteredAt, "debughandler_test.go:") {
+				t.Errorf("got registeredAt %q; want this file", buffer.RegisteredAt)
+			}
+
+			value := got.Types[1]
+			if value.Kind != "value" || !value.Realized || value.Constructor != "" {
+				t.Errorf("got %+v; want realized value", value)
+			}
+
+			unrealised := got.Types[2]
+			if unrealised.Realized || unrealised.LastDuration != "" {
+				t.Errorf("got %+v; want unrealized ctor with no duration", unrealised)
+			}
+		})
+	}
+}
+
+func TestDebugHandler_html(t *testing.T) {
+	p := New(func() *bytes.Buffer { return &bytes.Buffer{} })
+	child := p.Scope("request")
+	child.MustAdd(func(*bytes.Buffer) string { return "<b>" })
+	rec := httptest.NewRecorder()
+	DebugHandler(child).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/psyringe/", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("got Content-Type %q; want text/html", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"(scope &lt;root&gt;/request)",
+		"<code>*bytes.Buffer</code>",
+		"<code>func(*bytes.Buffer) string</code>",
+		"<td>&lt;root&gt;/request</td>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+// TestDebugHandler_concurrent is run with -race to check that serving the page
+// does not race with injection.
+func TestDebugHandler_concurrent(t *testing.T) {
+	p := New(func() *bytes.Buffer { return &bytes.Buffer{} })
+	h := DebugHandler(p)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var target struct{ Buffer *bytes.Buffer }
+			p.MustInject(&target)
+		}()
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/json", nil))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,196 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// Decorate wraps the value of an injection type already added to this
+// Psyringe with each decorator in turn, without needing to know how it is
+// constructed. A decorator is a constructor whose first parameter has the same
+// type as its output, for example:
+//
+//	func(rt http.RoundTripper, log *Logger) http.RoundTripper
+//
+// It is passed the value the injection type would otherwise have, along with
+// values of any further parameters, and returns the value to inject in its
+// place. It may take a leading context.Context, and return an error, like any
+// other constructor, but it must have a single output, and must not return a
+// cleanup func.
+//
+// Decorators are called in the order they were added, around the value or
+// constructor originally added, all together the first time the injection
+// type is needed, so a singleton is still constructed once. Any value already
+// constructed, and anything depending on it, is constructed afresh next time
+// it is needed. Test checks the parameters of decorators as well.
+//
+// Close closes both the decorated value and the value it decorated, if they
+// differ, unless the original constructor returned a cleanup func, in which
+// case only that is called. A value added directly is still never closed,
+// even if a decorator returns it unchanged.
+//
+// Decorating an injection type which has not been added to this Psyringe
+// returns an error, as does decorating one added to a parent scope.
+func (p *Psyringe) Decorate(decorators ...interface{}) error {
+	return p.decorateErr(decorators...)
+}
+
+// decorateErr just exists to make callerinfo consistent in Psyringe.decorate.
+func (p *Psyringe) decorateErr(decorators ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	for i, decorator := range decorators {
+		if decorator == nil {
+			rollback()
+			return fmt.Errorf("cannot decorate with nil (argument %d)", i)
+		}
+		if err := p.decorate(decorator); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Psyringe) decorate(decorator interface{}) error {
+	v := reflect.ValueOf(decorator)
+	dec, err := newDecorator(v)
+	if err != nil {
+		return errors.Wrapf(err, "decorating with %s failed", v.Type())
+	}
+	t := dec.outType
+	scoped, ok := p.injectionTypeRegistrationScope(t)
+	if !ok {
+		return fmt.Errorf("decorating %s failed: %s", t, NoConstructorOrValue{Type: t, Scope: p.errScope()})
+	}
+	if scoped != p {
+		return fmt.Errorf("decorating %s failed: it was added to scope %s", t, scoped.scopePath())
+	}
+	base := p.injectionTypes[t]
+	if base.Ctor != nil && len(base.Ctor.outTypes) > 1 {
+		return fmt.Errorf("decorating %s failed: constructor %s has %d outputs", t, base.Ctor.funcType, len(base.Ctor.outTypes))
+	}
+	_, file, line, _ := runtime.Caller(3)
+	it := &injectionType{
+		Ctor:               newDecoratedCtor(base, dec),
+		DebugAddedLocation: base.DebugAddedLocation,
+		Module:             base.Module,
+	}
+	p.setInjectionType(t, it)
+	if !p.allowAddCycle {
+		if err := p.detectCycle(it.Ctor); err != nil {
+			p.setInjectionType(t, base)
+			return errors.Wrapf(err, "decorating %s with %s failed", t, v.Type())
+		}
+	}
+	p.debug(DebugEvent{Kind: DebugDecorated, Type: t, Constructor: v.Type(), Location: fmt.Sprintf("%s:%d", file, line)})
+	reset := map[*ctorFunc]bool{}
+	if base.Ctor != nil {
+		reset[base.Ctor.ctorFunc] = true
+	}
+	p.resetDependents(map[reflect.Type]bool{t: true}, reset)
+	return nil
+}
+
+// newDecorator returns the ctor for decorator v, or an error if v is not a
+// decorator, see Decorate.
+func newDecorator(v reflect.Value) (*ctor, error) {
+	ctors := newCtors(v.Type(), v)
+	switch {
+	case ctors == nil:
+		return nil, fmt.Errorf("not a constructor")
+	case len(ctors) > 1:
+		return nil, fmt.Errorf("decorator has %d outputs", len(ctors))
+	case ctors[0].returnsCleanup:
+		return nil, fmt.Errorf("decorators cannot return a cleanup func")
+	case len(ctors[0].inTypes) == 0 || ctors[0].inTypes[0] != ctors[0].outType:
+		return nil, fmt.Errorf("first parameter must be the decorated type %s", ctors[0].outType)
+	}
+	return ctors[0], nil
+}
+
+// newDecoratedCtor returns a ctor for the injection type of base decorated by
+// dec. Its parameters are those of base's constructor, if it has one,
+// followed by those of dec after the first. It returns the decorated value,
+// followed by any other values base's constructor returned, including those
+// of earlier decorators, so that Close can close them all.
+func newDecoratedCtor(base *injectionType, dec *ctor) *ctor {
+	var inTypes []reflect.Type
+	var optionalIn []bool
+	var transient bool
+	if base.Ctor != nil {
+		inTypes = append(inTypes, base.Ctor.inTypes...)
+		for i := range base.Ctor.inTypes {
+			optionalIn = append(optionalIn, base.Ctor.optional(i))
+		}
+		transient = base.Ctor.transient
+	}
+	numBaseIn := len(inTypes)
+	inTypes = append(inTypes, dec.inTypes[1:]...)
+	for i := range dec.inTypes[1:] {
+		optionalIn = append(optionalIn, dec.optional(i+1))
+	}
+	construct := func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error) {
+		value := base.Value
+		var baseValues []reflect.Value
+		var cleanup func()
+		if base.Ctor != nil {
+			out, c, err := base.Ctor.construct(ctx, in[:numBaseIn])
+			if err != nil {
+				return nil, nil, err
+			}
+			value, baseValues, cleanup = out[0], out, c
+		}
+		decIn := append([]reflect.Value{value}, in[numBaseIn:]...)
+		out, _, err := dec.construct(ctx, decIn)
+		if err != nil {
+			if cleanup != nil {
+				cleanup()
+			}
+			return nil, nil, errors.Wrapf(err, "decorator %s failed", dec.funcType)
+		}
+		values := []reflect.Value{out[0]}
+		for _, bv := range baseValues {
+			if !sameValue(out[0], bv) {
+				values = append(values, bv)
+			}
+		}
+		if base.Ctor == nil && sameValue(out[0], value) {
+			// Nothing was constructed, so there is nothing to close.
+			cleanup = func() {}
+		}
+		return values, cleanup, nil
+	}
+	t := dec.outType
+	return &ctor{
+		funcType: reflect.FuncOf(inTypes, []reflect.Type{t}, false),
+		outType:  t,
+		inTypes:  inTypes,
+		ctorFunc: &ctorFunc{
+			outTypes:       []reflect.Type{t},
+			construct:      construct,
+			returnsCleanup: base.Ctor != nil && base.Ctor.returnsCleanup,
+			transient:      transient,
+			optionalIn:     optionalIn,
+		},
+	}
+}
+
+// sameValue reports whether a and b hold equal comparable values, such as the
+// same pointer.
+func sameValue(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface {
+		a, b = a.Elem(), b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return false
+	}
+	return a.Comparable() && a.Equal(b)
+}
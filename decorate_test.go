@@ -0,0 +1,129 @@
+package psyringe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type (
+	decoratedConn struct {
+		layers []string
+		closed bool
+	}
+	decoratedName string
+)
+
+func (c *decoratedConn) Close() error { c.closed = true; return nil }
+
+func wrapConn(c *decoratedConn, layer string) *decoratedConn {
+	return &decoratedConn{layers: append(append([]string{}, c.layers...), layer)}
+}
+
+func TestPsyringe_Decorate(t *testing.T) {
+	var calls Counter
+	base := &decoratedConn{layers: []string{"base"}}
+	p := New(func() *decoratedConn { calls.Increment(); return base }, "outer")
+	err := p.Decorate(
+		func(c *decoratedConn) *decoratedConn { return wrapConn(c, "inner") },
+		func(c *decoratedConn, layer string) (*decoratedConn, error) { return wrapConn(c, layer), nil },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct{ A, B *decoratedConn }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(target.A.layers), "[base inner outer]"; got != want {
+		t.Errorf("got layers %s; want %s", got, want)
+	}
+	if target.A != target.B || calls.Value() != 1 {
+		t.Errorf("got %d constructor calls, same value %t; want 1, true", calls.Value(), target.A == target.B)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !base.closed || !target.A.closed {
+		t.Errorf("got base closed %t, decorated closed %t; want both closed", base.closed, target.A.closed)
+	}
+}
+
+func TestPsyringe_Decorate_value(t *testing.T) {
+	added := &decoratedConn{}
+	p := New(added)
+	if err := p.Decorate(func(c *decoratedConn) *decoratedConn { return c }); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get[*decoratedConn](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != added {
+		t.Errorf("got a different value; want the value added")
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if added.closed {
+		t.Errorf("value added directly was closed")
+	}
+}
+
+func TestPsyringe_Decorate_resetsDependents(t *testing.T) {
+	p := New(decoratedName("name"), func(n decoratedName) string { return string(n) })
+	if got := MustGet[string](p); got != "name" {
+		t.Fatalf("got %q; want %q", got, "name")
+	}
+	if err := p.Decorate(func(n decoratedName) decoratedName { return "decorated " + n }); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := MustGet[string](p), "decorated name"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestPsyringe_Decorate_errors(t *testing.T) {
+	p := New(decoratedName("name"))
+	if err := p.Decorate(func(n decoratedName, i int) decoratedName { return n }); err != nil {
+		t.Fatal(err)
+	}
+	const wantTest = "unable to satisfy constructor func(int) psyringe.decoratedName: " +
+		"unable to satisfy param 0: no constructor or value for int"
+	if err := p.Test(); err == nil || err.Error() != wantTest {
+		t.Errorf("got Test error %v; want %q", err, wantTest)
+	}
+
+	err := New(decoratedName("name"), func(decoratedName) int { return 0 }).
+		Decorate(func(n decoratedName, _ int) decoratedName { return n })
+	if !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("got error %v; want dependency cycle", err)
+	}
+
+	cases := []struct {
+		p         *Psyringe
+		decorator interface{}
+		want      string
+	}{
+		{New(), nil, "cannot decorate with nil (argument 0)"},
+		{New(), func(n decoratedName) decoratedName { return n },
+			"decorating psyringe.decoratedName failed: no constructor or value for psyringe.decoratedName"},
+		{New(decoratedName("")).Scope("child"), func(n decoratedName) decoratedName { return n },
+			"decorating psyringe.decoratedName failed: it was added to scope <root>"},
+		{New(func() (decoratedName, int) { return "", 0 }), func(n decoratedName) decoratedName { return n },
+			"decorating psyringe.decoratedName failed: constructor func() (psyringe.decoratedName, int) has 2 outputs"},
+		{New(), func() decoratedName { return "" },
+			"decorating with func() psyringe.decoratedName failed: first parameter must be the decorated type psyringe.decoratedName"},
+		{New(), func(n decoratedName) (decoratedName, func()) { return n, nil },
+			"decorating with func(psyringe.decoratedName) (psyringe.decoratedName, func()) failed: decorators cannot return a cleanup func"},
+	}
+	for _, c := range cases {
+		if err := c.p.Decorate(c.decorator); err == nil || err.Error() != c.want {
+			t.Errorf("got error %v; want %q", err, c.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// injectMode is how inject treats the fields of a target.
+type injectMode struct {
+	// keepSet leaves fields already holding non-zero values as they are, see
+	// InjectNonZero.
+	keepSet bool
+	// deep injects into fields of struct types with no injection type, see
+	// InjectDeep.
+	deep bool
+	// outer are the struct types being injected into by InjectDeep, or as
+	// embedded structs, outermost first.
+	outer []reflect.Type
+	// report collects a FieldReport for each field, see InjectWithReport.
+	report *injectReport
+	// field is the FieldReport for the field being injected, if reporting.
+	field *FieldReport
+}
+
+// InjectDeep is like Inject, but fields of struct or pointer to struct types
+// which are not injection types are themselves injected into, and so on all
+// the way down. Nil pointers are set to a new struct if anything is injected
+// into it, and left nil otherwise. Injection types always take precedence, and
+// fields tagged `inject:"-"` are left alone as usual.
+//
+// A struct type containing itself, directly or through other structs, cannot
+// be injected into this way, so InjectDeep returns an error naming the types
+// involved when it finds one, rather than allocating forever.
+func (p *Psyringe) InjectDeep(targets ...interface{}) error {
+	unlock := p.rlockChain()
+	ctx := p.limited(context.Background())
+	return p.injectTargets(ctx, targets, unlock, func(target interface{}) []error {
+		return p.inject(ctx, target, injectMode{deep: true})
+	})
+}
+
+// deepInjectable reports whether InjectDeep injects into fields of type t
+// with no injection type.
+func deepInjectable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// injectNested injects into the struct in field f, planned by fp, or into a
+// new one if f is a nil pointer, for InjectDeep or because f is embedded. A new
+// struct is kept if f is embedded, or anything was injected into it.
+func (p *Psyringe) injectNested(ctx context.Context, f reflect.Value, fp *fieldPlan, mode injectMode) error {
+	field := fp.field
+	t := field.Type
+	st := t
+	if t.Kind() == reflect.Ptr {
+		st = t.Elem()
+	}
+	what := "deep injecting field"
+	if field.Anonymous {
+		what = "injecting embedded field"
+	}
+	if len(mode.outer) == 0 {
+		mode.outer = []reflect.Type{fp.parent.Elem()}
+	}
+	for i, outer := range mode.outer {
+		if outer != st {
+			continue
+		}
+		names := make([]string, 0, len(mode.outer)-i+1)
+		for _, o := range mode.outer[i:] {
+			names = append(names, o.String())
+		}
+		names = append(names, st.String())
+		err := fmt.Errorf("%s contains itself: %s", st, strings.Join(names, " -> "))
+		return withPathStep(err, fp.step(), "%s %s (%s) failed", what, field.Name, t)
+	}
+	mode.outer = append(mode.outer[:len(mode.outer):len(mode.outer)], st)
+	mode.source(FieldInjectedInto, "")
+	mode.field = nil
+	target := f
+	switch {
+	case t.Kind() != reflect.Ptr:
+		target = f.Addr()
+	case f.IsNil():
+		target = reflect.New(st)
+	}
+	err := InjectErrors(p.injectTarget(ctx, target.Interface(), mode)).errOrNil()
+	if err != nil {
+		return withPathStep(err, fp.step(), "%s %s (%s) failed", what, field.Name, t)
+	}
+	if t.Kind() == reflect.Ptr && f.IsNil() && (field.Anonymous || !target.Elem().IsZero()) {
+		f.Set(target)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package psyringe
+
+import (
+	"testing"
+)
+
+type (
+	deepConfig struct {
+		Name string
+		Port int
+	}
+	deepEmpty  struct{ Unknown float64 }
+	deepTarget struct {
+		Config  deepConfig
+		Pointer *deepConfig
+		Empty   *deepEmpty
+		Never   *deepConfig `inject:"-"`
+	}
+	deepNode struct {
+		Name string
+		Next *deepNode
+	}
+	deepA struct{ B deepB }
+	deepB struct{ A *deepA }
+)
+
+func TestPsyringe_InjectDeep(t *testing.T) {
+	p := New("name", 80)
+	var target deepTarget
+	if err := p.InjectDeep(&target); err != nil {
+		t.Fatal(err)
+	}
+	want := deepConfig{Name: "name", Port: 80}
+	if target.Config != want {
+		t.Errorf("got Config %+v; want %+v", target.Config, want)
+	}
+	if target.Pointer == nil || *target.Pointer != want {
+		t.Errorf("got Pointer %+v; want &%+v", target.Pointer, want)
+	}
+	if target.Empty != nil {
+		t.Errorf("got Empty %+v; want nil, since nothing was injected into it", target.Empty)
+	}
+	if target.Never != nil {
+		t.Errorf("got Never %+v; want nil", target.Never)
+	}
+
+	// Plain Inject leaves them alone.
+	target = deepTarget{}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target != (deepTarget{}) {
+		t.Errorf("Inject injected into nested structs: %+v", target)
+	}
+}
+
+func TestPsyringe_InjectDeep_registeredWins(t *testing.T) {
+	registered := &deepConfig{Name: "registered"}
+	p := New("name", registered)
+	var target deepTarget
+	if err := p.InjectDeep(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Pointer != registered {
+		t.Errorf("got Pointer %+v; want the registered value", target.Pointer)
+	}
+	if target.Config.Name != "name" {
+		t.Errorf("got Config.Name %q; want %q", target.Config.Name, "name")
+	}
+}
+
+func TestPsyringe_InjectDeep_existingPointer(t *testing.T) {
+	p := New("name")
+	existing := &deepConfig{Port: 1}
+	target := deepTarget{Pointer: existing}
+	if err := p.InjectDeep(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Pointer != existing || existing.Name != "name" || existing.Port != 1 {
+		t.Errorf("got Pointer %+v; want existing struct injected into", target.Pointer)
+	}
+}
+
+func TestPsyringe_InjectDeep_recursiveTypes(t *testing.T) {
+	cases := []struct {
+		target interface{}
+		want   string
+	}{
+		{&deepNode{}, "inject into *psyringe.deepNode target failed: " +
+			"deep injecting field Next (*psyringe.deepNode) failed: " +
+			"psyringe.deepNode contains itself: psyringe.deepNode -> psyringe.deepNode"},
+		{&deepA{}, "inject into *psyringe.deepA target failed: " +
+			"deep injecting field B (psyringe.deepB) failed: " +
+			"deep injecting field A (*psyringe.deepA) failed: " +
+			"psyringe.deepA contains itself: psyringe.deepA -> psyringe.deepB -> psyringe.deepA"},
+	}
+	for _, c := range cases {
+		err := New("name").InjectDeep(c.target)
+		if err == nil || err.Error() != c.want {
+			t.Errorf("got error %v; want %q", err, c.want)
+		}
+	}
+}
+
+func TestPsyringe_InjectDeep_strict(t *testing.T) {
+	p := New(WithStrictInjection(), "name")
+	var target struct{ Config deepConfig }
+	err := p.InjectDeep(&target)
+	want := "inject into *struct { Config psyringe.deepConfig } target failed: " +
+		"deep injecting field Config (psyringe.deepConfig) failed: " +
+		"unable to inject field *psyringe.deepConfig.Port (int)"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
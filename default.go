@@ -0,0 +1,58 @@
+package psyringe
+
+import "sync"
+
+var (
+	// defaultMu guards defaultPsyringe, and the Psyringe it points to.
+	defaultMu       sync.RWMutex
+	defaultPsyringe = New()
+)
+
+// Default returns the package-level default Psyringe used by the package-level
+// Add, AddErr, Inject and MustInject functions. Those functions are safe to call
+// concurrently, but calling methods on the returned Psyringe directly is not
+// synchronised with them.
+func Default() *Psyringe {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPsyringe
+}
+
+// Reset replaces the default Psyringe with a new empty one. It is mainly useful
+// for isolating tests which use the default Psyringe.
+func Reset() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPsyringe = New()
+}
+
+// Add is like Psyringe.Add, using the default Psyringe. It is safe to call from
+// init functions in multiple files, and concurrently with Inject.
+func Add(constructorsAndValues ...interface{}) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if err := defaultPsyringe.addErr(constructorsAndValues...); err != nil {
+		panic(err)
+	}
+}
+
+// AddErr is like Psyringe.AddErr, using the default Psyringe.
+func AddErr(constructorsAndValues ...interface{}) error {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultPsyringe.addErr(constructorsAndValues...)
+}
+
+// Inject is like Psyringe.Inject, using the default Psyringe.
+func Inject(targets ...interface{}) error {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPsyringe.Inject(targets...)
+}
+
+// MustInject is like Psyringe.MustInject, using the default Psyringe.
+func MustInject(targets ...interface{}) {
+	if err := Inject(targets...); err != nil {
+		panic(err)
+	}
+}
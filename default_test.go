@@ -0,0 +1,86 @@
+package psyringe
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Add(func(s string) int { return len(s) })
+	Add("hello")
+	target := &struct {
+		Int    int
+		String string
+	}{}
+	MustInject(target)
+	if target.Int != 5 || target.String != "hello" {
+		t.Errorf("got %+v; want Int 5 and String %q", target, "hello")
+	}
+	if err := Default().Test(); err != nil {
+		t.Error(err)
+	}
+
+	err := AddErr(1)
+	if err == nil {
+		t.Fatal("got nil error; want already registered")
+	}
+	if !strings.Contains(err.Error(), "default_test.go:") {
+		t.Errorf("got error %q; want location in default_test.go", err)
+	}
+
+	Reset()
+	target2 := &struct{ Int int }{}
+	if err := Inject(target2); err != nil {
+		t.Fatal(err)
+	}
+	if target2.Int != 0 {
+		t.Errorf("got Int %d after Reset; want 0", target2.Int)
+	}
+}
+
+func TestDefault_concurrent(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	type (
+		A int
+		B int
+		C int
+		D int
+	)
+	things := []interface{}{A(1), B(2), C(3), D(4)}
+	var wg sync.WaitGroup
+	wg.Add(2 * len(things))
+	for _, thing := range things {
+		go func(thing interface{}) {
+			defer wg.Done()
+			Add(thing)
+		}(thing)
+		go func() {
+			defer wg.Done()
+			target := &struct {
+				A A
+				B B
+			}{}
+			if err := Inject(target); err != nil {
+				panic(fmt.Sprint(err))
+			}
+		}()
+	}
+	wg.Wait()
+	target := &struct {
+		A A
+		B B
+		C C
+		D D
+	}{}
+	MustInject(target)
+	if target.A != 1 || target.B != 2 || target.C != 3 || target.D != 4 {
+		t.Errorf("got %+v; want {1 2 3 4}", *target)
+	}
+}
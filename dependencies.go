@@ -0,0 +1,75 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DependenciesOf returns the parameter types of the constructor of an
+// injection type added to p or any of its ancestors, in parameter order, not
+// including any leading context.Context. The example is either a reflect.Type,
+// or an example value of the injection type. It returns no types for an
+// injection type added as a value, and an error for one not added at all.
+func (p *Psyringe) DependenciesOf(example interface{}) ([]reflect.Type, error) {
+	if example == nil {
+		return nil, fmt.Errorf("cannot get dependencies of nil")
+	}
+	t, ok := example.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(example)
+	}
+	defer p.rlockChain()()
+	it, _ := p.find(t)
+	if it == nil {
+		return nil, fmt.Errorf("cannot get dependencies of %s: not added", t)
+	}
+	if it.Ctor == nil {
+		return []reflect.Type{}, nil
+	}
+	return append([]reflect.Type{}, it.Ctor.inTypes...), nil
+}
+
+// DependentsOf returns every injection type, added to p or any of its
+// ancestors, whose constructor has a parameter of the injection type of
+// example, sorted by name. Those are what changing its constructor or value
+// affects directly. A parameter of interface type counts if interface binding
+// binds it to the injection type, as does one of type T or *T if pointer
+// adaptation adapts the injection type to it, see WithPointerAdaptation. The
+// example is either a reflect.Type, or an example value of the injection type.
+// Named injection types are not included.
+func (p *Psyringe) DependentsOf(example interface{}) []reflect.Type {
+	if example == nil {
+		return nil
+	}
+	t, ok := example.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(example)
+	}
+	defer p.rlockChain()()
+	its := p.scopeChainInjectionTypes()
+	dependents := []reflect.Type{}
+	for _, v := range p.visibleInjectionTypes(false) {
+		if v.it.Ctor == nil {
+			continue
+		}
+		for _, in := range v.it.Ctor.inTypes {
+			if in == t {
+				dependents = append(dependents, v.t)
+				break
+			}
+			if _, registered := p.injectionTypeRegistrationScope(in); registered {
+				continue
+			}
+			bound, _ := p.boundType(in, its...)
+			if bound == nil {
+				bound, _ = p.adaptedType(in)
+			}
+			if bound == t {
+				dependents = append(dependents, v.t)
+				break
+			}
+		}
+	}
+	sortTypes(dependents)
+	return dependents
+}
@@ -0,0 +1,69 @@
+package psyringe
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPsyringe_DependenciesOf(t *testing.T) {
+	root := New(
+		"a string",
+		func(s string) *bytes.Buffer { return bytes.NewBufferString(s) },
+	)
+	root.EnableInterfaceBinding()
+	p := root.Scope("child")
+	p.Add(
+		func(ctx context.Context, r io.Reader, s string) int { return len(s) },
+		func(n int, missing float64) bool { return n > 0 },
+	)
+
+	var (
+		tstring = reflect.TypeOf("")
+		tbuffer = reflect.TypeOf(&bytes.Buffer{})
+		treader = reflect.TypeOf((*io.Reader)(nil)).Elem()
+		tint    = reflect.TypeOf(0)
+		tbool   = reflect.TypeOf(true)
+		tfloat  = reflect.TypeOf(0.0)
+	)
+	deps := map[interface{}][]reflect.Type{
+		"":      {},
+		tbuffer: {tstring},
+		0:       {treader, tstring},
+		true:    {tint, tfloat},
+	}
+	for example, want := range deps {
+		got, err := p.DependenciesOf(example)
+		if err != nil {
+			t.Errorf("DependenciesOf(%v): %s", example, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DependenciesOf(%v) = %v; want %v", example, got, want)
+		}
+	}
+	if _, err := p.DependenciesOf(0.0); err == nil {
+		t.Errorf("DependenciesOf(float64) returned no error")
+	} else if want := "cannot get dependencies of float64: not added"; err.Error() != want {
+		t.Errorf("got error %q; want %q", err, want)
+	}
+
+	dependents := map[interface{}][]reflect.Type{
+		tstring: {tbuffer, tint},
+		tbuffer: {tint},
+		tint:    {tbool},
+		tfloat:  {tbool},
+		tbool:   {},
+	}
+	for example, want := range dependents {
+		if got := p.DependentsOf(example); !reflect.DeepEqual(got, want) {
+			t.Errorf("DependentsOf(%v) = %v; want %v", example, got, want)
+		}
+	}
+	// The root scope knows nothing of its child's constructors.
+	if got, want := root.DependentsOf(tstring), []reflect.Type{tbuffer}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root DependentsOf(string) = %v; want %v", got, want)
+	}
+}
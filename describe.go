@@ -0,0 +1,71 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Registration describes how an injection type was added to a Psyringe.
+type Registration struct {
+	// Type is the injection type.
+	Type reflect.Type
+	// Constructor is the type of the constructor func providing Type, or nil
+	// if Type was added as a value.
+	Constructor reflect.Type
+	// Scope is the name of the scope Type was added to, see Psyringe.Scope.
+	Scope string
+	// Location is the file:line of the call which added Type.
+	Location string
+	// Module is the name of the Module which provided Type, or empty if it
+	// was not added by AddModule.
+	Module string
+}
+
+// IsConstructor returns true if the injection type was added as a constructor,
+// rather than a value.
+func (r Registration) IsConstructor() bool {
+	return r.Constructor != nil
+}
+
+// String returns a description like "constructor func() int for int added at
+// file.go:12 (scope <root>)". If Module is set, "by module "name"" follows
+// "added".
+func (r Registration) String() string {
+	what := "value"
+	if r.IsConstructor() {
+		what = "constructor " + r.Constructor.String()
+	}
+	by := ""
+	if r.Module != "" {
+		by = fmt.Sprintf(" by module %q", r.Module)
+	}
+	return fmt.Sprintf("%s for %s added%s at %s (scope %s)", what, r.Type, by, r.Location, r.Scope)
+}
+
+// Describe returns the Registration of an injection type added to p or any of
+// its ancestors. forType is either a reflect.Type, or an example value of the
+// injection type. It returns false if the injection type has not been added.
+func (p *Psyringe) Describe(forType interface{}) (Registration, bool) {
+	if forType == nil {
+		return Registration{}, false
+	}
+	t, ok := forType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(forType)
+	}
+	defer p.rlockChain()()
+	it, depth := p.find(t)
+	if it == nil {
+		return Registration{}, false
+	}
+	r := Registration{
+		Type:     t,
+		Scope:    p.ancestor(depth).scope,
+		Location: it.DebugAddedLocation,
+		Module:   it.Module,
+	}
+	if it.Ctor != nil {
+		r.Constructor = it.Ctor.funcType
+	}
+	return r, true
+}
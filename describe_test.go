@@ -0,0 +1,61 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestPsyringe_Describe(t *testing.T) {
+	p := New()
+	child := p.Scope("child")
+	var want []Registration
+	// expect records that the injection type of example is added on the line
+	// following the call to expect.
+	expect := func(example interface{}, constructor interface{}, scope string) {
+		_, file, line, _ := runtime.Caller(1)
+		r := Registration{
+			Type:     reflect.TypeOf(example),
+			Scope:    scope,
+			Location: fmt.Sprintf("%s:%d", file, line+1),
+		}
+		if constructor != nil {
+			r.Constructor = reflect.TypeOf(constructor)
+		}
+		want = append(want, r)
+	}
+
+	expect(0, nil, "<root>")
+	p.Add(1)
+	expect(int8(0), nil, "<root>")
+	p.AddErr(int8(1))
+	expect("", (func() string)(nil), "child")
+	child.Add(func() string { return "" })
+	expect(int16(0), (func() int16)(nil), "child")
+	child.AddTransient(func() int16 { return 0 })
+	expect(int32(0), nil, "child")
+	child.AddIfMissing(int32(1))
+	expect(int64(0), nil, "child")
+	child.AddOverride(int64(1))
+	child.Add(1.0)
+	expect(1.0, nil, "child")
+	child.Replace(2.0)
+	child.Add(uint(1))
+	expect(uint(0), nil, "child")
+	(&TestPsyringe{child}).Replace(uint(2))
+
+	for _, r := range want {
+		got, ok := child.Describe(r.Type)
+		if !ok {
+			t.Errorf("%s not described", r.Type)
+			continue
+		}
+		if got != r {
+			t.Errorf("got %s; want %s", got, r)
+		}
+	}
+	if _, ok := child.Describe(uint8(0)); ok {
+		t.Errorf("described uint8, which was not added")
+	}
+}
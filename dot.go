@@ -0,0 +1,157 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// WriteDOT writes the dependency graph of p (see Graph) to w in Graphviz DOT
+// format, for example to be rendered with "dot -Tsvg". Injection types provided
+// by constructors are drawn as boxes, and those provided by values as
+// ellipses. Each edge points from a constructor's output type to one of its
+// parameter types. Parameter types missing from the graph are drawn dashed, and
+// edges forming part of a dependency cycle are drawn red.
+//
+// Type names are fully qualified with their package path, and the output is
+// the same each time for the same graph.
+func (p *Psyringe) WriteDOT(w io.Writer) error {
+	g := p.Graph()
+	inCycle := g.cycleComponents()
+	nodes := map[reflect.Type]bool{}
+	for _, t := range g.nodes {
+		nodes[t] = true
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("digraph psyringe {\n")
+	for _, t := range g.nodes {
+		shape := "ellipse"
+		if _, isCtor := g.edges[t]; isCtor {
+			shape = "box"
+		}
+		fmt.Fprintf(buf, "\t%s [shape=%s];\n", dotID(t), shape)
+	}
+	var missing []reflect.Type
+	seenMissing := map[reflect.Type]bool{}
+	for _, t := range g.nodes {
+		for _, dep := range g.edges[t] {
+			if !nodes[dep] && !seenMissing[dep] {
+				seenMissing[dep] = true
+				missing = append(missing, dep)
+			}
+		}
+	}
+	sortTypes(missing)
+	for _, t := range missing {
+		fmt.Fprintf(buf, "\t%s [shape=ellipse, style=dashed];\n", dotID(t))
+	}
+	for _, t := range g.nodes {
+		for _, dep := range g.edges[t] {
+			attrs := ""
+			if c, ok := inCycle[t]; ok && inCycle[dep] == c {
+				attrs = " [color=red]"
+			}
+			fmt.Fprintf(buf, "\t%s -> %s%s;\n", dotID(t), dotID(dep), attrs)
+		}
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// cycleComponents maps each injection type which is part of a dependency cycle
+// to an identifier of that cycle. Two types have the same identifier if they
+// depend on each other, directly or transitively. It uses Tarjan's strongly
+// connected components algorithm.
+func (g *Graph) cycleComponents() map[reflect.Type]int {
+	var (
+		index    = map[reflect.Type]int{}
+		lowlink  = map[reflect.Type]int{}
+		onStack  = map[reflect.Type]bool{}
+		stack    []reflect.Type
+		next     int
+		inCycle  = map[reflect.Type]int{}
+		strongly func(t reflect.Type)
+	)
+	strongly = func(t reflect.Type) {
+		index[t], lowlink[t] = next, next
+		next++
+		stack = append(stack, t)
+		onStack[t] = true
+		selfLoop := false
+		for _, dep := range g.edges[t] {
+			if dep == t {
+				selfLoop = true
+			}
+			if _, visited := index[dep]; !visited {
+				strongly(dep)
+				if lowlink[dep] < lowlink[t] {
+					lowlink[t] = lowlink[dep]
+				}
+			} else if onStack[dep] && index[dep] < lowlink[t] {
+				lowlink[t] = index[dep]
+			}
+		}
+		if lowlink[t] != index[t] {
+			return
+		}
+		var component []reflect.Type
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == t {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			for _, ct := range component {
+				inCycle[ct] = index[t]
+			}
+		}
+	}
+	for _, t := range g.nodes {
+		if _, visited := index[t]; !visited {
+			strongly(t)
+		}
+	}
+	return inCycle
+}
+
+// dotID returns the fully qualified name of t as a quoted DOT identifier.
+func dotID(t reflect.Type) string {
+	return strconv.Quote(qualifiedTypeName(t))
+}
+
+// qualifiedTypeName is like t.String(), but with named types qualified by
+// their full package path rather than just the package name.
+func qualifiedTypeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.String()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + qualifiedTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + qualifiedTypeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), qualifiedTypeName(t.Elem()))
+	case reflect.Map:
+		return "map[" + qualifiedTypeName(t.Key()) + "]" + qualifiedTypeName(t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + qualifiedTypeName(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + qualifiedTypeName(t.Elem())
+		}
+		return "chan " + qualifiedTypeName(t.Elem())
+	}
+	return t.String()
+}
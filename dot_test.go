@@ -0,0 +1,42 @@
+package psyringe
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+)
+
+func TestPsyringe_WriteDOT(t *testing.T) {
+	p := New(
+		"a string",
+		func(s string) (*url.URL, error) { return url.Parse(s) },
+		func(u *url.URL, missing []float64) int { return len(u.Path) },
+	)
+	p.allowAddCycle = true
+	p.Add(
+		func(int, bool) uint { return 0 },
+		func(uint) bool { return false },
+	)
+	buf := &bytes.Buffer{}
+	if err := p.WriteDOT(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph psyringe {
+	"*net/url.URL" [shape=box];
+	"bool" [shape=box];
+	"int" [shape=box];
+	"string" [shape=ellipse];
+	"uint" [shape=box];
+	"[]float64" [shape=ellipse, style=dashed];
+	"*net/url.URL" -> "string";
+	"bool" -> "uint" [color=red];
+	"int" -> "*net/url.URL";
+	"int" -> "[]float64";
+	"uint" -> "int";
+	"uint" -> "bool" [color=red];
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
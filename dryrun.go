@@ -0,0 +1,318 @@
+package psyringe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Plan is what injecting into a target would do, worked out without calling
+// any constructors. Use Psyringe.Plan to create one.
+type Plan struct {
+	p *Psyringe
+	// target is the pointer to struct type planned for.
+	target reflect.Type
+	// key is the planKey of p when the plan was made.
+	key    planKey
+	steps  []PlanStep
+	fields []PlannedField
+}
+
+// PlanStep is a constructor which injecting would call, see Plan.Steps.
+type PlanStep struct {
+	// Type is the injection type needed from the constructor. Constructors
+	// with multiple outputs provide their other injection types too.
+	Type reflect.Type
+	// Constructor is the type of the constructor func.
+	Constructor reflect.Type
+	// Scope is the name of the scope the constructor is called in, the one
+	// it was added to.
+	Scope string
+	// Transient is true if the constructor is called each time its value
+	// is needed, see AddTransient, rather than just once.
+	Transient bool
+}
+
+// String returns a description like "call func() int for int (scope <root>)".
+func (s PlanStep) String() string {
+	transient := ""
+	if s.Transient {
+		transient = "transient "
+	}
+	return fmt.Sprintf("call %s%s for %s (scope %s)", transient, s.Constructor, s.Type, s.Scope)
+}
+
+// PlannedField is what injecting would do to a single field, see
+// Plan.Fields.
+type PlannedField struct {
+	// Target is the pointer to struct type containing the field.
+	Target reflect.Type
+	// Field is the field.
+	Field reflect.StructField
+	// Source is where the field's value would come from. It is never
+	// FieldSkipped with the reason "already set", since a Plan is for Inject.
+	Source FieldSource
+	// InjectionType is the injection type providing the value, when Source
+	// is FieldFromValue or FieldFromConstructor.
+	InjectionType reflect.Type
+	// Reason is why the field would be skipped, when Source is FieldSkipped.
+	Reason string
+}
+
+// String returns a description like "*main.Server.DB (*sql.DB): constructor
+// *sql.DB".
+func (f PlannedField) String() string {
+	s := fmt.Sprintf("%s.%s (%s): %s", f.Target, f.Field.Name, f.Field.Type, f.Source)
+	if f.InjectionType != nil {
+		s += " " + f.InjectionType.String()
+	}
+	if f.Reason != "" {
+		s += " (" + f.Reason + ")"
+	}
+	return s
+}
+
+// Plan works out what Inject would do to target, which must be a pointer to a
+// struct, but may be nil, without calling any constructors: the constructors
+// it would call, in the order they would need to finish, and where the value
+// of each field would come from. Constructors whose values have already been
+// constructed are not called again, so are not included.
+//
+// If injecting could not succeed without a constructor failing, Plan returns
+// the same error Inject would: a NoConstructorOrValue for a missing
+// constructor parameter, a DependencyCycle, an UnfilledField in strict mode,
+// or an error from a field's tags.
+func (p *Psyringe) Plan(target interface{}) (*Plan, error) {
+	defer p.rlockChain()()
+	plan := &Plan{p: p, target: reflect.TypeOf(target), key: p.planKey()}
+	err := p.forEachTarget(context.Background(), "plan", []interface{}{target}, func(target interface{}) []error {
+		t := plan.target
+		if t == nil || t.Kind() != reflect.Ptr {
+			return invalidTarget(TargetNotPointer, t)
+		}
+		if t.Elem().Kind() != reflect.Struct {
+			return invalidTarget(TargetNotStruct, t)
+		}
+		planner := &planner{p: p, plan: plan, done: map[planned]error{}}
+		return planner.fields(t.Elem(), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Steps returns the constructors injecting would call, each after those it
+// depends on.
+func (plan *Plan) Steps() []PlanStep {
+	return append([]PlanStep(nil), plan.steps...)
+}
+
+// Fields returns what injecting would do to each field of the target, in
+// declaration order. The fields of embedded structs follow the field
+// containing them.
+func (plan *Plan) Fields() []PlannedField {
+	return append([]PlannedField(nil), plan.fields...)
+}
+
+// String returns the steps and fields of plan, one per line.
+func (plan *Plan) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "plan for %s\n", plan.target)
+	for i, s := range plan.steps {
+		fmt.Fprintf(&buf, "  %d. %s\n", i+1, s)
+	}
+	for _, f := range plan.fields {
+		fmt.Fprintf(&buf, "  %s\n", f)
+	}
+	return buf.String()
+}
+
+// Execute injects into target, which must have the type plan was made for, as
+// Inject does. The Psyringe must not have been added to, nor had anything
+// removed or replaced, since the plan was made, otherwise Execute returns an
+// error rather than injecting something other than what was planned.
+//
+// Making the plan has already worked out where each field's value comes from,
+// so Execute, or Inject, does not need to work that out again.
+func (plan *Plan) Execute(target interface{}) error {
+	p := plan.p
+	unlock := p.rlockChain()
+	if t := reflect.TypeOf(target); t != plan.target {
+		unlock()
+		return errors.Errorf("plan is for %s, not %s", plan.target, t)
+	}
+	if !plan.key.current(p) {
+		unlock()
+		return errors.Errorf("plan for %s is out of date: the psyringe has changed since", plan.target)
+	}
+	ctx := p.limited(context.Background())
+	return p.injectTargets(ctx, []interface{}{target}, unlock, func(target interface{}) []error {
+		return p.inject(ctx, target, injectMode{})
+	})
+}
+
+// planned identifies a constructor call, which is made once per scope unless
+// the constructor is transient.
+type planned struct {
+	scope *Psyringe
+	f     *ctorFunc
+}
+
+// planner makes a Plan.
+type planner struct {
+	p    *Psyringe
+	plan *Plan
+	// done holds the outcome of planning each constructor call planned.
+	done map[planned]error
+}
+
+// fields plans each field of struct type t, which is embedded in outer, as
+// Psyringe.injectField would inject it, returning an error for each field
+// which would fail.
+func (pl *planner) fields(t reflect.Type, outer []reflect.Type) []error {
+	p := pl.p
+	fieldErrs := make([]error, t.NumField())
+	for i, fp := range p.plan(t).fields {
+		fp := fp
+		pf := PlannedField{Target: fp.parent, Field: fp.field}
+		add := func(source FieldSource, reason string) {
+			pf.Source, pf.Reason = source, reason
+			pl.plan.fields = append(pl.plan.fields, pf)
+		}
+		field := fp.field
+		switch {
+		case fp.err != nil:
+			fieldErrs[i] = fp.err
+		case fp.opt == injectNever:
+			add(FieldSkipped, `tagged inject:"-"`)
+		case field.PkgPath != "":
+			add(FieldSkipped, "unexported")
+		case field.Anonymous && fp.source == nil && fp.name == "" && deepInjectable(field.Type) && !p.providesFromContext(field.Type):
+			add(FieldInjectedInto, "")
+			fieldErrs[i] = pl.embedded(&fp, outer)
+		case fp.source == nil && fp.name == "" && (p.providesFromContext(field.Type) || p.providesSelf(field.Type) ||
+			p.providesFunc(field.Type) || p.providesLazy(field.Type)):
+			add(FieldFromPsyringe, "")
+		case fp.source == nil:
+			add(FieldFromHook, "")
+			fieldErrs[i] = p.unfilledField(fp.parent.String(), field, fp.opt)
+		case fp.source.Ctor == nil:
+			pf.InjectionType = fp.source.Value.Type()
+			add(FieldFromValue, "")
+		default:
+			pf.InjectionType = fp.source.Ctor.outType
+			add(FieldFromConstructor, "")
+			err := pl.ctor(fp.source.Ctor, p.ancestor(fp.depth), nil)
+			if fp.name != "" {
+				fieldErrs[i] = withPathStep(err, fp.step(), "getting field %s (%s named %q) failed", field.Name, field.Type, fp.name)
+			} else {
+				fieldErrs[i] = withPathStep(err, fp.step(), "getting field %s (%s) failed", field.Name, field.Type)
+			}
+		}
+	}
+	return sortedFieldErrs(t, fieldErrs)
+}
+
+// embedded plans the fields of the embedded struct field planned by fp, as
+// Psyringe.injectNested would inject them.
+func (pl *planner) embedded(fp *fieldPlan, outer []reflect.Type) error {
+	st := fp.field.Type
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if len(outer) == 0 {
+		outer = []reflect.Type{fp.parent.Elem()}
+	}
+	for _, o := range outer {
+		if o == st {
+			err := fmt.Errorf("%s contains itself", st)
+			return withPathStep(err, fp.step(), "injecting embedded field %s (%s) failed", fp.field.Name, fp.field.Type)
+		}
+	}
+	outer = append(outer[:len(outer):len(outer)], st)
+	err := InjectErrors(pl.fields(st, outer)).errOrNil()
+	return withPathStep(err, fp.step(), "injecting embedded field %s (%s) failed", fp.field.Name, fp.field.Type)
+}
+
+// ctor plans calling c in scope s, after any constructors it depends on,
+// unless its value has already been constructed. path is the chain of
+// constructors which would be being called, as for ctor.getValue, whose
+// errors it returns.
+func (pl *planner) ctor(c *ctor, s *Psyringe, path []*ctor) error {
+	for _, pc := range path {
+		if pc.ctorFunc == c.ctorFunc {
+			dc := newDependencyCycle(appendPath(path, c))
+			dc.Scope = s.errScope()
+			return dc
+		}
+	}
+	key := planned{scope: s, f: c.ctorFunc}
+	if err, ok := pl.done[key]; ok {
+		return err
+	}
+	if call := s.calls.lookup(c.ctorFunc); !c.transient && call != nil && call.realised() {
+		return nil
+	}
+	err := pl.args(c, s, path)
+	pl.done[key] = err
+	if err == nil {
+		pl.plan.steps = append(pl.plan.steps, PlanStep{
+			Type:        c.outType,
+			Constructor: c.funcType,
+			Scope:       s.scope,
+			Transient:   c.transient,
+		})
+	}
+	return err
+}
+
+// args plans getting each argument of c in scope s, returning the error
+// ctor.getValue would for the first which would fail.
+func (pl *planner) args(c *ctor, s *Psyringe, path []*ctor) error {
+	for i, t := range c.inTypes {
+		err := pl.arg(s, c, i, t, appendPath(path, c))
+		if err == nil {
+			continue
+		}
+		if missing, ok := err.(NoConstructorOrValue); ok && missing.Constructor == c.funcType {
+			return missing
+		}
+		return errors.Wrapf(err, "invoking %s constructor (%s) failed", c.outType, c.funcType)
+	}
+	return nil
+}
+
+// arg plans getting argument i, of type t, for c in scope s, as
+// Psyringe.getValueForConstructor would get it.
+func (pl *planner) arg(s *Psyringe, c *ctor, i int, t reflect.Type, path []*ctor) error {
+	it, depth := s.find(t)
+	if it == nil {
+		if s.providesFromContext(t) || s.providesSelf(t) {
+			return nil
+		}
+		bound, err := s.boundType(t, s.scopeChainInjectionTypes()...)
+		if err != nil {
+			return err
+		}
+		if bound == nil {
+			if at, _ := s.adaptedType(t); at != nil {
+				return pl.arg(s, c, i, at, path)
+			}
+			if c.optional(i) {
+				return nil
+			}
+			missing, _ := missingParam(NoConstructorOrValue{Type: t, Scope: s.errScope()}, c, i)
+			return missing
+		}
+		return pl.arg(s, c, i, bound, path)
+	}
+	if it.Ctor == nil {
+		return nil
+	}
+	err := pl.ctor(it.Ctor, s.ancestor(depth), path)
+	return withPathStep(err, PathStep{Constructor: c.funcType, Param: i}, "getting argument %d failed", i)
+}
@@ -0,0 +1,96 @@
+package psyringe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	planA      string
+	planB      string
+	planC      string
+	planTarget struct {
+		A      planA
+		C      planC
+		Other  int
+		hidden planA
+	}
+)
+
+func TestPsyringe_Plan(t *testing.T) {
+	var calls Counter
+	p := New(
+		func(b planB, c planC) planA { calls.Increment(); return planA(b) + planA(c) },
+		func(c planC) planB { calls.Increment(); return planB(c) },
+		planC("c"),
+	)
+	plan, err := p.Plan((*planTarget)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 0 {
+		t.Errorf("planning called %d constructors; want none", calls.Value())
+	}
+	var steps []string
+	for _, s := range plan.Steps() {
+		steps = append(steps, s.Type.String())
+	}
+	if got, want := fmt.Sprint(steps), "[psyringe.planB psyringe.planA]"; got != want {
+		t.Errorf("got steps %s; want %s", got, want)
+	}
+	var fields []string
+	for _, f := range plan.Fields() {
+		fields = append(fields, f.Field.Name+" "+f.Source.String())
+	}
+	if got, want := strings.Join(fields, ", "), "A constructor, C value, Other hook, hidden skipped"; got != want {
+		t.Errorf("got fields %s; want %s", got, want)
+	}
+	const wantLine = "  1. call func(psyringe.planC) psyringe.planB for psyringe.planB (scope <root>)\n"
+	if !strings.Contains(plan.String(), wantLine) {
+		t.Errorf("got plan:\n%s\nwant it to contain %q", plan, wantLine)
+	}
+
+	target := &planTarget{}
+	if err := plan.Execute(target); err != nil {
+		t.Fatal(err)
+	}
+	if target.A != "cc" || calls.Value() != 2 {
+		t.Errorf("got A %q after %d calls; want %q after 2", target.A, calls.Value(), "cc")
+	}
+	if plan, err := p.Plan(target); err != nil || len(plan.Steps()) != 0 {
+		t.Errorf("got steps %v, error %v after injecting; want none", plan.Steps(), err)
+	}
+	if err := plan.Execute(&struct{}{}); err == nil {
+		t.Errorf("executing a plan for another type succeeded")
+	}
+	p.Add(0)
+	if err := plan.Execute(target); err == nil || !strings.Contains(err.Error(), "out of date") {
+		t.Errorf("got error %v; want plan out of date", err)
+	}
+}
+
+func TestPsyringe_Plan_errors(t *testing.T) {
+	newP := func(opts ...interface{}) *Psyringe {
+		return New(append(opts,
+			func(b planB) planA { return "" },
+			func(c planC) planB { return "" },
+		)...)
+	}
+	for _, p := range []*Psyringe{newP(), newP(WithStrictInjection())} {
+		_, got := p.Plan(&planTarget{})
+		want := p.Inject(&planTarget{})
+		if got == nil || want == nil || strings.ReplaceAll(want.Error(), "inject into", "plan") != got.Error() {
+			t.Errorf("got error %v; want %v", got, want)
+		}
+	}
+
+	p := New(WithAddTimeCycleCheck(false),
+		func(b planB) planA { return "" },
+		func(a planA) planB { return "" },
+	)
+	if _, err := p.Plan(&planTarget{}); !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("got error %v; want dependency cycle", err)
+	}
+}
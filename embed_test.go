@@ -0,0 +1,115 @@
+package psyringe
+
+import (
+	"strings"
+	"testing"
+)
+
+type (
+	EmbedBase struct {
+		Name   string
+		Logger *structLog
+	}
+	EmbedOther struct{ Port int }
+	embedValue struct {
+		EmbedBase
+		Port int
+	}
+	embedPointer struct {
+		*EmbedBase
+		*EmbedOther
+	}
+	embedCollision struct {
+		EmbedBase
+		Name string
+	}
+	embedUnexported struct {
+		embedBase
+	}
+	embedBase struct{ Name string }
+	// EmbedSelf embeds itself, so cannot be injected into.
+	EmbedSelf struct {
+		*EmbedSelf
+		Name string
+	}
+)
+
+func TestPsyringe_Inject_embedded(t *testing.T) {
+	l := &structLog{}
+	p := New("name", l, 80)
+
+	var value embedValue
+	if err := p.Inject(&value); err != nil {
+		t.Fatal(err)
+	}
+	if value.Name != "name" || value.Logger != l || value.Port != 80 {
+		t.Errorf("got %+v; want embedded fields injected", value)
+	}
+
+	var pointer embedPointer
+	if err := p.Inject(&pointer); err != nil {
+		t.Fatal(err)
+	}
+	if pointer.EmbedBase == nil || pointer.Name != "name" || pointer.Logger != l {
+		t.Errorf("got EmbedBase %+v; want it allocated and injected", pointer.EmbedBase)
+	}
+	if pointer.EmbedOther == nil || pointer.Port != 80 {
+		t.Errorf("got EmbedOther %+v; want it allocated and injected", pointer.EmbedOther)
+	}
+
+	var unexported embedUnexported
+	if err := p.Inject(&unexported); err != nil {
+		t.Fatal(err)
+	}
+	if unexported.Name != "" {
+		t.Errorf("got Name %q; want unexported embedded struct left alone", unexported.Name)
+	}
+}
+
+func TestPsyringe_Inject_embeddedNameCollision(t *testing.T) {
+	p := New("name")
+	var target embedCollision
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	// Both are injected: the outer one shadows the embedded one, but methods
+	// of EmbedBase still see their own.
+	if target.Name != "name" || target.EmbedBase.Name != "name" {
+		t.Errorf("got Name %q and EmbedBase.Name %q; want both injected", target.Name, target.EmbedBase.Name)
+	}
+}
+
+func TestPsyringe_Inject_embeddedRegisteredWins(t *testing.T) {
+	registered := &EmbedBase{Name: "registered"}
+	p := New("name", registered)
+	var target embedPointer
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.EmbedBase != registered || registered.Name != "registered" {
+		t.Errorf("got EmbedBase %+v; want the registered value, untouched", target.EmbedBase)
+	}
+}
+
+func TestPsyringe_Inject_embeddedSelf(t *testing.T) {
+	err := New("name").Inject(&EmbedSelf{})
+	want := "inject into *psyringe.EmbedSelf target failed: " +
+		"injecting embedded field EmbedSelf (*psyringe.EmbedSelf) failed: " +
+		"psyringe.EmbedSelf contains itself: psyringe.EmbedSelf -> psyringe.EmbedSelf"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestPsyringe_TestTargets_embedded(t *testing.T) {
+	p := New("name")
+	if err := p.TestTargets(&embedCollision{}); err == nil {
+		t.Fatal("got nil error; want embedded Logger reported")
+	} else if want := "embedded field EmbedBase (psyringe.EmbedBase)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q; want it to contain %q", err, want)
+	}
+	p.Add(&structLog{})
+	if err := p.TestTargets(&embedCollision{}); err != nil {
+		t.Error(err)
+	}
+}
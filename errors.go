@@ -0,0 +1,440 @@
+package psyringe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DependencyCycle is the error returned when constructing a value of some
+// injection type requires a value of that same type, directly or transitively.
+type DependencyCycle struct {
+	// Path is the chain of injection types forming the cycle. The first and
+	// last elements are the same type.
+	Path []reflect.Type
+	// Scope is the path of the child scope the cycle was found in, like
+	// "<root>/child", or empty if it was found in a root Psyringe.
+	Scope string
+}
+
+// Error returns the cycle formatted like
+// "dependency cycle: A: depends on B: depends on A", followed by
+// " (scope <root>/child)" if Scope is set.
+func (dc DependencyCycle) Error() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("dependency cycle")
+	for i, t := range dc.Path {
+		if i == 0 {
+			buf.WriteString(": " + t.String())
+			continue
+		}
+		buf.WriteString(": depends on " + t.String())
+	}
+	if dc.Scope != "" {
+		buf.WriteString(" (scope " + dc.Scope + ")")
+	}
+	return buf.String()
+}
+
+// Is reports whether target is a DependencyCycle with the same path, or with
+// an empty path, so that errors.Is(err, DependencyCycle{}) reports whether err
+// is caused by any dependency cycle.
+func (dc DependencyCycle) Is(target error) bool {
+	t, ok := target.(DependencyCycle)
+	if !ok {
+		return false
+	}
+	if len(t.Path) == 0 {
+		return true
+	}
+	if len(t.Path) != len(dc.Path) {
+		return false
+	}
+	for i := range t.Path {
+		if t.Path[i] != dc.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// key identifies the types forming the loop in dc, regardless of which type the
+// path starts from.
+func (dc DependencyCycle) key() string {
+	if len(dc.Path) == 0 {
+		return ""
+	}
+	// The loop starts after the first occurrence of the last type. If the last
+	// type is not repeated, it is another output of the first constructor, so
+	// the whole path is the loop.
+	loopPath := dc.Path
+	last := dc.Path[len(dc.Path)-1]
+	for i, t := range dc.Path[:len(dc.Path)-1] {
+		if t == last {
+			loopPath = dc.Path[i+1:]
+			break
+		}
+	}
+	loop := make([]string, len(loopPath))
+	for i, t := range loopPath {
+		loop[i] = t.String()
+	}
+	sort.Strings(loop)
+	return strings.Join(loop, ",")
+}
+
+// NoConstructorOrValue is the error returned when a value of some injection
+// type is needed, but no constructor or value of that type has been added.
+type NoConstructorOrValue struct {
+	// Type is the injection type that was needed.
+	Type reflect.Type
+	// Scope is the path of the child scope Type was needed in, like
+	// "<root>/child", or empty if it was needed in a root Psyringe.
+	Scope string
+	// Constructor is the type of the constructor whose parameter Param has
+	// injection type Type, or nil if Type was needed for something else, such
+	// as a field.
+	Constructor reflect.Type
+	// Param is the index of the constructor's parameter, not counting any
+	// leading context.Context.
+	Param int
+}
+
+// Error returns a message like "no constructor or value for T", followed by
+// " (scope <root>/child)" if Scope is set. If Constructor is set, that is
+// preceded by "unable to satisfy constructor func(T) U: unable to satisfy
+// param 0: ", so that the message is the same whether it comes from Test or
+// from injecting.
+func (e NoConstructorOrValue) Error() string {
+	if e.Constructor != nil {
+		const format = "unable to satisfy constructor %s: unable to satisfy param %d: %s"
+		return fmt.Sprintf(format, e.Constructor, e.Param, e.typeMessage())
+	}
+	return e.typeMessage()
+}
+
+// typeMessage returns the message of e, ignoring Constructor.
+func (e NoConstructorOrValue) typeMessage() string {
+	if e.Scope != "" {
+		return fmt.Sprintf("no constructor or value for %s (scope %s)", e.Type, e.Scope)
+	}
+	return fmt.Sprintf("no constructor or value for %s", e.Type)
+}
+
+// missingParam returns err as a NoConstructorOrValue for parameter i of c, if
+// it is a NoConstructorOrValue not already for some other parameter.
+func missingParam(err error, c *ctor, i int) (NoConstructorOrValue, bool) {
+	missing, ok := err.(NoConstructorOrValue)
+	if !ok || missing.Constructor != nil {
+		return NoConstructorOrValue{}, false
+	}
+	missing.Constructor, missing.Param = c.funcType, i
+	return missing, true
+}
+
+// Is reports whether target is a NoConstructorOrValue for the same type, or
+// with a nil Type, so that errors.Is(err, NoConstructorOrValue{}) reports
+// whether err is caused by any missing injection type.
+func (e NoConstructorOrValue) Is(target error) bool {
+	t, ok := target.(NoConstructorOrValue)
+	return ok && (t.Type == nil || t.Type == e.Type)
+}
+
+// ErrFrozen is the error returned, or panicked with, when trying to change a
+// Psyringe after Freeze.
+type ErrFrozen struct {
+	// Scope is the path of the frozen scope, like "<root>/child", or empty
+	// for an unnamed root Psyringe.
+	Scope string
+}
+
+// Error returns a message like "psyringe is frozen (scope <root>/child)".
+func (e ErrFrozen) Error() string {
+	if e.Scope != "" {
+		return "psyringe is frozen (scope " + e.Scope + ")"
+	}
+	return "psyringe is frozen"
+}
+
+// ConstructorPanic is the error returned when a constructor panics. It is
+// returned in place of the error the constructor would have returned, so that
+// a panic deep in the graph fails the injection needing it, rather than
+// crashing the program.
+type ConstructorPanic struct {
+	// OutType is the injection type of the constructor's first output.
+	OutType reflect.Type
+	// FuncType is the type of the constructor.
+	FuncType reflect.Type
+	// Recovered is the value the constructor panicked with.
+	Recovered interface{}
+	// Stack is the stack trace of the goroutine which panicked, as returned by
+	// runtime/debug.Stack.
+	Stack []byte
+}
+
+// Error returns a message like "T constructor (func() T) panicked: value".
+func (e ConstructorPanic) Error() string {
+	return fmt.Sprintf("%s constructor (%s) panicked: %v", e.OutType, e.FuncType, e.Recovered)
+}
+
+// Unwrap returns Recovered if it is an error, so that errors.Is and errors.As
+// consider it, or nil otherwise.
+func (e ConstructorPanic) Unwrap() error {
+	err, _ := e.Recovered.(error)
+	return err
+}
+
+// ConstructorTimeout is the error returned when a constructor takes longer
+// than the timeout set by SetConstructorTimeout.
+type ConstructorTimeout struct {
+	// OutType is the injection type being constructed.
+	OutType reflect.Type
+	// FuncType is the type of the constructor.
+	FuncType reflect.Type
+	// Timeout is the timeout which passed.
+	Timeout time.Duration
+}
+
+// Error returns a message like "T constructor (func() T) timed out after 1s".
+func (e ConstructorTimeout) Error() string {
+	return fmt.Sprintf("%s constructor (%s) timed out after %s", e.OutType, e.FuncType, e.Timeout)
+}
+
+// Unwrap returns context.DeadlineExceeded, so that errors.Is reports a
+// timeout the same way whether it was set by SetConstructorTimeout or by the
+// context passed to InjectCtx.
+func (e ConstructorTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// NilConstructed is the error returned by the NilValueConstructed hook set by
+// WithRejectNilConstructions.
+type NilConstructed struct {
+	// OutType is the injection type of the nil value.
+	OutType reflect.Type
+	// FuncType is the type of the constructor.
+	FuncType reflect.Type
+}
+
+// Error returns a message like "constructor returned nil *sql.DB".
+func (e NilConstructed) Error() string {
+	return fmt.Sprintf("constructor returned nil %s", e.OutType)
+}
+
+// InvalidTargetKind is why a target is not valid, see InvalidTargetError.
+type InvalidTargetKind int
+
+const (
+	// TargetNotPointer is a target which is not a pointer.
+	TargetNotPointer InvalidTargetKind = iota + 1
+	// TargetNotStruct is a pointer target which does not point to a struct.
+	TargetNotStruct
+	// TargetNil is a nil pointer target.
+	TargetNil
+)
+
+// InvalidTargetError is the error returned when a target passed to Inject, or
+// another method taking targets, is not one it can use. It is wrapped in a
+// message naming the target's type, like "inject into int target failed:
+// target must be a pointer", so use errors.As to find it.
+type InvalidTargetError struct {
+	// Kind is why the target is not valid.
+	Kind InvalidTargetKind
+	// Type is the target's type, or nil if the target was nil.
+	Type reflect.Type
+}
+
+// Error returns "target must be a pointer", "target must be a pointer to
+// struct" or "target is nil", depending on Kind.
+func (e InvalidTargetError) Error() string {
+	switch e.Kind {
+	case TargetNotStruct:
+		return "target must be a pointer to struct"
+	case TargetNil:
+		return "target is nil"
+	default:
+		return "target must be a pointer"
+	}
+}
+
+// invalidTarget returns the InvalidTargetError of kind for a target of type t
+// as a single error, the form returned for each target.
+func invalidTarget(kind InvalidTargetKind, t reflect.Type) []error {
+	return []error{InvalidTargetError{Kind: kind, Type: t}}
+}
+
+// InjectErrors is returned by Inject when more than one field could not be
+// injected. Errors are ordered by target type, then by field name.
+type InjectErrors []error
+
+// Errors returns each individual error.
+func (ie InjectErrors) Errors() []error {
+	return []error(ie)
+}
+
+// Unwrap returns each individual error, so that errors.Is and errors.As
+// consider all of them.
+func (ie InjectErrors) Unwrap() []error {
+	return ie.Errors()
+}
+
+// Error returns each error message on its own line.
+func (ie InjectErrors) Error() string {
+	messages := make([]string, len(ie))
+	for i, err := range ie {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// errOrNil returns nil if ie is empty, its only error if it has just one, and
+// ie itself otherwise.
+func (ie InjectErrors) errOrNil() error {
+	switch len(ie) {
+	case 0:
+		return nil
+	case 1:
+		return ie[0]
+	default:
+		return ie
+	}
+}
+
+// CloseErrors is returned by Close when more than one value could not be
+// closed. Errors are in the order the values were closed.
+type CloseErrors []error
+
+// Errors returns each individual error.
+func (ce CloseErrors) Errors() []error {
+	return []error(ce)
+}
+
+// Unwrap returns each individual error, so that errors.Is and errors.As
+// consider all of them.
+func (ce CloseErrors) Unwrap() []error {
+	return ce.Errors()
+}
+
+// Error returns each error message on its own line.
+func (ce CloseErrors) Error() string {
+	return InjectErrors(ce).Error()
+}
+
+// errOrNil returns nil if ce is empty, its only error if it has just one, and
+// ce itself otherwise.
+func (ce CloseErrors) errOrNil() error {
+	if len(ce) < 2 {
+		return InjectErrors(ce).errOrNil()
+	}
+	return ce
+}
+
+// AddErrors is returned by Add, AddErr and New when more than one of their
+// arguments could not be added, in the order of the arguments. Each error
+// names the position and type of its argument.
+type AddErrors []error
+
+// Errors returns each individual error.
+func (ae AddErrors) Errors() []error {
+	return []error(ae)
+}
+
+// Unwrap returns each individual error, so that errors.Is and errors.As
+// consider all of them.
+func (ae AddErrors) Unwrap() []error {
+	return ae.Errors()
+}
+
+// Error returns each error message on its own line.
+func (ae AddErrors) Error() string {
+	return InjectErrors(ae).Error()
+}
+
+// InjectionError is returned by Inject when getting the value for a field
+// fails. As well as the message, it records the path through the graph which
+// led to the failure, so that it can be inspected by code.
+type InjectionError struct {
+	// Path starts with the field whose value could not be got, followed by
+	// each constructor argument which was needed to get it, in order.
+	Path []PathStep
+	// Err is the error which occurred at the end of Path.
+	Err error
+	// message is this step's part of the error message, and wrapped the error
+	// it prefixes, whose message includes those of the rest of Path.
+	message string
+	wrapped error
+}
+
+// Error returns a message like "getting field F (T) failed: invoking T
+// constructor (func(U) T) failed: getting argument 0 failed: ...".
+func (e InjectionError) Error() string {
+	return e.message + ": " + e.wrapped.Error()
+}
+
+// Cause returns Err, for use with errors.Cause.
+func (e InjectionError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns Err, so that errors.Is and errors.As consider it.
+func (e InjectionError) Unwrap() error {
+	return e.Err
+}
+
+// PathStep is a single step in the Path of an InjectionError. It is either a
+// struct field, or a constructor argument.
+type PathStep struct {
+	// Parent is the type of target, a pointer to the struct owning Field, or
+	// nil if this step is a constructor argument.
+	Parent reflect.Type
+	// Field is the name of the field.
+	Field string
+	// Constructor is the type of the constructor, or nil if this step is a
+	// struct field.
+	Constructor reflect.Type
+	// Param is the index of the constructor's parameter, not counting any
+	// leading context.Context.
+	Param int
+}
+
+// IsField returns true if s is a struct field, rather than a constructor
+// argument.
+func (s PathStep) IsField() bool {
+	return s.Parent != nil
+}
+
+// String returns a description like "field *pkg.T.Field", or "argument 1 of
+// func(int, string) pkg.T".
+func (s PathStep) String() string {
+	if s.IsField() {
+		return fmt.Sprintf("field %s.%s", s.Parent, s.Field)
+	}
+	return fmt.Sprintf("argument %d of %s", s.Param, s.Constructor)
+}
+
+// withPathStep returns err wrapped in an InjectionError whose Path starts with
+// step, followed by the Path of any InjectionError err wraps. It returns nil if
+// err is nil.
+func withPathStep(err error, step PathStep, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	ie := InjectionError{
+		Path:    []PathStep{step},
+		Err:     err,
+		message: fmt.Sprintf(format, a...),
+		wrapped: err,
+	}
+	var inner InjectionError
+	if errors.As(err, &inner) {
+		ie.Path = append(ie.Path, inner.Path...)
+		ie.Err = inner.Err
+	}
+	return ie
+}
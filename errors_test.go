@@ -0,0 +1,140 @@
+package psyringe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrors_As(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+	)
+	p := New(func(int) string { return "" })
+	p.allowAddCycle = true
+	p.Add(
+		func(B) A { return nil },
+		func(A) B { return nil },
+	)
+	err := p.Test()
+
+	var dc DependencyCycle
+	if !errors.As(err, &dc) {
+		t.Fatalf("errors.As found no DependencyCycle in %q", err)
+	}
+	wantPath := []reflect.Type{reflect.TypeOf(A(nil)), reflect.TypeOf(B(nil)), reflect.TypeOf(A(nil))}
+	if !reflect.DeepEqual(dc.Path, wantPath) {
+		t.Errorf("got cycle path %v; want %v", dc.Path, wantPath)
+	}
+	if !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("errors.Is(err, DependencyCycle{}) = false; want true")
+	}
+	if !errors.Is(err, DependencyCycle{Path: wantPath}) {
+		t.Errorf("errors.Is(err, DependencyCycle{Path: %v}) = false; want true", wantPath)
+	}
+	if errors.Is(err, DependencyCycle{Path: wantPath[1:]}) {
+		t.Errorf("errors.Is(err, DependencyCycle{Path: %v}) = true; want false", wantPath[1:])
+	}
+
+	var ncv NoConstructorOrValue
+	if !errors.As(err, &ncv) {
+		t.Fatalf("errors.As found no NoConstructorOrValue in %q", err)
+	}
+	if ncv.Type != reflect.TypeOf(0) {
+		t.Errorf("got missing type %s; want int", ncv.Type)
+	}
+	if !errors.Is(err, NoConstructorOrValue{}) {
+		t.Errorf("errors.Is(err, NoConstructorOrValue{}) = false; want true")
+	}
+	if !errors.Is(err, NoConstructorOrValue{Type: reflect.TypeOf(0)}) {
+		t.Errorf("errors.Is(err, NoConstructorOrValue{int}) = false; want true")
+	}
+	if errors.Is(err, NoConstructorOrValue{Type: reflect.TypeOf("")}) {
+		t.Errorf("errors.Is(err, NoConstructorOrValue{string}) = true; want false")
+	}
+}
+
+func TestErrors_As_inject(t *testing.T) {
+	p := New(
+		func() (int, error) { return 0, NoConstructorOrValue{Type: reflect.TypeOf(0.0)} },
+		func() (string, error) { return "", errors.New("string error") },
+	)
+	err := p.Inject(&struct {
+		Int    int
+		String string
+	}{})
+	if _, ok := err.(InjectErrors); !ok {
+		t.Fatalf("got error %T; want InjectErrors", err)
+	}
+	var ncv NoConstructorOrValue
+	if !errors.As(err, &ncv) {
+		t.Fatalf("errors.As found no NoConstructorOrValue in %q", err)
+	}
+	if ncv.Type != reflect.TypeOf(0.0) {
+		t.Errorf("got missing type %s; want float64", ncv.Type)
+	}
+}
+
+func TestInjectionError_path(t *testing.T) {
+	errDeep := errors.New("deep error")
+	p := New(
+		func() (int, error) { return 0, errDeep },
+		func(b bool, i int) string { return "" },
+		func(string) byte { return 0 },
+		true,
+	)
+	var target struct{ Byte byte }
+	err := p.Inject(&target)
+
+	want := "inject into *struct { Byte uint8 } target failed: getting field Byte (uint8) failed: " +
+		"invoking uint8 constructor (func(string) uint8) failed: getting argument 0 failed: " +
+		"invoking string constructor (func(bool, int) string) failed: getting argument 1 failed: " +
+		"invoking int constructor (func() (int, error)) failed: deep error"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+	var ie InjectionError
+	if !errors.As(err, &ie) {
+		t.Fatalf("errors.As found no InjectionError in %q", err)
+	}
+	wantPath := []PathStep{
+		{Parent: reflect.TypeOf(&target), Field: "Byte"},
+		{Constructor: reflect.TypeOf(func(string) byte { return 0 }), Param: 0},
+		{Constructor: reflect.TypeOf(func(bool, int) string { return "" }), Param: 1},
+	}
+	if !reflect.DeepEqual(ie.Path, wantPath) {
+		t.Errorf("got path %v; want %v", ie.Path, wantPath)
+	}
+	if !errors.Is(err, errDeep) {
+		t.Errorf("errors.Is(err, errDeep) = false; want true")
+	}
+	if errors.Cause(err) != errDeep {
+		t.Errorf("got cause %v; want %v", errors.Cause(err), errDeep)
+	}
+	if got, want := ie.Path[2].String(), "argument 1 of func(bool, int) string"; got != want {
+		t.Errorf("got step %q; want %q", got, want)
+	}
+}
+
+func TestNoConstructorOrValue_sameMessage(t *testing.T) {
+	p := New(func(string, float64) int { return 0 }, "")
+	const want = "unable to satisfy constructor func(string, float64) int: unable to satisfy param 1: no constructor or value for float64"
+	if err := p.Test(); err == nil || err.Error() != want {
+		t.Errorf("got Test error %v; want %q", err, want)
+	}
+	var target struct{ Int int }
+	err := p.Inject(&target)
+	if err == nil || !strings.HasSuffix(err.Error(), "failed: "+want) {
+		t.Errorf("got Inject error %v; want it to end with %q", err, want)
+	}
+	var missing NoConstructorOrValue
+	if !errors.As(err, &missing) {
+		t.Fatalf("errors.As found no NoConstructorOrValue in %q", err)
+	}
+	if missing.Param != 1 || missing.Constructor != reflect.TypeOf(func(string, float64) int { return 0 }) {
+		t.Errorf("got param %d of %v; want param 1 of the constructor", missing.Param, missing.Constructor)
+	}
+}
@@ -0,0 +1,72 @@
+// Package expvarcollector provides a psyringe.Collector which publishes
+// counters for constructor calls and injections using expvar, so they can be
+// read from /debug/vars without any other metrics library.
+package expvarcollector
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+var _ psyringe.Collector = (*Collector)(nil)
+
+// Collector is a psyringe.Collector keeping counters in an expvar.Map. Each
+// counter is itself an expvar.Map keyed by injection type or target type:
+//
+//	ctor_calls, ctor_errors, ctor_nanoseconds
+//	injections, injection_errors, injection_nanoseconds
+//
+// The *_nanoseconds counters hold the total time taken, so that dividing by
+// the matching calls or injections gives the mean.
+type Collector struct {
+	m                                                 *expvar.Map
+	ctorCalls, ctorErrors, ctorNanoseconds            *expvar.Map
+	injections, injectionErrors, injectionNanoseconds *expvar.Map
+}
+
+// New returns a Collector publishing its counters under name. Like
+// expvar.NewMap, it panics if name is already published.
+func New(name string) *Collector {
+	c := &Collector{
+		m:                    new(expvar.Map).Init(),
+		ctorCalls:            new(expvar.Map).Init(),
+		ctorErrors:           new(expvar.Map).Init(),
+		ctorNanoseconds:      new(expvar.Map).Init(),
+		injections:           new(expvar.Map).Init(),
+		injectionErrors:      new(expvar.Map).Init(),
+		injectionNanoseconds: new(expvar.Map).Init(),
+	}
+	c.m.Set("ctor_calls", c.ctorCalls)
+	c.m.Set("ctor_errors", c.ctorErrors)
+	c.m.Set("ctor_nanoseconds", c.ctorNanoseconds)
+	c.m.Set("injections", c.injections)
+	c.m.Set("injection_errors", c.injectionErrors)
+	c.m.Set("injection_nanoseconds", c.injectionNanoseconds)
+	expvar.Publish(name, c.m)
+	return c
+}
+
+// Map returns the expvar.Map holding c's counters.
+func (c *Collector) Map() *expvar.Map {
+	return c.m
+}
+
+// CtorInvoked counts a constructor call.
+func (c *Collector) CtorInvoked(outType string, duration time.Duration, err error) {
+	c.ctorCalls.Add(outType, 1)
+	c.ctorNanoseconds.Add(outType, int64(duration))
+	if err != nil {
+		c.ctorErrors.Add(outType, 1)
+	}
+}
+
+// Injected counts an injection into a target.
+func (c *Collector) Injected(targetType string, duration time.Duration, err error) {
+	c.injections.Add(targetType, 1)
+	c.injectionNanoseconds.Add(targetType, int64(duration))
+	if err != nil {
+		c.injectionErrors.Add(targetType, 1)
+	}
+}
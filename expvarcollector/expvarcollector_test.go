@@ -0,0 +1,38 @@
+package expvarcollector
+
+import (
+	"expvar"
+	"fmt"
+	"testing"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("psyringe_test")
+	if expvar.Get("psyringe_test") != c.Map() {
+		t.Fatal("counters not published")
+	}
+	p := psyringe.New(psyringe.WithCollector(c),
+		func() int { return 1 },
+		func() (string, error) { return "", fmt.Errorf("string error") },
+	)
+	type Good struct{ Int int }
+	type Bad struct{ String string }
+	if err := p.Inject(&Good{}, &Bad{}); err == nil {
+		t.Fatal("got nil error")
+	}
+	p.Clone().MustInject(&Good{})
+
+	want := map[string]string{
+		"ctor_calls":       `{"int": 1, "string": 1}`,
+		"ctor_errors":      `{"string": 1}`,
+		"injections":       `{"*expvarcollector.Bad": 1, "*expvarcollector.Good": 2}`,
+		"injection_errors": `{"*expvarcollector.Bad": 1}`,
+	}
+	for name, want := range want {
+		if got := c.Map().Get(name).String(); got != want {
+			t.Errorf("got %s %s; want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package psyringe
+
+import "sync/atomic"
+
+// Freeze marks this Psyringe as finished being wired, so that nothing can be
+// added to it, removed from it or replaced in it again. Methods which would
+// change it return an ErrFrozen error instead, or panic with one if they do
+// not return errors; Scope panics too, since scopes are usually created while
+// wiring. Freezing cannot be undone, but Clone returns an unfrozen copy, for
+// example for tests to replace constructors in.
+//
+// In return, once this Psyringe and all of its parent scopes are frozen,
+// Inject and the like no longer need to lock them, since nothing they read
+// can change. Constructors are still called at most once, and values already
+// constructed are kept.
+func (p *Psyringe) Freeze() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	atomic.StoreInt32(&p.frozen, 1)
+}
+
+// Frozen reports whether Freeze has been called.
+func (p *Psyringe) Frozen() bool {
+	return atomic.LoadInt32(&p.frozen) == 1
+}
+
+// chainFrozen reports whether p and all of its ancestors are frozen.
+func (p *Psyringe) chainFrozen() bool {
+	for q := p; q != nil; q = q.parent {
+		if !q.Frozen() {
+			return false
+		}
+	}
+	return true
+}
+
+// frozenErr returns an ErrFrozen if p is frozen, or nil.
+func (p *Psyringe) frozenErr() error {
+	if !p.Frozen() {
+		return nil
+	}
+	return ErrFrozen{Scope: p.errScope()}
+}
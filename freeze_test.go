@@ -0,0 +1,76 @@
+package psyringe
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type frozenConn struct{}
+
+func TestPsyringe_Freeze(t *testing.T) {
+	p := New(func() *frozenConn { return &frozenConn{} })
+	child := p.Scope("child")
+	p.Freeze()
+	if !p.Frozen() || child.Frozen() {
+		t.Fatalf("got frozen %t, child frozen %t; want true, false", p.Frozen(), child.Frozen())
+	}
+
+	var wg sync.WaitGroup
+	targets := make([]struct{ Conn *frozenConn }, 8)
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.MustInject(&targets[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, target := range targets {
+		if target.Conn == nil || target.Conn != targets[0].Conn {
+			t.Fatalf("got %p; want the same value injected everywhere", target.Conn)
+		}
+	}
+
+	if err := p.AddErr(0); !errors.As(err, &ErrFrozen{}) {
+		t.Errorf("got AddErr error %v; want ErrFrozen", err)
+	}
+	if err := p.Remove(&frozenConn{}); !errors.As(err, &ErrFrozen{}) {
+		t.Errorf("got Remove error %v; want ErrFrozen", err)
+	}
+	for name, change := range map[string]func(){
+		"Add":    func() { p.Add(0) },
+		"Scope":  func() { p.Scope("other") },
+		"Strict": func() { p.Strict(true) },
+	} {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.As(err, &ErrFrozen{}) {
+					t.Errorf("%s panicked with %v; want ErrFrozen", name, err)
+				}
+			}()
+			change()
+		}()
+	}
+
+	if err := child.AddErr(0); err != nil {
+		t.Errorf("adding to a scope created before freezing failed: %s", err)
+	}
+	clone := p.Clone()
+	if clone.Frozen() {
+		t.Errorf("clone of frozen psyringe is frozen")
+	}
+	if err := clone.AddErr(0); err != nil {
+		t.Errorf("adding to clone failed: %s", err)
+	}
+}
+
+func TestErrFrozen_Error(t *testing.T) {
+	p := New()
+	child := p.Scope("child")
+	child.Freeze()
+	const want = "psyringe is frozen (scope <root>/child)"
+	if err := child.AddErr(0); err == nil || err.Error() != want {
+		t.Errorf("got %v; want %q", err, want)
+	}
+}
@@ -0,0 +1,51 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Get returns the value of injection type T from p, calling constructors as
+// necessary, exactly as if it were injecting a struct field of type T. The
+// value is cached like any other, so subsequent calls return the same value.
+//
+// Get returns NoConstructorOrValue if T is not a known injection type, or the
+// error from T's constructor if that fails.
+func Get[T any](p *Psyringe) (T, error) {
+	var value T
+	v, err := p.ResolveType(reflect.TypeOf(&value).Elem())
+	if err != nil {
+		return value, err
+	}
+	// Note: nil interface values fail this assertion, leaving value as nil.
+	value, _ = v.Interface().(T)
+	return value, nil
+}
+
+// ResolveType is like Get, for code which has only the reflect.Type of the
+// injection type, such as a router or codec.
+func (p *Psyringe) ResolveType(t reflect.Type) (reflect.Value, error) {
+	if t == nil {
+		return reflect.Value{}, fmt.Errorf("cannot resolve nil type")
+	}
+	unlock := p.rlockChain()
+	v, ok, err := p.resolve(context.Background(), t)
+	unlock()
+	if !ok {
+		return reflect.Value{}, NoConstructorOrValue{Type: t, Scope: p.errScope()}
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// MustGet wraps Get and panics if Get returns an error.
+func MustGet[T any](p *Psyringe) T {
+	value, err := Get[T](p)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
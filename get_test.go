@@ -0,0 +1,102 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() *bytes.Buffer {
+			calls.Increment()
+			return bytes.NewBufferString("hello")
+		},
+		func(b *bytes.Buffer) int { return b.Len() },
+		func() io.Reader { return nil },
+		"a string",
+	)
+
+	n, err := Get[int](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("got %d; want 5", n)
+	}
+	b1 := MustGet[*bytes.Buffer](p)
+	b2 := MustGet[*bytes.Buffer](p)
+	if b1 != b2 {
+		t.Errorf("got different *bytes.Buffer values")
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+	if s := MustGet[string](p); s != "a string" {
+		t.Errorf("got %q; want %q", s, "a string")
+	}
+	if r := MustGet[io.Reader](p); r != nil {
+		t.Errorf("got %v; want nil io.Reader", r)
+	}
+}
+
+func TestGet_fromParentScope(t *testing.T) {
+	child := New(1).Scope("child")
+	if n := MustGet[int](child); n != 1 {
+		t.Errorf("got %d; want 1", n)
+	}
+}
+
+func TestGet_errors(t *testing.T) {
+	p := New(func() (int, error) { return 0, fmt.Errorf("int error") })
+
+	_, err := Get[string](p)
+	if _, ok := err.(NoConstructorOrValue); !ok {
+		t.Errorf("got error %v (%T); want NoConstructorOrValue", err, err)
+	}
+	if got, want := err.Error(), "no constructor or value for string"; got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+
+	_, err = Get[int](p)
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	want := "invoking int constructor (func() (int, error)) failed: int error"
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+}
+
+func TestPsyringe_ResolveType(t *testing.T) {
+	var calls Counter
+	p := New(func() *bytes.Buffer {
+		calls.Increment()
+		return bytes.NewBufferString("hello")
+	})
+	bufType := reflect.TypeOf((*bytes.Buffer)(nil))
+	v1, err := p.ResolveType(bufType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := p.ResolveType(bufType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.Interface() != v2.Interface() || v1.Interface().(*bytes.Buffer).String() != "hello" {
+		t.Errorf("got %v and %v; want the same constructed buffer", v1, v2)
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+
+	if _, err := p.ResolveType(reflect.TypeOf("")); err == nil || err.Error() != "no constructor or value for string" {
+		t.Errorf("got error %v; want no constructor or value for string", err)
+	}
+	if _, err := p.ResolveType(nil); err == nil {
+		t.Errorf("got nil error resolving nil type")
+	}
+}
@@ -0,0 +1,131 @@
+package psyringe
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Graph is a snapshot of the injection types known to a Psyringe, and the
+// dependencies between them. Use Psyringe.Graph to create one.
+type Graph struct {
+	nodes []reflect.Type
+	edges map[reflect.Type][]reflect.Type
+}
+
+// Graph returns the dependency graph of p, including injection types added to
+// its parent scopes. An injection type added to a child scope shadows the same
+// one added to a parent. Named injection types are not included.
+func (p *Psyringe) Graph() *Graph {
+	defer p.rlockChain()()
+	g := &Graph{edges: map[reflect.Type][]reflect.Type{}}
+	its := p.scopeChainInjectionTypes()
+	for _, v := range p.visibleInjectionTypes(false) {
+		g.nodes = append(g.nodes, v.t)
+		if v.it.Ctor == nil {
+			continue
+		}
+		g.edges[v.t] = p.dependencies(v.it.Ctor, its)
+	}
+	sortTypes(g.nodes)
+	return g
+}
+
+// dependencies returns the injection types of c's parameters, as described by
+// Graph.Edges. its are the injection types of p and its ancestors, see
+// scopeChainInjectionTypes, which must be read-locked.
+func (p *Psyringe) dependencies(c *ctor, its []injectionTypes) []reflect.Type {
+	deps := make([]reflect.Type, len(c.inTypes))
+	for i, in := range c.inTypes {
+		deps[i] = in
+		if _, registered := p.injectionTypeRegistrationScope(in); registered {
+			continue
+		}
+		if bound, _ := p.boundType(in, its...); bound != nil {
+			deps[i] = bound
+		} else if at, _ := p.adaptedType(in); at != nil {
+			deps[i] = at
+		}
+	}
+	return deps
+}
+
+// Nodes returns every injection type in the graph, sorted by their string
+// representation.
+func (g *Graph) Nodes() []reflect.Type {
+	return append([]reflect.Type(nil), g.nodes...)
+}
+
+// Edges maps each injection type provided by a constructor to the injection
+// types of that constructor's parameters, in parameter order. Parameters of
+// interface type are mapped to the injection type bound to them, if interface
+// binding applies, and parameters of type T or *T to *T or T, if pointer
+// adaptation applies, see WithPointerAdaptation. Parameter types which are not
+// in the graph are included, so that missing dependencies are visible.
+// Injection types provided by values have no entry.
+func (g *Graph) Edges() map[reflect.Type][]reflect.Type {
+	edges := make(map[reflect.Type][]reflect.Type, len(g.edges))
+	for t, deps := range g.edges {
+		edges[t] = append([]reflect.Type(nil), deps...)
+	}
+	return edges
+}
+
+// TopoSort returns every injection type in the graph, each one after all of
+// its dependencies. Ties are broken by string representation, so the order is deterministic. If
+// the graph contains a cycle, TopoSort returns a DependencyCycle error.
+func (g *Graph) TopoSort() ([]reflect.Type, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[reflect.Type]int, len(g.nodes))
+	nodes := make(map[reflect.Type]bool, len(g.nodes))
+	for _, t := range g.nodes {
+		nodes[t] = true
+	}
+	order := make([]reflect.Type, 0, len(g.nodes))
+	var path []reflect.Type
+	var visit func(t reflect.Type) error
+	visit = func(t reflect.Type) error {
+		switch state[t] {
+		case visited:
+			return nil
+		case visiting:
+			for i, pt := range path {
+				if pt == t {
+					return DependencyCycle{Path: append(append([]reflect.Type(nil), path[i:]...), t)}
+				}
+			}
+		}
+		state[t] = visiting
+		path = append(path, t)
+		deps := append([]reflect.Type(nil), g.edges[t]...)
+		sortTypes(deps)
+		for _, dep := range deps {
+			if !nodes[dep] {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[t] = visited
+		order = append(order, t)
+		return nil
+	}
+	for _, t := range g.nodes {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// sortTypes sorts types by their string representation.
+func sortTypes(types []reflect.Type) {
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+}
@@ -0,0 +1,93 @@
+package psyringe
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPsyringe_Graph(t *testing.T) {
+	root := New(
+		"a string",
+		func(s string) *bytes.Buffer { return bytes.NewBufferString(s) },
+	)
+	root.EnableInterfaceBinding()
+	p := root.Scope("child")
+	p.Add(
+		func(r io.Reader, s string) int { return len(s) },
+		func(n int, missing float64) bool { return n > 0 },
+	)
+	g := p.Graph()
+
+	var (
+		tstring = reflect.TypeOf("")
+		tbuffer = reflect.TypeOf(&bytes.Buffer{})
+		tint    = reflect.TypeOf(0)
+		tbool   = reflect.TypeOf(true)
+		tfloat  = reflect.TypeOf(0.0)
+	)
+	wantNodes := []reflect.Type{tbuffer, tbool, tint, tstring}
+	if got := g.Nodes(); !reflect.DeepEqual(got, wantNodes) {
+		t.Errorf("got nodes %v; want %v", got, wantNodes)
+	}
+	wantEdges := map[reflect.Type][]reflect.Type{
+		tbuffer: {tstring},
+		tint:    {tbuffer, tstring},
+		tbool:   {tint, tfloat},
+	}
+	if got := g.Edges(); !reflect.DeepEqual(got, wantEdges) {
+		t.Errorf("got edges %v; want %v", got, wantEdges)
+	}
+	order, err := g.TopoSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOrder := []reflect.Type{tstring, tbuffer, tint, tbool}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("got order %v; want %v", order, wantOrder)
+	}
+}
+
+func TestGraph_TopoSort_cycle(t *testing.T) {
+	p := New()
+	p.allowAddCycle = true
+	p.Add(
+		func(int) string { return "" },
+		func(string) float64 { return 0 },
+		func(float64) int { return 0 },
+		func(int) bool { return false },
+	)
+	_, err := p.Graph().TopoSort()
+	dc, ok := err.(DependencyCycle)
+	if !ok {
+		t.Fatalf("got error %v (%T); want DependencyCycle", err, err)
+	}
+	want := "dependency cycle: int: depends on float64: depends on string: depends on int"
+	if dc.Error() != want {
+		t.Errorf("got error %q; want %q", dc, want)
+	}
+}
+
+func TestPsyringe_Graph_shadowed(t *testing.T) {
+	root := New("a string", func(s string) int { return len(s) })
+	p := root.Scope("child")
+	if err := p.AddShadow(1.0, func(f float64) int { return int(f) }); err != nil {
+		t.Fatal(err)
+	}
+	g := p.Graph()
+
+	var (
+		tstring = reflect.TypeOf("")
+		tint    = reflect.TypeOf(0)
+		tfloat  = reflect.TypeOf(0.0)
+	)
+	wantNodes := []reflect.Type{tfloat, tint, tstring}
+	if got := g.Nodes(); !reflect.DeepEqual(got, wantNodes) {
+		t.Errorf("got nodes %v; want %v", got, wantNodes)
+	}
+	wantEdges := map[reflect.Type][]reflect.Type{tint: {tfloat}}
+	if got := g.Edges(); !reflect.DeepEqual(got, wantEdges) {
+		t.Errorf("got edges %v; want %v", got, wantEdges)
+	}
+}
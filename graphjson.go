@@ -0,0 +1,99 @@
+package psyringe
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// graphJSON is the schema of MarshalGraphJSON.
+type graphJSON struct {
+	Nodes []graphJSONNode `json:"nodes"`
+	Edges []graphJSONEdge `json:"edges"`
+}
+
+type graphJSONNode struct {
+	Type         string `json:"type"`
+	Kind         string `json:"kind"`
+	RegisteredAt string `json:"registeredAt"`
+	Realized     bool   `json:"realized"`
+	Scope        string `json:"scope"`
+}
+
+type graphJSONEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	ParamIndex int    `json:"paramIndex"`
+}
+
+// MarshalGraphJSON returns the dependency graph of p, as Graph does, as JSON
+// for external tools, like:
+//
+//	{
+//	  "nodes": [
+//	    {
+//	      "type": "*example.com/app.Server",
+//	      "kind": "ctor",
+//	      "registeredAt": "/src/app/main.go:12",
+//	      "realized": false,
+//	      "scope": "<root>"
+//	    }
+//	  ],
+//	  "edges": [
+//	    {
+//	      "from": "*example.com/app.Server",
+//	      "to": "*database/sql.DB",
+//	      "paramIndex": 0
+//	    }
+//	  ]
+//	}
+//
+// Each node is an injection type added to p or its ancestors, with "kind"
+// "value" or "ctor", the file:line which added it, whether its value has
+// been realized, which is always true for values, and the path of the scope
+// which added it. An injection type added to a child scope shadows the same
+// one added to a parent. Each edge goes from an injection type provided by a
+// constructor to the injection type of one of its parameters, as in
+// Graph.Edges. Type names include the full package path.
+//
+// Nodes are sorted by type, and edges by from, then paramIndex, so that the
+// same graph always produces the same output, which can be compared with a
+// golden file.
+func (p *Psyringe) MarshalGraphJSON() ([]byte, error) {
+	defer p.rlockChain()()
+	g := graphJSON{Nodes: []graphJSONNode{}, Edges: []graphJSONEdge{}}
+	its := p.scopeChainInjectionTypes()
+	for _, v := range p.visibleInjectionTypes(false) {
+		node := graphJSONNode{
+			Type:         qualifiedTypeName(v.t),
+			Kind:         "value",
+			RegisteredAt: v.it.DebugAddedLocation,
+			Realized:     true,
+			Scope:        v.scope.scopePath(),
+		}
+		if v.it.Ctor != nil {
+			node.Kind = "ctor"
+			node.Realized = v.scope.realised(v.it.Ctor)
+			for i, dep := range p.dependencies(v.it.Ctor, its) {
+				g.Edges = append(g.Edges, graphJSONEdge{From: node.Type, To: qualifiedTypeName(dep), ParamIndex: i})
+			}
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Type < g.Nodes[j].Type })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].ParamIndex < g.Edges[j].ParamIndex
+	})
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Keep scopes like "<root>" readable.
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
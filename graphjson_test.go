@@ -0,0 +1,95 @@
+package psyringe
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_MarshalGraphJSON(t *testing.T) {
+	p := New(
+		1,
+		func(int) *bytes.Buffer { return &bytes.Buffer{} },
+	)
+	child := p.Scope("request")
+	child.Add(func(*bytes.Buffer, int) string { return "" })
+	var target struct{ Buffer *bytes.Buffer }
+	p.MustInject(&target)
+
+	data, err := child.MarshalGraphJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := child.MarshalGraphJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Errorf("output differs between calls:\n%s\n%s", data, again)
+	}
+
+	// Blank registeredAt, which depends on where the tests are.
+	registeredAt := regexp.MustCompile(`"registeredAt": "[^"]*graphjson_test.go:\d+"`)
+	if n := len(registeredAt.FindAll(data, -1)); n != 3 {
+		t.Errorf("got %d registeredAt in graphjson_test.go; want 3:\n%s", n, data)
+	}
+	got := registeredAt.ReplaceAllString(string(data), `"registeredAt": ""`)
+	const want = `{
+  "nodes": [
+    {
+      "type": "*bytes.Buffer",
+      "kind": "ctor",
+      "registeredAt": "",
+      "realized": true,
+      "scope": "<root>"
+    },
+    {
+      "type": "int",
+      "kind": "value",
+      "registeredAt": "",
+      "realized": true,
+      "scope": "<root>"
+    },
+    {
+      "type": "string",
+      "kind": "ctor",
+      "registeredAt": "",
+      "realized": false,
+      "scope": "<root>/request"
+    }
+  ],
+  "edges": [
+    {
+      "from": "*bytes.Buffer",
+      "to": "int",
+      "paramIndex": 0
+    },
+    {
+      "from": "string",
+      "to": "*bytes.Buffer",
+      "paramIndex": 0
+    },
+    {
+      "from": "string",
+      "to": "int",
+      "paramIndex": 1
+    }
+  ]
+}
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPsyringe_MarshalGraphJSON_qualified(t *testing.T) {
+	type Local struct{}
+	data, err := New(Local{}).MarshalGraphJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"type": "github.com/samsalisbury/psyringe.Local"`; !strings.Contains(string(data), want) {
+		t.Errorf("got:\n%s\nwant it to contain %s", data, want)
+	}
+}
@@ -0,0 +1,64 @@
+package psyringe
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Has reports whether the injection type of example, which is the type of
+// example itself, has been added to p or any of its ancestors. It does not
+// consider interface binding or named injection types.
+func (p *Psyringe) Has(example interface{}) bool {
+	if example == nil {
+		return false
+	}
+	return p.HasType(reflect.TypeOf(example))
+}
+
+// HasType is like Has, but takes the injection type itself, which is useful for
+// interface types.
+func (p *Psyringe) HasType(t reflect.Type) bool {
+	defer p.rlockChain()()
+	return p.has(t)
+}
+
+// has does the work of HasType. p and its ancestors must be read-locked.
+func (p *Psyringe) has(t reflect.Type) bool {
+	_, ok := p.injectionTypeRegistrationScope(t)
+	return ok || p.providesSelf(t) || p.hasSlice(t)
+}
+
+// KnownTypes returns every injection type added to p or any of its ancestors,
+// except named injection types, sorted by their string representation. Each
+// type is listed once, even if a child scope shadows it.
+func (p *Psyringe) KnownTypes() []reflect.Type {
+	defer p.rlockChain()()
+	var types []reflect.Type
+	for _, v := range p.visibleInjectionTypes(false) {
+		types = append(types, v.t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].String() != types[j].String() {
+			return types[i].String() < types[j].String()
+		}
+		return types[i].PkgPath() < types[j].PkgPath()
+	})
+	return types
+}
+
+// IsRealised reports whether p already has the value of injection type t, that
+// is, whether it was added as a value, or its constructor has been called
+// successfully. It returns false for transient constructors, and for types p
+// does not know.
+func (p *Psyringe) IsRealised(t reflect.Type) bool {
+	defer p.rlockChain()()
+	it, depth := p.find(t)
+	if it == nil {
+		return false
+	}
+	if it.Ctor == nil {
+		return true
+	}
+	call := p.ancestor(depth).calls.lookup(it.Ctor.ctorFunc)
+	return call != nil && call.realised()
+}
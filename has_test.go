@@ -0,0 +1,88 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPsyringe_Has(t *testing.T) {
+	p := New(1, func() io.Reader { return nil })
+	child := p.Scope("child")
+	child.Add("hello")
+
+	testCases := []struct {
+		psyringe *Psyringe
+		example  interface{}
+		want     bool
+	}{
+		{p, 0, true},
+		{p, "", false},
+		{child, 0, true},
+		{child, "", true},
+		{child, 1.0, false},
+		{child, nil, false},
+	}
+	for _, tc := range testCases {
+		if got := tc.psyringe.Has(tc.example); got != tc.want {
+			t.Errorf("%s: Has(%T) = %t; want %t", tc.psyringe.scope, tc.example, got, tc.want)
+		}
+	}
+	if !child.HasType(reflect.TypeOf((*io.Reader)(nil)).Elem()) {
+		t.Errorf("HasType(io.Reader) = false; want true")
+	}
+}
+
+func TestPsyringe_KnownTypes(t *testing.T) {
+	p := New(1, func() (io.Reader, *bytes.Buffer) { return nil, nil })
+	p.AddNamed("name", 2.0)
+	child := p.Scope("child")
+	child.Add("hello")
+
+	got := fmt.Sprint(child.KnownTypes())
+	const want = "[*bytes.Buffer int io.Reader string]"
+	if got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
+
+func TestPsyringe_IsRealised(t *testing.T) {
+	p := New(1, func(int) string { return "hello" })
+	if err := p.AddTransient(func() *bytes.Buffer { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	child := p.Scope("child")
+	intType, stringType := reflect.TypeOf(0), reflect.TypeOf("")
+	bufferType := reflect.TypeOf(&bytes.Buffer{})
+
+	if !child.IsRealised(intType) {
+		t.Errorf("value not realised")
+	}
+	if child.IsRealised(stringType) {
+		t.Errorf("constructor realised before being called")
+	}
+	MustGet[string](p)
+	MustGet[*bytes.Buffer](p)
+	if !child.IsRealised(stringType) {
+		t.Errorf("constructor not realised after being called")
+	}
+	if child.IsRealised(bufferType) || child.IsRealised(reflect.TypeOf(1.0)) {
+		t.Errorf("transient or unknown type realised")
+	}
+}
+
+func TestPsyringe_KnownTypes_shadowed(t *testing.T) {
+	p := New(1, "hello")
+	child := p.Scope("child")
+	if err := child.AddShadow(2); err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(child.KnownTypes())
+	const want = "[int string]"
+	if got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
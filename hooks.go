@@ -1,13 +1,32 @@
 package psyringe
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+	"time"
+)
 
 // Hooks describe a set of event hooks which are called under certain
 // circumstances during injection.
 //
-// All hooks may be called concurrently.
+// All hooks may be called concurrently. Only NoValueForStructField and
+// UnexportedFieldSkipped must be set, the rest may be left nil.
 type Hooks struct {
-	NoValueForStructField NoValueForStructFieldFunc
+	NoValueForStructField  NoValueForStructFieldFunc
+	UnexportedFieldSkipped UnexportedFieldSkippedFunc
+	ConstructorStarted     ConstructorStartedFunc
+	ConstructorFinished    ConstructorFinishedFunc
+	ValueConstructed       ValueConstructedFunc
+	ValueInjected          ValueInjectedFunc
+	FieldAlreadySet        FieldAlreadySetFunc
+	FieldOverwritten       FieldOverwrittenFunc
+	InjectStarted          InjectStartedFunc
+	InjectFinished         InjectFinishedFunc
+	TraceInject            TraceInjectFunc
+	TraceConstructor       TraceConstructorFunc
+	AddedAfterInject       AddedAfterInjectFunc
+	NilValueConstructed    NilValueConstructedFunc
+	ConstructorWarning     ConstructorWarningFunc
 }
 
 // NoValueForStructFieldFunc is called for each field in a struct passed to
@@ -21,10 +40,161 @@ type Hooks struct {
 // Inject.
 type NoValueForStructFieldFunc func(parentTypeName string, field reflect.StructField) error
 
-// newHooks returns noop hooks to avoid the need to check for nil during
-// injection.
-func newHooks() Hooks {
-	return Hooks{
-		NoValueForStructField: func(string, reflect.StructField) error { return nil },
-	}
-}
+// UnexportedFieldSkippedFunc is called for each unexported field in a struct
+// passed to Inject whose type is a known injection type. Unexported fields
+// cannot be set, so they are always skipped.
+//
+// parentTypeName is the name of the type of struct that owns the field.
+//
+// field is the field in question.
+//
+// If you return an error, that is an injection error and returned by
+// Inject.
+type UnexportedFieldSkippedFunc func(parentTypeName string, field reflect.StructField) error
+
+// ConstructorStartedFunc is called just before a constructor is called, once
+// all of its arguments are available. It is not called for constructors whose
+// arguments could not be got.
+//
+// funcType is the type of the constructor.
+//
+// outType is the injection type whose value is needed. For constructors with
+// multiple outputs, this is the first one needed.
+type ConstructorStartedFunc func(funcType, outType reflect.Type)
+
+// ConstructorFinishedFunc is called just after a constructor returns, for each
+// call of ConstructorStartedFunc.
+//
+// outType is the same as for ConstructorStartedFunc.
+//
+// duration is how long the constructor took to return.
+//
+// err is the error returned by the constructor, if any.
+type ConstructorFinishedFunc func(outType reflect.Type, duration time.Duration, err error)
+
+// ValueConstructedFunc is called after each call of ConstructorFinishedFunc,
+// so once per constructor per Psyringe, or once per use for constructors added
+// by AddTransient. It is never called for values added directly.
+//
+// outType is the same as for ConstructorStartedFunc.
+//
+// value is the value constructed for outType, or the zero reflect.Value if
+// the constructor returned an error.
+//
+// duration is how long the constructor took to return.
+//
+// err is the error returned by the constructor, if any.
+//
+// If you return an error, it replaces err, and the constructed values are
+// discarded, calling the constructor's cleanup func if it has one. Returning
+// nil leaves err as it was.
+type ValueConstructedFunc func(outType reflect.Type, value reflect.Value, duration time.Duration, err error) error
+
+// ValueInjectedFunc is called after a value is injected into a field of a
+// struct passed to Inject.
+//
+// parentTypeName is the name of the type of struct that owns the field.
+//
+// field is the field in question.
+//
+// valueType is the type of the value injected, which may differ from the
+// field's type when interface binding is enabled.
+type ValueInjectedFunc func(parentTypeName string, field reflect.StructField, valueType reflect.Type)
+
+// FieldAlreadySetFunc is called by InjectNonZero for each field it leaves
+// alone because it already holds a non-zero value.
+//
+// parentTypeName is the name of the type of struct that owns the field.
+//
+// field is the field in question.
+type FieldAlreadySetFunc func(parentTypeName string, field reflect.StructField)
+
+// FieldOverwrittenFunc is called before a value is injected into a field of a
+// struct passed to Inject which already holds a non-zero value.
+//
+// parentTypeName is the name of the type of struct that owns the field.
+//
+// field is the field in question.
+//
+// oldValue is the value the field holds, and newValue the value about to be
+// injected.
+//
+// If you return an error, the field is left as it is, and the error is an
+// injection error returned by Inject.
+type FieldOverwrittenFunc func(parentTypeName string, field reflect.StructField, oldValue, newValue reflect.Value) error
+
+// InjectStartedFunc is called once for each target passed to Inject, before
+// any of its fields are injected.
+//
+// targetType is the type of the target, normally a pointer to a struct.
+type InjectStartedFunc func(targetType reflect.Type)
+
+// InjectFinishedFunc is called after every field of a target passed to Inject
+// has been injected, including calling any constructors needed, for each call
+// of InjectStartedFunc.
+//
+// targetType is the same as for InjectStartedFunc.
+//
+// duration is how long injecting into the target took.
+//
+// err is the error injecting into the target, which is InjectErrors if more
+// than one field failed, or nil.
+type InjectFinishedFunc func(targetType reflect.Type, duration time.Duration, err error)
+
+// TraceInjectFunc is called once for each target passed to Inject, before
+// any of its fields are injected, for tracing. It returns a context derived
+// from ctx, which is passed on to TraceConstructorFunc for each constructor
+// called to inject the target, and to the constructors themselves, and a func
+// which is called with the same err as InjectFinishedFunc when injecting the
+// target finishes.
+//
+// ctx is the context passed to InjectCtx, or context.Background().
+//
+// targetType is the type of the target, normally a pointer to a struct.
+type TraceInjectFunc func(ctx context.Context, targetType reflect.Type) (context.Context, func(err error))
+
+// TraceConstructorFunc is called when a constructor's value is first needed,
+// before getting its arguments, for tracing. It returns a context derived from
+// ctx, which is passed on to TraceConstructorFunc for each constructor called
+// to get its arguments, and to the constructor itself, and a func which is
+// called with the constructor's outcome when it finishes.
+//
+// ctx is the context returned by the TraceInjectFunc or TraceConstructorFunc
+// which first needed the constructor's value. Since each constructor is only
+// called once, other targets or constructors needing the same value do not
+// get a call of their own.
+//
+// funcType and outType are the same as for ConstructorStartedFunc.
+type TraceConstructorFunc func(ctx context.Context, funcType, outType reflect.Type) (context.Context, func(err error))
+
+// AddedAfterInjectFunc is called for each injection type added to a
+// Psyringe after it has injected, with the WarnAddAfterInject policy, see
+// SetAddAfterInjectPolicy. It is called while adding, so must not add to or
+// inject from the Psyringe itself.
+//
+// t is the injection type added.
+type AddedAfterInjectFunc func(t reflect.Type)
+
+// NilValueConstructedFunc is called when a constructor returns without error,
+// but one of its values is a nil pointer, interface, map or slice, or an
+// interface holding one, such as a nil *DB returned as an io.Closer. It is
+// called before ValueConstructedFunc, once for each nil value.
+//
+// outType is the injection type of the nil value.
+//
+// funcType is the type of the constructor.
+//
+// If you return an error, the constructor fails with it, as if it had
+// returned it, and its values are discarded, calling its cleanup func if it
+// has one. Returning nil injects the nil value as usual. See
+// WithRejectNilConstructions.
+type NilValueConstructedFunc func(outType, funcType reflect.Type) error
+
+// ConstructorWarningFunc is called when a constructor returns a warning,
+// see Warning, in place of an error. It is called before
+// ValueConstructedFunc, which is passed no error.
+//
+// outType is the same as for ConstructorStartedFunc.
+//
+// err is the warning.
+type ConstructorWarningFunc func(outType reflect.Type, err error)
@@ -1,10 +1,17 @@
 package psyringe
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestHooks_NoValueForStructField(t *testing.T) {
@@ -179,3 +186,445 @@ func TestHooks_NoValueForStructField(t *testing.T) {
 
 	}
 }
+
+func TestHooks_UnexportedFieldSkipped(t *testing.T) {
+
+	type TestTargetStruct struct {
+		Int      int
+		int      int
+		unknown  string
+		AlsoInt  int
+		alsoInt  int
+		Exported string
+	}
+
+	t.Run("skipped silently by default", func(t *testing.T) {
+		p := New(func() int { return 1 })
+		target := &TestTargetStruct{}
+		if err := p.Inject(target); err != nil {
+			t.Fatal(err)
+		}
+		if target.Int != 1 || target.AlsoInt != 1 {
+			t.Errorf("exported fields not injected: %+v", target)
+		}
+		if target.int != 0 || target.alsoInt != 0 {
+			t.Errorf("unexported fields injected: %+v", target)
+		}
+	})
+
+	t.Run("hook called for known types only", func(t *testing.T) {
+		p := New(func() int { return 1 })
+		var fields []string
+		var mu sync.Mutex
+		p.Hooks.UnexportedFieldSkipped = func(parentTypeName string, field reflect.StructField) error {
+			mu.Lock()
+			defer mu.Unlock()
+			fields = append(fields, parentTypeName+"."+field.Name)
+			return fmt.Errorf("unexported field %s", field.Name)
+		}
+		err := p.Inject(&TestTargetStruct{})
+		if err == nil {
+			t.Fatal("got nil error")
+		}
+		want := "inject into *psyringe.TestTargetStruct target failed: unexported field alsoInt\n" +
+			"inject into *psyringe.TestTargetStruct target failed: unexported field int"
+		if got := err.Error(); got != want {
+			t.Errorf("got error %q; want %q", got, want)
+		}
+		sort.Strings(fields)
+		wantFields := "*psyringe.TestTargetStruct.alsoInt *psyringe.TestTargetStruct.int"
+		if got := strings.Join(fields, " "); got != wantFields {
+			t.Errorf("hook called for %q; want %q", got, wantFields)
+		}
+	})
+}
+
+func TestHooks_Constructor(t *testing.T) {
+
+	type TestTargetStruct struct {
+		Int    int
+		String string
+		Reader io.Reader
+	}
+
+	var mu sync.Mutex
+	var events []string
+	hooks := newHooks()
+	hooks.ConstructorStarted = func(funcType, outType reflect.Type) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, fmt.Sprintf("started %s (%s)", outType, funcType))
+	}
+	hooks.ConstructorFinished = func(outType reflect.Type, duration time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if duration < time.Millisecond {
+			t.Errorf("got duration %s for %s; want at least 1ms", duration, outType)
+		}
+		events = append(events, fmt.Sprintf("finished %s (%v)", outType, err))
+	}
+	hooks.ValueInjected = func(parentTypeName string, field reflect.StructField, valueType reflect.Type) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, fmt.Sprintf("injected %s.%s (%s)", parentTypeName, field.Name, valueType))
+	}
+	addCtors := func(p *Psyringe) *Psyringe {
+		p.Add(
+			func() int { time.Sleep(time.Millisecond); return 1 },
+			func(int) (string, error) {
+				time.Sleep(time.Millisecond)
+				return "", fmt.Errorf("string error")
+			},
+			func() *bytes.Buffer { time.Sleep(time.Millisecond); return &bytes.Buffer{} },
+		)
+		return p
+	}
+
+	makePsyringe := map[string]func() *Psyringe{
+		"psyringe": func() *Psyringe {
+			p := New()
+			p.Hooks = hooks
+			p.EnableInterfaceBinding()
+			return addCtors(p)
+		},
+		"clone": func() *Psyringe {
+			p := New()
+			p.Hooks = hooks
+			p.EnableInterfaceBinding()
+			return addCtors(p).Clone()
+		},
+		"scope": func() *Psyringe {
+			p := New()
+			p.Hooks = hooks
+			p.EnableInterfaceBinding()
+			return addCtors(p.Scope("child"))
+		},
+	}
+
+	want := []string{
+		"finished *bytes.Buffer (<nil>)",
+		"finished int (<nil>)",
+		"finished string (string error)",
+		"injected *psyringe.TestTargetStruct.Int (int)",
+		"injected *psyringe.TestTargetStruct.Reader (*bytes.Buffer)",
+		"started *bytes.Buffer (func() *bytes.Buffer)",
+		"started int (func() int)",
+		"started string (func(int) (string, error))",
+	}
+	for name, makeP := range makePsyringe {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			events = nil
+			mu.Unlock()
+			if err := makeP().Inject(&TestTargetStruct{}); err == nil {
+				t.Fatal("got nil error; want string error")
+			}
+			sort.Strings(events)
+			if got := strings.Join(events, "\n"); got != strings.Join(want, "\n") {
+				t.Errorf("got events:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+			}
+		})
+	}
+
+	t.Run("nil hooks", func(t *testing.T) {
+		p := addCtors(New())
+		p.Hooks = Hooks{}
+		target := &struct{ Int int }{}
+		if err := p.Inject(target); err != nil {
+			t.Fatal(err)
+		}
+		if target.Int != 1 {
+			t.Errorf("got Int %d; want 1", target.Int)
+		}
+	})
+}
+
+func TestHooks_FieldOverwritten(t *testing.T) {
+
+	type TestTargetStruct struct {
+		Int    int
+		String string
+	}
+
+	var mu sync.Mutex
+	var events []string
+	hooks := newHooks()
+	hooks.FieldOverwritten = func(parentTypeName string, field reflect.StructField, oldValue, newValue reflect.Value) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, fmt.Sprintf("overwriting %s.%s %v with %v", parentTypeName, field.Name, oldValue, newValue))
+		if field.Name == "String" {
+			return fmt.Errorf("String already set")
+		}
+		return nil
+	}
+
+	makePsyringe := map[string]func() *Psyringe{
+		"psyringe": func() *Psyringe {
+			p := New(2, "injected")
+			p.Hooks = hooks
+			return p
+		},
+		"clone": func() *Psyringe {
+			p := New(2, "injected")
+			p.Hooks = hooks
+			return p.Clone()
+		},
+		"scope": func() *Psyringe {
+			p := New()
+			p.Hooks = hooks
+			child := p.Scope("child")
+			child.Add(2, "injected")
+			return child
+		},
+	}
+
+	const wantErr = "inject into *psyringe.TestTargetStruct target failed: String already set"
+	want := []string{
+		"overwriting *psyringe.TestTargetStruct.Int 1 with 2",
+		"overwriting *psyringe.TestTargetStruct.String preset with injected",
+	}
+	for name, makeP := range makePsyringe {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			events = nil
+			mu.Unlock()
+			p := makeP()
+
+			target := TestTargetStruct{Int: 1, String: "preset"}
+			err := p.Inject(&target)
+			if err == nil || err.Error() != wantErr {
+				t.Errorf("got error %v; want %q", err, wantErr)
+			}
+			if target.Int != 2 || target.String != "preset" {
+				t.Errorf("got %+v; want Int overwritten, String left alone", target)
+			}
+			sort.Strings(events)
+			if got := strings.Join(events, "\n"); got != strings.Join(want, "\n") {
+				t.Errorf("got events:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+			}
+
+			// Zero fields are not reported.
+			mu.Lock()
+			events = nil
+			mu.Unlock()
+			p.MustInject(&TestTargetStruct{})
+			if len(events) != 0 {
+				t.Errorf("got events %q for zero fields; want none", events)
+			}
+		})
+	}
+}
+
+func TestHooks_ValueConstructed(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		C struct{}
+		D struct{}
+		E struct{}
+	)
+	var mu sync.Mutex
+	counts := map[reflect.Type]int{}
+	hooks := Hooks{ValueConstructed: func(outType reflect.Type, v reflect.Value, d time.Duration, err error) error {
+		if err != nil {
+			t.Errorf("got error %v constructing %s", err, outType)
+		}
+		if v.Type() != outType {
+			t.Errorf("got a %s value for %s", v.Type(), outType)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		counts[outType]++
+		return nil
+	}}
+	// A diamond: D depends on B and C, which both depend on A.
+	p := New(WithHooks(hooks),
+		func(string) A { return A{} },
+		func(A) B { return B{} },
+		func(A) C { return C{} },
+		func(B, C) D { return D{} },
+		"a value",
+	)
+	var t1 struct{ D D }
+	var t2 struct {
+		B B
+		C C
+		D D
+	}
+	p.MustInject(&t1, &t2)
+	p.Clone().MustInject(&t2)
+	child := p.Scope("child")
+	child.Add(func(D) E { return E{} })
+	var t3 struct {
+		D D
+		E E
+	}
+	child.MustInject(&t3)
+
+	want := map[reflect.Type]int{
+		reflect.TypeOf(A{}): 1,
+		reflect.TypeOf(B{}): 1,
+		reflect.TypeOf(C{}): 1,
+		reflect.TypeOf(D{}): 1,
+		reflect.TypeOf(E{}): 1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got counts %v; want %v", counts, want)
+	}
+}
+
+func TestHooks_ValueConstructed_veto(t *testing.T) {
+	var cleanups Counter
+	p := New(func() (int, func()) { return 1, func() { cleanups.Increment() } })
+	p.Hooks.ValueConstructed = func(outType reflect.Type, v reflect.Value, d time.Duration, err error) error {
+		return fmt.Errorf("vetoed %d", v.Interface())
+	}
+	var target struct{ Int int }
+	err := p.Inject(&target)
+	want := "inject into *struct { Int int } target failed: getting field Int (int) failed: " +
+		"invoking int constructor (func() (int, func())) failed: vetoed 1"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if target.Int != 0 {
+		t.Errorf("got Int %d; want 0", target.Int)
+	}
+	if got := cleanups.Value(); got != 1 {
+		t.Errorf("got %d cleanups; want 1", got)
+	}
+}
+
+func TestHooks_Inject(t *testing.T) {
+	type Good struct{ Int int }
+	type Bad struct {
+		String string
+		Byte   byte
+	}
+	testCases := []struct {
+		Desc         string
+		MakePsyringe func(Hooks) *Psyringe
+		// ExpectedCalls is the number of times each hook should be called.
+		ExpectedCalls int
+	}{
+		{
+			Desc: "single psyringe",
+			MakePsyringe: func(hooks Hooks) *Psyringe {
+				p := New()
+				p.Hooks = hooks
+				return p
+			},
+			ExpectedCalls: 2,
+		},
+		{
+			Desc: "cloned psyringe inherits hooks",
+			MakePsyringe: func(hooks Hooks) *Psyringe {
+				p := New()
+				p.Hooks = hooks
+				return p.Clone()
+			},
+			ExpectedCalls: 2,
+		},
+		{
+			Desc: "scoped psyringe inherits hooks",
+			MakePsyringe: func(hooks Hooks) *Psyringe {
+				p := New()
+				p.Hooks = hooks
+				return p.Scope("testScope")
+			},
+			ExpectedCalls: 2,
+		},
+		{
+			Desc: "scoped psyringe overrides hooks",
+			MakePsyringe: func(hooks Hooks) *Psyringe {
+				p := New()
+				p.Hooks = hooks
+				scoped := p.Scope("testScope")
+				scoped.Hooks.InjectStarted = nil
+				scoped.Hooks.InjectFinished = nil
+				return scoped
+			},
+			ExpectedCalls: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			var mu sync.Mutex
+			started := map[reflect.Type]int{}
+			finished := map[reflect.Type]error{}
+			var calls int
+			hooks := newHooks()
+			hooks.InjectStarted = func(targetType reflect.Type) {
+				mu.Lock()
+				defer mu.Unlock()
+				started[targetType]++
+				calls++
+			}
+			hooks.InjectFinished = func(targetType reflect.Type, d time.Duration, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if started[targetType] != 1 {
+					t.Errorf("%s finished before started", targetType)
+				}
+				finished[targetType] = err
+			}
+			p := tc.MakePsyringe(hooks)
+			p.Add(
+				func() int { return 1 },
+				func() (string, error) { return "", fmt.Errorf("string error") },
+				func() (byte, error) { return 0, fmt.Errorf("byte error") },
+			)
+			if err := p.Inject(&Good{}, &Bad{}); err == nil {
+				t.Fatal("got nil error")
+			}
+			if calls != tc.ExpectedCalls || len(finished) != tc.ExpectedCalls {
+				t.Fatalf("got %d starts and %d finishes; want %d", calls, len(finished), tc.ExpectedCalls)
+			}
+			if calls == 0 {
+				return
+			}
+			if err := finished[reflect.TypeOf(&Good{})]; err != nil {
+				t.Errorf("got error %v finishing *Good", err)
+			}
+			errs, ok := finished[reflect.TypeOf(&Bad{})].(InjectErrors)
+			if !ok || len(errs) != 2 {
+				t.Errorf("got %v finishing *Bad; want InjectErrors with 2 errors", finished[reflect.TypeOf(&Bad{})])
+			}
+		})
+	}
+}
+
+func TestHooks_Trace(t *testing.T) {
+	type key struct{}
+	p := New(
+		func() int { return 1 },
+		func(ctx context.Context, i int) string { return ctx.Value(key{}).(string) },
+	)
+	var mu sync.Mutex
+	var ended []string
+	trace := func(ctx context.Context, name string) (context.Context, func(error)) {
+		if parent, ok := ctx.Value(key{}).(string); ok {
+			name = parent + "/" + name
+		}
+		return context.WithValue(ctx, key{}, name), func(error) {
+			mu.Lock()
+			defer mu.Unlock()
+			ended = append(ended, name)
+		}
+	}
+	p.Hooks.TraceInject = func(ctx context.Context, targetType reflect.Type) (context.Context, func(error)) {
+		return trace(ctx, "inject")
+	}
+	p.Hooks.TraceConstructor = func(ctx context.Context, funcType, outType reflect.Type) (context.Context, func(error)) {
+		return trace(ctx, outType.String())
+	}
+	var target struct{ String string }
+	p.MustInject(&target)
+	if want := "inject/string"; target.String != want {
+		t.Errorf("constructor got context %q; want %q", target.String, want)
+	}
+	sort.Strings(ended)
+	if got, want := strings.Join(ended, " "), "inject inject/string inject/string/int"; got != want {
+		t.Errorf("got ended %q; want %q", got, want)
+	}
+}
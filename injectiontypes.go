@@ -1,7 +1,6 @@
 package psyringe
 
 import (
-	"fmt"
 	"reflect"
 	"sort"
 )
@@ -12,9 +11,14 @@ type injectionType struct {
 	Ctor               *ctor
 	Value              reflect.Value
 	DebugAddedLocation string
+	// Module is the name of the Module which added this injection type, if
+	// any.
+	Module string
 }
 
 // Keys returns a sorted slice of the reflect.Type keys of this collection.
+// Types are sorted by name, and types with the same name (including unnamed
+// types) by their string representation.
 func (its injectionTypes) Keys() []reflect.Type {
 	types := make([]reflect.Type, len(its))
 	i := 0
@@ -23,7 +27,10 @@ func (its injectionTypes) Keys() []reflect.Type {
 		i++
 	}
 	sort.Slice(types, func(i, j int) bool {
-		return types[i].Name() < types[j].Name()
+		if types[i].Name() != types[j].Name() {
+			return types[i].Name() < types[j].Name()
+		}
+		return types[i].String() < types[j].String()
 	})
 	return types
 }
@@ -33,31 +40,15 @@ func (its injectionTypes) Contains(t reflect.Type) bool {
 	return ok
 }
 
-func (its injectionTypes) Add(t reflect.Type, it *injectionType) error {
-	if its.Contains(t) {
-		return fmt.Errorf("type %s already registered", t)
-	}
-	its[t] = it
-	return nil
-}
-
-func (its injectionTypes) Clone() injectionTypes {
-	clone := make(injectionTypes, len(its))
+// Copy returns a copy of its, sharing the same injectionType entries. Entries
+// never change once added, so the copy can be safely added to without
+// affecting its.
+func (its injectionTypes) Copy() injectionTypes {
+	c := make(injectionTypes, len(its)+1)
 	for t, it := range its {
-		clone[t] = it.Clone()
-	}
-	return clone
-}
-
-func (it *injectionType) Clone() *injectionType {
-	clone := *it
-	if it.Ctor != nil {
-		clone.Ctor = it.Ctor.clone()
-	}
-	if (it.Value != reflect.Value{}) {
-		clone.Value = it.Value
+		c[t] = it
 	}
-	return &clone
+	return c
 }
 
 // Where filters injectionTypes by the predicate.
@@ -86,9 +77,3 @@ func (its injectionTypes) AddedAsValues() injectionTypes {
 		return it.Ctor == nil
 	})
 }
-
-func (its injectionTypes) WithRealisedValues() injectionTypes {
-	return its.Where(func(_ reflect.Type, it *injectionType) bool {
-		return it.Value.IsValid()
-	})
-}
@@ -0,0 +1,57 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// InjectNew allocates a new instance of the type of example, injects into it
+// like Inject, and returns it, for when all that is needed is one of a struct
+// type, without declaring it and passing a pointer to it. The example is
+// either a reflect.Type, or an example value, and its type is either a struct
+// type, in which case the populated struct is returned, or a pointer to one,
+// in which case a pointer to a new, populated struct is returned:
+//
+//	v, err := p.InjectNew(Handler{})
+//	h := v.(Handler)
+//
+// Errors are those Inject returns for a pointer to the struct type.
+func (p *Psyringe) InjectNew(example interface{}) (interface{}, error) {
+	if example == nil {
+		return nil, fmt.Errorf("cannot inject into new nil type")
+	}
+	t, ok := example.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(example)
+	}
+	structType := t
+	if t.Kind() == reflect.Ptr {
+		structType = t.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		p.mu.RLock()
+		message := p.withName(fmt.Sprintf("inject into %s target failed", t))
+		p.mu.RUnlock()
+		return nil, errors.Wrap(InvalidTargetError{Kind: TargetNotStruct, Type: t}, message)
+	}
+	ptr := reflect.New(structType)
+	if err := p.Inject(ptr.Interface()); err != nil {
+		return nil, err
+	}
+	if t.Kind() == reflect.Ptr {
+		return ptr.Interface(), nil
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// InjectNewT is InjectNew for a struct type, or pointer to struct type, T.
+func InjectNewT[T any](p *Psyringe) (T, error) {
+	var value T
+	v, err := p.InjectNew(reflect.TypeOf(&value).Elem())
+	if err != nil {
+		return value, err
+	}
+	return v.(T), nil
+}
@@ -0,0 +1,92 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type injectNewTarget struct {
+	Buffer *bytes.Buffer
+	String string
+}
+
+func TestPsyringe_InjectNew(t *testing.T) {
+	p := New(bytes.NewBufferString("hello"), "a string")
+	cases := map[string]interface{}{
+		"struct value":       injectNewTarget{},
+		"struct type":        reflect.TypeOf(injectNewTarget{}),
+		"pointer value":      (*injectNewTarget)(nil),
+		"pointer type":       reflect.TypeOf(&injectNewTarget{}),
+		"used pointer value": &injectNewTarget{String: "not this one"},
+	}
+	for name, example := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := p.InjectNew(example)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got injectNewTarget
+			switch v := v.(type) {
+			case injectNewTarget:
+				got = v
+			case *injectNewTarget:
+				if v == example {
+					t.Errorf("got the example; want a new value")
+				}
+				got = *v
+			default:
+				t.Fatalf("got %T; want injectNewTarget or a pointer to one", v)
+			}
+			if got.String != "a string" || got.Buffer == nil || got.Buffer.String() != "hello" {
+				t.Errorf("got %+v; want fields injected", got)
+			}
+		})
+	}
+}
+
+func TestInjectNewT(t *testing.T) {
+	p := New(bytes.NewBufferString("hello"), "a string")
+	v, err := InjectNewT[injectNewTarget](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String != "a string" {
+		t.Errorf("got String %q; want %q", v.String, "a string")
+	}
+	ptr, err := InjectNewT[*injectNewTarget](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ptr == nil || ptr.Buffer != v.Buffer {
+		t.Errorf("got %+v; want same Buffer as %+v", ptr, v)
+	}
+}
+
+func TestPsyringe_InjectNew_errors(t *testing.T) {
+	p := New(WithName("app"))
+	_, err := p.InjectNew(1)
+	const want = `psyringe "app": inject into int target failed: target must be a pointer to struct`
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	var ite InvalidTargetError
+	if !errors.As(err, &ite) || ite.Kind != TargetNotStruct || ite.Type != reflect.TypeOf(1) {
+		t.Errorf("got %#v; want InvalidTargetError for int", err)
+	}
+
+	if _, err := p.InjectNew(nil); err == nil {
+		t.Errorf("got nil error for nil example")
+	}
+
+	_, err = InjectNewT[struct{ Int int }](New(WithStrictInjection()))
+	if err == nil {
+		t.Fatalf("got nil error; want error for missing int")
+	}
+	if got := err.Error(); !strings.HasPrefix(got, "inject into *struct { Int int } target failed") {
+		t.Errorf("got error %q; want Inject's format", got)
+	}
+}
@@ -0,0 +1,38 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// injectTag is the struct tag controlling how a field is injected.
+const injectTag = "inject"
+
+// injectOption is the meaning of a field's inject tag.
+type injectOption int
+
+const (
+	// injectDefault is for fields with no inject tag, or an empty one.
+	injectDefault injectOption = iota
+	// injectOptional is for fields tagged `inject:"optional"`, which are not
+	// reported by strict mode when there is no value for them.
+	injectOptional
+	// injectNever is for fields tagged `inject:"-"`, which are never injected,
+	// even when there is a value for them.
+	injectNever
+)
+
+// parseInjectTag returns the injectOption for field, or an error if its inject
+// tag is not recognised.
+func parseInjectTag(parentTypeName string, field reflect.StructField) (injectOption, error) {
+	switch tag := field.Tag.Get(injectTag); tag {
+	case "":
+		return injectDefault, nil
+	case "optional":
+		return injectOptional, nil
+	case "-":
+		return injectNever, nil
+	default:
+		return injectDefault, fmt.Errorf("invalid inject tag %q on field %s.%s", tag, parentTypeName, field.Name)
+	}
+}
@@ -0,0 +1,72 @@
+package psyringe
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type injectTagTarget struct {
+	Int      int    `inject:"-"`
+	String   string `inject:"optional"`
+	Float    float64
+	internal int `inject:"-"`
+}
+
+func TestPsyringe_Inject_injectTag(t *testing.T) {
+	p := New(1, 2.0)
+	p.Strict(true)
+	var mu sync.Mutex
+	var hooked []string
+	p.Hooks.NoValueForStructField = func(parent string, field reflect.StructField) error {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked = append(hooked, field.Name)
+		return nil
+	}
+	p.Hooks.UnexportedFieldSkipped = func(parent string, field reflect.StructField) error {
+		t.Errorf("UnexportedFieldSkipped called for %s tagged inject:\"-\"", field.Name)
+		return nil
+	}
+	root := p.Scope("request")
+	for name, q := range map[string]*Psyringe{
+		"psyringe":       p,
+		"clone":          p.Clone(),
+		"scope":          root,
+		"clone of scope": root.Clone(),
+	} {
+		mu.Lock()
+		hooked = nil
+		mu.Unlock()
+		target := &injectTagTarget{Int: 5}
+		if err := q.Inject(target); err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if target.Int != 5 {
+			t.Errorf("%s: got Int %d; want 5 (not injected)", name, target.Int)
+		}
+		if target.Float != 2.0 {
+			t.Errorf("%s: got Float %v; want 2.0", name, target.Float)
+		}
+		if !reflect.DeepEqual(hooked, []string{"String"}) {
+			t.Errorf("%s: got NoValueForStructField called for %v; want [String]", name, hooked)
+		}
+	}
+}
+
+func TestPsyringe_Inject_invalidInjectTag(t *testing.T) {
+	p := New(1)
+	target := &struct {
+		Int int `inject:"optinal"`
+	}{}
+	err := p.Inject(target)
+	want := `inject into *struct { Int int "inject:\"optinal\"" } target failed: ` +
+		`invalid inject tag "optinal" on field *struct { Int int "inject:\"optinal\"" }.Int`
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if target.Int != 0 {
+		t.Errorf("got Int %d; want 0", target.Int)
+	}
+}
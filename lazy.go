@@ -0,0 +1,73 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Lazy gets a value of injection type T when asked, rather than when injected.
+// A field of type Lazy[T] is injected with a Lazy bound to the Psyringe
+// injecting it, if T is an injection type, so that T's constructor is not
+// called until Get is. A Lazy added as a value or constructor takes
+// precedence, as usual.
+//
+// Values are got exactly as by the package level Get, so a singleton is
+// constructed once and then returned by every call to Get, and a constructor
+// error is returned by every call. Values never got are not constructed, so
+// are not closed by Close.
+type Lazy[T any] struct {
+	p *Psyringe
+}
+
+// Get returns the value of T from the Psyringe l was injected by, constructing
+// it if necessary. It returns an error if l was not injected.
+func (l Lazy[T]) Get() (T, error) {
+	if l.p == nil {
+		var zero T
+		return zero, fmt.Errorf("%T was not injected", l)
+	}
+	return Get[T](l.p)
+}
+
+func (l *Lazy[T]) lazyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l *Lazy[T]) bind(p *Psyringe) {
+	l.p = p
+}
+
+// lazy is implemented by *Lazy[T] for every T.
+type lazy interface {
+	// lazyType returns T.
+	lazyType() reflect.Type
+	bind(p *Psyringe)
+}
+
+// tlazy is the type "psyringe.lazy".
+var tlazy = reflect.TypeOf((*lazy)(nil)).Elem()
+
+// providesLazy reports whether t is Lazy[T], and T is an injection type. p and
+// its ancestors must be read-locked.
+func (p *Psyringe) providesLazy(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(tlazy) {
+		return false
+	}
+	elem := reflect.New(t).Interface().(lazy).lazyType()
+	if p.has(elem) {
+		return true
+	}
+	bound, _ := p.boundType(elem, p.scopeChainInjectionTypes()...)
+	return bound != nil
+}
+
+// lazyValue returns a Lazy[T] bound to p, if t is Lazy[T] and T is an
+// injection type.
+func (p *Psyringe) lazyValue(t reflect.Type) (reflect.Value, bool) {
+	if !p.providesLazy(t) {
+		return reflect.Value{}, false
+	}
+	v := reflect.New(t)
+	v.Interface().(lazy).bind(p)
+	return v.Elem(), true
+}
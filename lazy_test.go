@@ -0,0 +1,97 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type (
+	lazyConn   struct{ closed bool }
+	lazyBroken struct{}
+)
+
+func (c *lazyConn) Close() error { c.closed = true; return nil }
+
+func TestLazy(t *testing.T) {
+	var calls Counter
+	p := New(func() *lazyConn { calls.Increment(); return &lazyConn{} })
+	q := p.Clone()
+	var target struct {
+		Conn    Lazy[*lazyConn]
+		Unknown Lazy[string]
+	}
+	if err := q.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 0 {
+		t.Errorf("constructor called before Get")
+	}
+	first, err := target.Conn.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := target.Conn.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || calls.Value() != 1 {
+		t.Errorf("got %d constructor calls, same value %t; want 1, true", calls.Value(), first == second)
+	}
+	// The value belongs to the clone which injected the Lazy.
+	tconn := reflect.TypeOf(first)
+	if p.IsRealised(tconn) || !q.IsRealised(tconn) {
+		t.Errorf("value realised by the wrong psyringe")
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !first.closed {
+		t.Errorf("value got by Lazy not closed")
+	}
+
+	const want = "psyringe.Lazy[string] was not injected"
+	if _, err := target.Unknown.Get(); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if err := q.TestTargets(&target); err == nil {
+		t.Errorf("got nil error from TestTargets; want Unknown reported")
+	}
+}
+
+func TestLazy_errors(t *testing.T) {
+	var calls Counter
+	p := New(func() (lazyBroken, error) {
+		calls.Increment()
+		return lazyBroken{}, fmt.Errorf("broken")
+	})
+	var target struct{ Broken Lazy[lazyBroken] }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	const want = "invoking psyringe.lazyBroken constructor (func() (psyringe.lazyBroken, error)) failed: broken"
+	for i := 0; i < 2; i++ {
+		if _, err := target.Broken.Get(); err == nil || err.Error() != want {
+			t.Errorf("get %d: got error %v; want %q", i, err, want)
+		}
+	}
+	if calls.Value() != 1 {
+		t.Errorf("got %d constructor calls; want 1", calls.Value())
+	}
+}
+
+func TestLazy_neverGotNotClosed(t *testing.T) {
+	conn := &lazyConn{}
+	p := New(func() *lazyConn { return conn })
+	var target struct{ Conn Lazy[*lazyConn] }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if conn.closed {
+		t.Errorf("value never got was closed")
+	}
+}
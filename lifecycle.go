@@ -0,0 +1,72 @@
+package psyringe
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Initializer is implemented by values needing a step after construction which
+// needs nothing more injected, such as starting background workers or
+// validating configuration. Init is called on each value a constructor
+// returns which implements it, once per constructor call, before the value is
+// injected anywhere. If Init returns an error, the constructor fails with it.
+// It is not called on values added directly, nor on nil values.
+type Initializer interface {
+	Init() error
+}
+
+// AfterInjector is implemented by targets needing a step once their fields
+// are set. AfterInject is called on each target implementing it, once for
+// each call to Inject, or any other method injecting into targets, after
+// every field has been injected successfully. If AfterInject returns an
+// error, injecting the target fails with it. The Psyringe is not locked while
+// AfterInject runs, so it may use the Psyringe, or provider funcs and Lazy
+// fields injected into the target, while other goroutines add to it.
+type AfterInjector interface {
+	AfterInject() error
+}
+
+// WithLifecycleMethods turns calling Init on constructed values implementing
+// Initializer, and AfterInject on targets implementing AfterInjector, on or
+// off. It is on by default; turn it off if calling methods implicitly is too
+// magical, or some types have methods of those names for other reasons.
+func WithLifecycleMethods(call bool) Option {
+	return func(p *Psyringe) { p.skipLifecycle = !call }
+}
+
+// initValues calls Init on each of values, the outputs of c's constructor,
+// implementing Initializer, in order, returning the first error.
+func (c *ctor) initValues(values []reflect.Value) error {
+	for i, v := range values {
+		if !v.IsValid() || !v.CanInterface() || holdsNil(v) {
+			continue
+		}
+		initializer, ok := v.Interface().(Initializer)
+		if !ok {
+			continue
+		}
+		if err := initializer.Init(); err != nil {
+			return errors.Wrapf(err, "calling Init on %s failed", c.outTypes[i])
+		}
+	}
+	return nil
+}
+
+// afterInject calls AfterInject on target, once it has been injected into
+// successfully, if it implements AfterInjector, returning any error it
+// returns. It is called once p's locks have been released, see injectTargets,
+// so that AfterInject may use p, including its provider funcs and Lazy fields.
+func (p *Psyringe) afterInject(target interface{}) []error {
+	if p.skipLifecycle {
+		return nil
+	}
+	afterInjector, ok := target.(AfterInjector)
+	if !ok {
+		return nil
+	}
+	if err := afterInjector.AfterInject(); err != nil {
+		return []error{errors.Wrapf(err, "calling AfterInject on %T failed", target)}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package psyringe
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type lifecycleWorker struct {
+	inits   int32
+	initErr error
+}
+
+func (w *lifecycleWorker) Init() error {
+	atomic.AddInt32(&w.inits, 1)
+	return w.initErr
+}
+
+type lifecycleTarget struct {
+	Worker      *lifecycleWorker
+	afterInject int
+	err         error
+}
+
+func (t *lifecycleTarget) AfterInject() error {
+	t.afterInject++
+	if t.Worker == nil || atomic.LoadInt32(&t.Worker.inits) != 1 {
+		return errors.New("worker not initialised first")
+	}
+	return t.err
+}
+
+func TestLifecycle(t *testing.T) {
+	p := New(func() *lifecycleWorker { return &lifecycleWorker{} })
+	a, b := &lifecycleTarget{}, &lifecycleTarget{}
+	if err := p.Inject(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Inject(a); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&a.Worker.inits); got != 1 {
+		t.Errorf("Init called %d times; want 1", got)
+	}
+	if a.afterInject != 2 || b.afterInject != 1 {
+		t.Errorf("AfterInject called %d and %d times; want 2 and 1", a.afterInject, b.afterInject)
+	}
+}
+
+func TestLifecycle_initError(t *testing.T) {
+	p := New(func() *lifecycleWorker { return &lifecycleWorker{initErr: errors.New("boom")} })
+	target := &lifecycleTarget{}
+	err := p.Inject(target)
+	want := "invoking *psyringe.lifecycleWorker constructor (func() *psyringe.lifecycleWorker) failed: calling Init on *psyringe.lifecycleWorker failed: boom"
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("got error %v; want it to end %q", err, want)
+	}
+	if target.afterInject != 0 {
+		t.Errorf("AfterInject called despite injection failing")
+	}
+}
+
+func TestLifecycle_afterInjectError(t *testing.T) {
+	p := New(func() *lifecycleWorker { return &lifecycleWorker{} })
+	err := p.Inject(&lifecycleTarget{err: errors.New("invalid")})
+	want := "inject into *psyringe.lifecycleTarget target failed: calling AfterInject on *psyringe.lifecycleTarget failed: invalid"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestWithLifecycleMethods_false(t *testing.T) {
+	p := New(
+		WithLifecycleMethods(false),
+		func() *lifecycleWorker { return &lifecycleWorker{initErr: errors.New("boom")} },
+	)
+	target := &lifecycleTarget{err: errors.New("invalid")}
+	if err := p.Scope("child").Inject(target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Worker.inits != 0 || target.afterInject != 0 {
+		t.Errorf("lifecycle methods called")
+	}
+}
+
+type lifecycleLazyTarget struct {
+	Buffer Lazy[*bytes.Buffer]
+	p      *Psyringe
+	got    *bytes.Buffer
+}
+
+func (t *lifecycleLazyTarget) AfterInject() error {
+	// Adding would wait forever if Inject still held its lock.
+	if err := t.p.AddErr("added in AfterInject"); err != nil {
+		return err
+	}
+	var err error
+	t.got, err = t.Buffer.Get()
+	return err
+}
+
+func TestLifecycle_afterInjectUnlocked(t *testing.T) {
+	for name, inject := range map[string]func(p *Psyringe, target interface{}) error{
+		"Inject":        func(p *Psyringe, target interface{}) error { return p.Inject(target) },
+		"Inject list":   func(p *Psyringe, target interface{}) error { return p.Inject(target, &struct{}{}) },
+		"InjectNonZero": func(p *Psyringe, target interface{}) error { return p.InjectNonZero(target) },
+		"InjectDeep":    func(p *Psyringe, target interface{}) error { return p.InjectDeep(target) },
+		"InjectWithReport": func(p *Psyringe, target interface{}) error {
+			_, err := p.InjectWithReport(target)
+			return err
+		},
+		"Plan.Execute": func(p *Psyringe, target interface{}) error {
+			plan, err := p.Plan(target)
+			if err != nil {
+				return err
+			}
+			return plan.Execute(target)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := New(func() *bytes.Buffer { return bytes.NewBufferString("hello") })
+			target := &lifecycleLazyTarget{p: p}
+			done := make(chan error, 1)
+			go func() { done <- inject(p, target) }()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("deadlocked")
+			}
+			if target.got == nil || target.got.String() != "hello" {
+				t.Errorf("got %v from Lazy; want hello buffer", target.got)
+			}
+			if !p.Has("") {
+				t.Errorf("string added in AfterInject is missing")
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package psyringe
+
+import (
+	"context"
+	"sync"
+)
+
+// WithMaxConcurrency limits the number of goroutines each call to Inject, or
+// any other method injecting or constructing values, uses at once to n,
+// including the goroutine calling it. Work which would otherwise be started in
+// a new goroutine, such as injecting a field or getting a constructor
+// argument, is done by the goroutine needing it instead when n are already
+// busy.
+//
+// With n = 1, everything is done in turn by the calling goroutine: targets,
+// fields and constructor arguments in order, so that injection happens in the
+// same order every time, which can help when debugging. The default, n = 0,
+// is no limit.
+func WithMaxConcurrency(n int) Option {
+	return func(p *Psyringe) { p.maxConcurrency = n }
+}
+
+// limiter holds a token for each goroutine an injection may start, beyond the
+// one calling it. A nil limiter has no limit.
+type limiter chan struct{}
+
+// limiterKey is the context key of the limiter for an injection.
+type limiterKey struct{}
+
+// SetSequential makes injection into this Psyringe, and any clones or scopes
+// subsequently created from it, happen one step at a time on the goroutine
+// calling Inject, or any other method injecting or constructing values:
+// targets in turn, the fields of each in declaration order, and the arguments
+// of each constructor in parameter order. Log output from constructors and
+// hooks then comes out in the same order every time, which can help when
+// debugging.
+//
+// Errors are the same as when injecting concurrently, except that a
+// dependency cycle, if one was added with WithAddTimeCycleCheck(false), is
+// always reported as a DependencyCycle with the same path, where concurrent
+// injection may find it from a different constructor each time.
+//
+// This trades throughput for reproducibility: independent constructors are no
+// longer called at the same time, so slow ones add up. Only a constructor
+// limited by SetConstructorTimeout is still called on its own goroutine.
+func (p *Psyringe) SetSequential(sequential bool) {
+	defer p.lock()()
+	p.sequential = sequential
+}
+
+// WithSequential is an Option doing the same as SetSequential(true).
+func WithSequential() Option {
+	return func(p *Psyringe) { p.sequential = true }
+}
+
+// limited returns ctx carrying a new limiter, if p is sequential or has a
+// maximum concurrency and ctx does not already carry a limiter, or ctx
+// otherwise. A limiter with no tokens makes injection sequential.
+func (p *Psyringe) limited(ctx context.Context) context.Context {
+	if ctx.Value(limiterKey{}) != nil {
+		return ctx
+	}
+	if p.sequential {
+		return context.WithValue(ctx, limiterKey{}, make(limiter))
+	}
+	if p.maxConcurrency <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, limiterKey{}, make(limiter, p.maxConcurrency-1))
+}
+
+// each calls f for each i from 0 to n-1, concurrently as far as the limiter
+// in ctx allows, and returns once every call has returned. If the limiter has
+// no tokens, the calls are made in order, and nothing is left to wait for.
+func each(ctx context.Context, n int, f func(i int)) {
+	if n == 0 {
+		return
+	}
+	if l, _ := ctx.Value(limiterKey{}).(limiter); l != nil && cap(l) == 0 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		i := i
+		spawn(ctx, func() {
+			defer wg.Done()
+			f(i)
+		})
+	}
+	wg.Wait()
+}
+
+// spawn calls f in a new goroutine, if the limiter in ctx has a token to
+// spare, or else calls f directly.
+func spawn(ctx context.Context, f func()) {
+	l, _ := ctx.Value(limiterKey{}).(limiter)
+	if l == nil {
+		go f()
+		return
+	}
+	select {
+	case l <- struct{}{}:
+		go func() {
+			defer func() { <-l }()
+			f()
+		}()
+	default:
+		f()
+	}
+}
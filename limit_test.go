@@ -0,0 +1,135 @@
+package psyringe
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	limitA string
+	limitB string
+	limitC string
+	limitD string
+)
+
+func TestWithMaxConcurrency_one(t *testing.T) {
+	// With a limit of 1, everything happens in the calling goroutine, so the
+	// race detector would complain about order being appended to unguarded.
+	var order []string
+	p := New(WithMaxConcurrency(1),
+		func(b limitB, c limitC) limitA { order = append(order, "A"); return "A" },
+		func() limitB { order = append(order, "B"); return "B" },
+		func(d limitD) limitC { order = append(order, "C"); return "C" },
+		func() limitD { order = append(order, "D"); return "D" },
+	)
+	var target struct {
+		A limitA
+		D limitD
+	}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(order), "[B D C A]"; got != want {
+		t.Errorf("got constructor calls %s; want %s", got, want)
+	}
+}
+
+func TestWithMaxConcurrency_bounded(t *testing.T) {
+	const limit = 2
+	var mu sync.Mutex
+	var running, most int
+	slow := func() {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}
+	p := New(WithMaxConcurrency(limit),
+		func(b limitB, c limitC, d limitD) limitA { slow(); return "" },
+		func() limitB { slow(); return "" },
+		func(d limitD) limitC { slow(); return "" },
+		func() limitD { slow(); return "" },
+	)
+	child := p.Scope("child")
+	targets := make([]interface{}, 8)
+	for i := range targets {
+		targets[i] = &struct {
+			A limitA
+			B limitB
+			C limitC
+		}{}
+	}
+	done := make(chan error)
+	go func() { done <- child.Inject(targets...) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("injection deadlocked")
+	}
+	if most > limit {
+		t.Errorf("got %d constructors running at once; want at most %d", most, limit)
+	}
+}
+
+func TestPsyringe_SetSequential(t *testing.T) {
+	var order []string
+	p := New(
+		func(b limitB, c limitC) limitA { order = append(order, "A"); return "A" },
+		func() limitB { order = append(order, "B"); return "B" },
+		func(d limitD) limitC { order = append(order, "C"); return "C" },
+		func() limitD { order = append(order, "D"); return "D" },
+	)
+	p.SetSequential(true)
+	var first struct{ C limitC }
+	var second struct{ A limitA }
+	if err := p.Scope("child").Inject(&first, &second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(order), "[D C B A]"; got != want {
+		t.Errorf("got constructor calls %s; want %s", got, want)
+	}
+}
+
+func TestWithSequential_sameErrors(t *testing.T) {
+	ctors := []interface{}{
+		func(b limitB, c limitC) (limitA, error) { return "", nil },
+		func() (limitB, error) { return "", fmt.Errorf("no B") },
+		func(d limitD) limitC { return "" },
+	}
+	type target struct {
+		A limitA
+		C limitC
+	}
+	want := New(ctors...).Inject(&target{}, &target{})
+	got := New(append([]interface{}{WithSequential()}, ctors...)...).Inject(&target{}, &target{})
+	if want == nil || got == nil || got.Error() != want.Error() {
+		t.Errorf("got error %v; want %v", got, want)
+	}
+}
+
+func TestWithSequential_cycle(t *testing.T) {
+	p := New(WithSequential(), WithAddTimeCycleCheck(false),
+		func(b limitB) limitA { return "" },
+		func(a limitA) limitB { return "" },
+	)
+	var target struct {
+		A limitA
+		B limitB
+	}
+	err := p.Inject(&target)
+	if !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("got error %v; want dependency cycle", err)
+	}
+}
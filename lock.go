@@ -0,0 +1,198 @@
+package psyringe
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// rwMutex is a readers-writer lock which, unlike sync.RWMutex, lets readers in
+// while a writer is waiting, so that code called while a read lock is held,
+// like a constructor calling Get, or a provider func or Lazy, can take it again
+// without deadlocking against a concurrent Add. A writer waits until there are
+// no readers at all. A read lock may be released by a different goroutine to
+// the one which took it, as when a constructor outlives the call which
+// started it.
+type rwMutex struct {
+	// readersMu guards readers.
+	readersMu sync.Mutex
+	readers   int
+	// writer is held by a writer, or on behalf of all readers while there are
+	// any.
+	writer sync.Mutex
+}
+
+func (m *rwMutex) RLock() {
+	m.readersMu.Lock()
+	m.readers++
+	if m.readers == 1 {
+		m.writer.Lock()
+	}
+	m.readersMu.Unlock()
+}
+
+func (m *rwMutex) RUnlock() {
+	m.readersMu.Lock()
+	m.readers--
+	if m.readers == 0 {
+		m.writer.Unlock()
+	}
+	m.readersMu.Unlock()
+}
+
+func (m *rwMutex) Lock() {
+	m.writer.Lock()
+}
+
+func (m *rwMutex) Unlock() {
+	m.writer.Unlock()
+}
+
+// lock write-locks p and read-locks its ancestors, and returns a func to
+// unlock them all. It panics with ErrFrozen if p is frozen, so methods which
+// return errors check frozenErr first.
+func (p *Psyringe) lock() (unlock func()) {
+	p.mu.Lock()
+	if err := p.frozenErr(); err != nil {
+		p.mu.Unlock()
+		panic(err)
+	}
+	unlockParents := func() {}
+	if p.parent != nil {
+		unlockParents = p.parent.rlockChain()
+	}
+	return func() {
+		// Nothing can be rolled back once unlocked.
+		p.changes = nil
+		unlockParents()
+		p.mu.Unlock()
+	}
+}
+
+// rlockChain read-locks p and its ancestors, and returns a func to unlock them.
+// Locks are always taken from child to parent, so that concurrent calls cannot
+// deadlock.
+func (p *Psyringe) rlockChain() (unlock func()) {
+	if p.chainFrozen() {
+		// Nothing can change, so there is nothing to lock.
+		return func() {}
+	}
+	var locked []*Psyringe
+	for q := p; q != nil; q = q.parent {
+		q.mu.RLock()
+		locked = append(locked, q)
+	}
+	return func() {
+		for _, q := range locked {
+			q.mu.RUnlock()
+		}
+	}
+}
+
+// unshare gives p its own copies of the maps of injection types, if it may
+// share them with clones, so that they can be changed, and discards its plans,
+// which are about to be out of date. It returns a func which undoes every
+// change made to the maps since through setInjectionType, setNamed and
+// setSlice, for a change which fails part way to leave p as it was. p must be
+// write-locked.
+func (p *Psyringe) unshare() (rollback func()) {
+	if atomic.LoadInt32(p.shared) != 0 {
+		p.injectionTypes = p.injectionTypes.Copy()
+		p.named = copyNamed(p.named)
+		p.slices = p.slices.Copy()
+		p.shared = new(int32)
+	}
+	p.invalidatePlans()
+	mark := len(p.changes)
+	return func() {
+		for i := len(p.changes) - 1; i >= mark; i-- {
+			p.changes[i].undo(p)
+		}
+		p.changes = p.changes[:mark]
+		p.invalidatePlans()
+	}
+}
+
+// share records that another Psyringe may read p's maps of injection types
+// without holding p's lock, so that p copies them before changing them, see
+// unshare. p must be at least read-locked.
+func (p *Psyringe) share() {
+	atomic.StoreInt32(p.shared, 1)
+}
+
+// change is a change to one of the maps of injection types of a Psyringe, see
+// unshare.
+type change struct {
+	// name is the name of a named injection type, if named is true.
+	name  string
+	named bool
+	// slice is true for a change to slices, see AddToSlice.
+	slice bool
+	t     reflect.Type
+	// it or s is what was there before, or nil if there was nothing.
+	it *injectionType
+	s  *sliceType
+}
+
+// undo puts back what was there before c.
+func (c change) undo(p *Psyringe) {
+	switch {
+	case c.named:
+		if c.it != nil {
+			p.named[c.name][c.t] = c.it
+			return
+		}
+		delete(p.named[c.name], c.t)
+		if len(p.named[c.name]) == 0 {
+			delete(p.named, c.name)
+		}
+	case c.slice:
+		if c.s != nil {
+			p.slices[c.t] = c.s
+		} else {
+			delete(p.slices, c.t)
+		}
+	default:
+		if c.it != nil {
+			p.injectionTypes[c.t] = c.it
+		} else {
+			delete(p.injectionTypes, c.t)
+		}
+	}
+}
+
+// setInjectionType sets injection type t of p to it, or removes it if it is
+// nil. p must be write-locked and unshared.
+func (p *Psyringe) setInjectionType(t reflect.Type, it *injectionType) {
+	p.changes = append(p.changes, change{t: t, it: p.injectionTypes[t]})
+	if it == nil {
+		delete(p.injectionTypes, t)
+		return
+	}
+	p.injectionTypes[t] = it
+}
+
+// setNamed sets injection type t named name of p to it, or removes it if it is
+// nil. p must be write-locked and unshared.
+func (p *Psyringe) setNamed(name string, t reflect.Type, it *injectionType) {
+	p.changes = append(p.changes, change{name: name, named: true, t: t, it: p.named[name][t]})
+	if it == nil {
+		delete(p.named[name], t)
+		return
+	}
+	if p.named[name] == nil {
+		p.named[name] = injectionTypes{}
+	}
+	p.named[name][t] = it
+}
+
+// setSlice sets the elements of slice type t of p to s, or removes them if s
+// is nil. p must be write-locked and unshared.
+func (p *Psyringe) setSlice(t reflect.Type, s *sliceType) {
+	p.changes = append(p.changes, change{slice: true, t: t, s: p.slices[t]})
+	if s == nil {
+		delete(p.slices, t)
+		return
+	}
+	p.slices[t] = s
+}
@@ -0,0 +1,132 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeConflict describes an injection type added to both Psyringes passed to
+// Merge or Union.
+type MergeConflict struct {
+	// Type is the injection type added to both.
+	Type reflect.Type
+	// Name is the name of the injection type, if it was added by AddNamed.
+	Name string
+	// Location and OtherLocation are where Type was added to the receiver
+	// of Merge, and to the Psyringe being merged in, respectively.
+	Location, OtherLocation string
+}
+
+// Error returns a message like "injection type T added at a.go:1 and at
+// b.go:2".
+func (mc MergeConflict) Error() string {
+	what := mc.Type.String()
+	if mc.Name != "" {
+		what = fmt.Sprintf("%s named %q", mc.Type, mc.Name)
+	}
+	return fmt.Sprintf("injection type %s added at %s and at %s", what, mc.Location, mc.OtherLocation)
+}
+
+// MergeConflicts is returned by Merge and Union when any injection type was
+// added to more than one Psyringe. Conflicts are ordered by name, then type.
+type MergeConflicts []MergeConflict
+
+// Error returns each conflict on its own line.
+func (mcs MergeConflicts) Error() string {
+	messages := make([]string, len(mcs))
+	for i, mc := range mcs {
+		messages[i] = mc.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Merge adds everything added directly to other to p, including named
+// injection types and slice elements, but not anything added to other's parent
+// scopes. Values
+// already constructed by either Psyringe are kept, so constructors already
+// called by other are not called again by p.
+//
+// p keeps its own Hooks and other settings, those of other are ignored.
+//
+// If any injection type was added to both, nothing is merged, and
+// MergeConflicts listing every such type is returned. Elements added to the
+// same slice type by AddToSlice to both do not conflict: the slice has p's
+// elements followed by other's, as if they had all been added to p.
+func (p *Psyringe) Merge(other *Psyringe) error {
+	// Note: other is read before locking p, so that concurrently merging p
+	// into other cannot deadlock. Sharing the maps read means other copies
+	// them before changing them, see unshare.
+	unlock := other.rlockChain()
+	other.share()
+	its, named, slices := other.injectionTypes, other.named, other.slices
+	calls := other.calls.cloneRealised()
+	modules := other.modules
+	unlock()
+
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	var conflicts MergeConflicts
+	for _, t := range its.Keys() {
+		if scoped, ok := p.injectionTypeRegistrationScope(t); ok {
+			conflicts = append(conflicts, MergeConflict{
+				Type:          t,
+				Location:      scoped.injectionTypes[t].DebugAddedLocation,
+				OtherLocation: its[t].DebugAddedLocation,
+			})
+		}
+	}
+	for _, name := range sortedNames(named) {
+		for _, t := range named[name].Keys() {
+			if scoped, ok := p.namedRegistrationScope(name, t); ok {
+				conflicts = append(conflicts, MergeConflict{
+					Type:          t,
+					Name:          name,
+					Location:      scoped.named[name][t].DebugAddedLocation,
+					OtherLocation: named[name][t].DebugAddedLocation,
+				})
+			}
+		}
+	}
+	if len(conflicts) != 0 {
+		return conflicts
+	}
+	p.unshare()
+	for t, it := range its {
+		p.setInjectionType(t, it)
+	}
+	for name, its := range named {
+		for t, it := range its {
+			p.setNamed(name, t, it)
+		}
+	}
+	for st, s := range slices {
+		if existing, ok := p.slices[st]; ok {
+			s = existing.merged(st, s)
+		}
+		p.setSlice(st, s)
+	}
+	p.calls.merge(calls)
+	p.modules = append(p.modules[:len(p.modules):len(p.modules)], modules...)
+	return nil
+}
+
+// Union returns a new Psyringe with everything added directly to each of ps
+// merged in, as Merge does. It has default Hooks and settings.
+func Union(ps ...*Psyringe) (*Psyringe, error) {
+	u := New()
+	var conflicts MergeConflicts
+	for _, p := range ps {
+		err := u.Merge(p)
+		if err == nil {
+			continue
+		}
+		conflicts = append(conflicts, err.(MergeConflicts)...)
+	}
+	if len(conflicts) != 0 {
+		return nil, conflicts
+	}
+	return u, nil
+}
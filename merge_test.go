@@ -0,0 +1,115 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestPsyringe_Merge(t *testing.T) {
+	var intCalls, stringCalls Counter
+	storage := New(func() int { return int(intCalls.Increment()) })
+	storage.AddNamed("dsn", "db://")
+	var d dependent
+	storage.MustInject(&d)
+
+	http := New(func(i int) string { stringCalls.Increment(); return "http" })
+	var hooked Counter
+	http.Hooks.ValueInjected = func(string, reflect.StructField, reflect.Type) { hooked.Increment() }
+	if err := http.Merge(storage); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Int    int
+		String string
+		DSN    string `psyringe:"dsn"`
+	}
+	http.MustInject(&target)
+	if target.Int != 1 || target.String != "http" || target.DSN != "db://" {
+		t.Errorf("got %+v", target)
+	}
+	// The int constructor was already called by storage.
+	if intCalls.Value() != 1 || stringCalls.Value() != 1 {
+		t.Errorf("got %d int calls and %d string calls; want 1 and 1", intCalls.Value(), stringCalls.Value())
+	}
+	if hooked.Value() != 3 {
+		t.Errorf("receiver's hooks not called")
+	}
+	// storage is unchanged.
+	if storage.Has("") {
+		t.Errorf("merged into other")
+	}
+}
+
+func TestPsyringe_Merge_conflicts(t *testing.T) {
+	a := New(1, "a", bytes.NewBufferString("a"))
+	a.AddNamed("name", 1.0)
+	b := New(2, func() string { return "b" })
+	b.AddNamed("name", 2.0)
+	err := a.Merge(b)
+	want := regexp.MustCompile(`^injection type int added at .*/merge_test.go:\d+ and at .*/merge_test.go:\d+
+injection type string added at .*/merge_test.go:\d+ and at .*/merge_test.go:\d+
+injection type float64 named "name" added at .*/merge_test.go:\d+ and at .*/merge_test.go:\d+$`)
+	if err == nil || !want.MatchString(err.Error()) {
+		t.Errorf("got error %v; want match for %s", err, want)
+	}
+	if conflicts, ok := err.(MergeConflicts); !ok || len(conflicts) != 3 {
+		t.Errorf("got %T with %d conflicts; want MergeConflicts with 3", err, len(conflicts))
+	}
+}
+
+func TestPsyringe_Merge_slices(t *testing.T) {
+	p := New()
+	p.AddToSlice("a")
+	other := New()
+	other.AddToSlice("b", func() string { return "c" })
+	other.AddToSlice(1)
+	var before struct{ Strings []string }
+	other.MustInject(&before)
+	if err := p.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Strings []string
+		Ints    []int
+	}
+	p.MustInject(&target)
+	if got, want := target.Strings, []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got Strings %q; want %q", got, want)
+	}
+	if got, want := target.Ints, []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got Ints %v; want %v", got, want)
+	}
+	// other is unchanged.
+	var after struct{ Strings []string }
+	other.MustInject(&after)
+	if got, want := after.Strings, []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("other's Strings: got %q; want %q", got, want)
+	}
+
+	u, err := Union(other, New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.Strings = nil
+	u.MustInject(&target)
+	if got, want := target.Strings, []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Union: got Strings %q; want %q", got, want)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	u, err := Union(New(1), New("a"), New(bytes.NewBufferString("b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d dependent
+	u.MustInject(&d)
+	if d.Int != 1 || d.String != "a" || d.Buffer.String() != "b" {
+		t.Errorf("got %+v", d)
+	}
+	if _, err := Union(New(1), New(2)); err == nil {
+		t.Errorf("got nil error; want conflict")
+	}
+}
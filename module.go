@@ -0,0 +1,76 @@
+package psyringe
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Module is a named group of constructors and values, typically provided by a
+// single package. Modules only affect bookkeeping: errors about injection
+// types added twice name the modules involved, and Describe reports which
+// module added each injection type.
+type Module struct {
+	// Name identifies the module, it must be unique within a Psyringe and its
+	// ancestors.
+	Name string
+	// Provides are the constructors and values the module adds, as for Add.
+	Provides []interface{}
+}
+
+// AddModule adds the constructors and values provided by m, as AddErr does. If
+// any error is returned, nothing is added.
+func (p *Psyringe) AddModule(m Module) error {
+	return p.addModule(m)
+}
+
+// addModule just exists to make callerinfo consistent in Psyringe.add.
+func (p *Psyringe) addModule(m Module) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	if m.Name == "" {
+		return fmt.Errorf("cannot add module with no name")
+	}
+	for q := p; q != nil; q = q.parent {
+		for _, name := range q.modules {
+			if name == m.Name {
+				return fmt.Errorf("module %q already added (scope %s)", m.Name, q.scopePath())
+			}
+		}
+	}
+	for i, thing := range m.Provides {
+		if thing == nil {
+			return fmt.Errorf("adding module %q failed: cannot add nil (argument %d)", m.Name, i)
+		}
+	}
+	rollback := p.unshare()
+	p.addingModule = m.Name
+	defer func() { p.addingModule = "" }()
+	for _, thing := range m.Provides {
+		if err := p.add(thing); err != nil {
+			rollback()
+			return errors.Wrapf(err, "adding module %q failed", m.Name)
+		}
+	}
+	// Note: the full slice expression makes sure appending never writes to an
+	// array shared with clones.
+	p.modules = append(p.modules[:len(p.modules):len(p.modules)], m.Name)
+	return nil
+}
+
+// Modules returns the names of the modules added to p and its ancestors, in
+// the order they were added, starting with the root scope's.
+func (p *Psyringe) Modules() []string {
+	defer p.rlockChain()()
+	var scopes [][]string
+	for q := p; q != nil; q = q.parent {
+		scopes = append(scopes, q.modules)
+	}
+	var modules []string
+	for i := len(scopes) - 1; i >= 0; i-- {
+		modules = append(modules, scopes[i]...)
+	}
+	return modules
+}
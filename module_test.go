@@ -0,0 +1,46 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_AddModule(t *testing.T) {
+	p := New()
+	if err := p.AddModule(Module{Name: "db", Provides: []interface{}{1, "dsn"}}); err != nil {
+		t.Fatal(err)
+	}
+	child := p.Scope("child")
+	if err := child.AddModule(Module{Name: "cache", Provides: []interface{}{&bytes.Buffer{}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := child.Modules(), []string{"db", "cache"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got modules %q; want %q", got, want)
+	}
+	if got := p.Modules(); !reflect.DeepEqual(got, []string{"db"}) {
+		t.Errorf("got parent modules %q; want [db]", got)
+	}
+	r, ok := child.Describe(0)
+	if !ok || r.Module != "db" || !strings.Contains(r.String(), `added by module "db" at `) {
+		t.Errorf("got registration %s; want added by module db", r)
+	}
+
+	err := child.AddModule(Module{Name: "other", Provides: []interface{}{2.0, 2}})
+	const want = `adding module "other" failed: adding int value failed: injection type int already registered by module "db" (now being added by module "other") at `
+	if err == nil || !strings.HasPrefix(err.Error(), want) || !strings.HasSuffix(err.Error(), "(scope <root>)") {
+		t.Errorf("got error %v; want %q...(scope <root>)", err, want)
+	}
+	if child.Has(2.0) {
+		t.Errorf("added float64 from failed module")
+	}
+
+	const wantDup = `module "db" already added (scope <root>)`
+	if err := child.AddModule(Module{Name: "db"}); err == nil || err.Error() != wantDup {
+		t.Errorf("got error %v; want %q", err, wantDup)
+	}
+	if got := child.Modules(); len(got) != 2 {
+		t.Errorf("got modules %q after failures; want 2", got)
+	}
+}
@@ -0,0 +1,44 @@
+package psyringe
+
+import "fmt"
+
+// SetName names p, so that errors from adding to, injecting from and testing
+// it, and its debug messages, say which Psyringe they came from, as in
+// `psyringe "request": inject into *Handler target failed: ...`, when a
+// program has more than one. Errors and debug messages are unchanged for a
+// Psyringe with no name, the default. Clones keep the name. A scope created
+// from a named Psyringe is named after it, as in "app/request", see Scope.
+//
+// Naming a root Psyringe names its root scope too, as WithName does.
+func (p *Psyringe) SetName(name string) {
+	defer p.lock()()
+	p.setName(name)
+}
+
+// setName does the work of SetName and WithName.
+func (p *Psyringe) setName(name string) {
+	p.name = name
+	if p.parent != nil {
+		return
+	}
+	p.scope = name
+	if name == "" {
+		p.scope = rootScopeName
+	}
+}
+
+// Name returns the name given to p by SetName, or the name of a scope
+// derived from its parent's, or "" if it has none.
+func (p *Psyringe) Name() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.name
+}
+
+// withName returns message prefixed with p's name, if it has one.
+func (p *Psyringe) withName(message string) string {
+	if p.name == "" {
+		return message
+	}
+	return fmt.Sprintf("psyringe %q: %s", p.name, message)
+}
@@ -0,0 +1,81 @@
+package psyringe
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPsyringe_SetName(t *testing.T) {
+	p := New(func(int) string { return "" })
+	p.SetName("request")
+	if got := p.Name(); got != "request" {
+		t.Errorf("got name %q; want %q", got, "request")
+	}
+
+	err := p.AddErr(func() string { return "" })
+	wantAdd := regexp.MustCompile(`^adding constructor func\(\) string failed: psyringe "request": injection type string already registered at .*/name_test.go:\d+$`)
+	if err == nil || !wantAdd.MatchString(err.Error()) {
+		t.Errorf("got error %v; want match %q", err, wantAdd)
+	}
+
+	var target struct{ String string }
+	err = p.Inject(&target)
+	wantInject := `psyringe "request": inject into *struct { String string } target failed: `
+	if err == nil || !strings.HasPrefix(err.Error(), wantInject) {
+		t.Errorf("got error %v; want prefix %q", err, wantInject)
+	}
+
+	err = p.Test()
+	wantTest := `psyringe "request": unable to satisfy constructor func(int) string: `
+	if err == nil || !strings.HasPrefix(err.Error(), wantTest) {
+		t.Errorf("got error %v; want prefix %q", err, wantTest)
+	}
+	if report, ok := err.(TestReport); !ok || report.Psyringe != "request" {
+		t.Errorf("got %#v; want TestReport for psyringe request", err)
+	}
+
+	if got := p.Clone().Name(); got != "request" {
+		t.Errorf("got clone name %q; want %q", got, "request")
+	}
+}
+
+func TestPsyringe_SetName_scope(t *testing.T) {
+	p := New()
+	if got := p.Scope("unnamed").Name(); got != "" {
+		t.Errorf("got scope name %q; want none", got)
+	}
+	p.SetName("app")
+	child := p.Scope("request")
+	if got := child.Name(); got != "app/request" {
+		t.Errorf("got scope name %q; want %q", got, "app/request")
+	}
+	child.SetName("req")
+	if got := child.Name(); got != "req" {
+		t.Errorf("got scope name %q; want %q", got, "req")
+	}
+	if got := child.scopePath(); got != "app/request" {
+		t.Errorf("got scope path %q; want it unchanged by naming a child", got)
+	}
+	p.SetName("")
+	if got := p.scopePath(); got != rootScopeName {
+		t.Errorf("got root scope %q; want %q", got, rootScopeName)
+	}
+}
+
+func TestPsyringe_SetName_debug(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	debug := func(format string, a ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, a[0].(string))
+	}
+	New(WithDebugFunc(debug), WithName("job"), 1)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], `psyringe "job": added value for int at `) {
+		t.Errorf("got debug messages %q", messages)
+	}
+}
@@ -0,0 +1,158 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// nameTag is the struct tag used to select a named injection type.
+const nameTag = "psyringe"
+
+// AddNamed adds a constructor or value to the Psyringe under name. Named
+// injection types allow multiple values of the same type in one Psyringe. They
+// are only injected into struct fields with a matching tag, for example:
+//
+//     p.AddNamed("dbHost", func() string { return "localhost" })
+//
+//     type Config struct {
+//         DBHost string `psyringe:"dbHost"`
+//     }
+//
+// Fields without a tag are never injected with named values, and tagged fields
+// are never injected with unnamed ones. Named constructors have their
+// parameters satisfied by unnamed injection types in the usual way.
+//
+// AddNamed panics if the same injection type has already been added with the
+// same name to this Psyringe or its ancestors (see Scope).
+func (p *Psyringe) AddNamed(name string, constructorOrValue interface{}) {
+	if err := p.addNamed(name, constructorOrValue); err != nil {
+		panic(err)
+	}
+}
+
+// AddNamedErr is similar to AddNamed, but returns an error instead of
+// panicking.
+func (p *Psyringe) AddNamedErr(name string, constructorOrValue interface{}) error {
+	return p.addNamed(name, constructorOrValue)
+}
+
+func (p *Psyringe) addNamed(name string, thing interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	if thing == nil {
+		return fmt.Errorf("cannot add nil (named %q)", name)
+	}
+	rollback := p.unshare()
+	v := reflect.ValueOf(thing)
+	t := v.Type()
+	if ctors := newCtors(t, v); ctors != nil {
+		for i, c := range ctors {
+			err := p.registerNamedInjectionType(name, c.outType, &injectionType{Ctor: c})
+			if err != nil && len(ctors) > 1 {
+				err = errors.Wrapf(err, "return %d", i)
+			}
+			if err != nil {
+				rollback()
+				return errors.Wrapf(err, "adding constructor %s named %q failed", t, name)
+			}
+		}
+		return nil
+	}
+	return errors.Wrapf(p.registerNamedInjectionType(name, t, &injectionType{Value: v}),
+		"adding %s value named %q failed", t, name)
+}
+
+func (p *Psyringe) registerNamedInjectionType(name string, t reflect.Type, it *injectionType) error {
+	if err := p.checkNotErrorType(t); err != nil {
+		return err
+	}
+	if scopedPsyringe, registered := p.namedRegistrationScope(name, t); registered {
+		message := p.withName(fmt.Sprintf("injection type %s named %q already registered at %s",
+			t, name, scopedPsyringe.named[name][t].DebugAddedLocation))
+		if scopedPsyringe.scope == p.scope {
+			return errors.New(message)
+		}
+		return fmt.Errorf("%s (scope %s)", message, scopedPsyringe.scopePath())
+	}
+	if err := p.checkAddAfterInject(t); err != nil {
+		return err
+	}
+	_, file, line, _ := runtime.Caller(3)
+	it.DebugAddedLocation = fmt.Sprintf("%s:%d", file, line)
+	if p.named[name].Contains(t) {
+		return fmt.Errorf("type %s already registered", t)
+	}
+	p.setNamed(name, t, it)
+	p.debug(addedEvent(t, name, it))
+	return nil
+}
+
+func (p *Psyringe) namedRegistrationScope(name string, t reflect.Type) (*Psyringe, bool) {
+	if p.named[name].Contains(t) {
+		return p, true
+	}
+	if p.parent == nil {
+		return nil, false
+	}
+	return p.parent.namedRegistrationScope(name, t)
+}
+
+// resolveNamed is like resolve, but for named injection types.
+func (p *Psyringe) resolveNamed(ctx context.Context, name string, t reflect.Type) (reflect.Value, bool, error) {
+	if it, ok := p.named[name][t]; ok {
+		if it.Ctor == nil {
+			return it.Value, true, nil
+		}
+		v, err := it.Ctor.getValue(ctx, p, ctorPath(ctx))
+		return v, true, err
+	}
+	if p.parent != nil {
+		return p.parent.resolveNamed(ctx, name, t)
+	}
+	return reflect.Value{}, false, nil
+}
+
+// testNamed adds a problem to report for each parameter of a named
+// constructor which is not satisfied.
+func (p *Psyringe) testNamed(report *TestReport) {
+	for _, name := range sortedNames(p.named) {
+		ctors := p.named[name].AddedAsCtors()
+		tested := map[*ctorFunc]bool{}
+		for _, outType := range ctors.Keys() {
+			c := ctors[outType].Ctor
+			if tested[c.ctorFunc] {
+				continue
+			}
+			tested[c.ctorFunc] = true
+			for _, err := range c.testParametersAreRegisteredIn(p) {
+				report.add(c, name, err)
+			}
+		}
+	}
+}
+
+// sortedNames returns the names in named, sorted.
+func sortedNames(named map[string]injectionTypes) []string {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// copyNamed copies all named injection types, see injectionTypes.Copy.
+func copyNamed(named map[string]injectionTypes) map[string]injectionTypes {
+	c := make(map[string]injectionTypes, len(named)+1)
+	for name, its := range named {
+		c[name] = its.Copy()
+	}
+	return c
+}
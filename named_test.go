@@ -0,0 +1,109 @@
+package psyringe
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+type namedTarget struct {
+	Default  string
+	DBHost   string `psyringe:"dbHost"`
+	DBPort   int    `psyringe:"dbPort"`
+	CacheURL string `psyringe:"cacheURL"`
+	Unknown  string `psyringe:"unknown"`
+}
+
+func TestPsyringe_AddNamed(t *testing.T) {
+	p := New("default", func() int { return 5432 })
+	p.AddNamed("dbHost", "db.local")
+	// Named constructors consume unnamed injection types.
+	p.AddNamed("dbPort", func(i int) int { return i + 1 })
+	p.AddNamed("cacheURL", func(host string) (string, error) { return "redis://" + host, nil })
+
+	target := namedTarget{Unknown: "untouched"}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	want := namedTarget{
+		Default:  "default",
+		DBHost:   "db.local",
+		DBPort:   5433,
+		CacheURL: "redis://default",
+		Unknown:  "untouched",
+	}
+	if target != want {
+		t.Errorf("got %+v; want %+v", target, want)
+	}
+	if err := p.Test(); err != nil {
+		t.Errorf("Test failed: %s", err)
+	}
+}
+
+func TestPsyringe_AddNamed_scopeAndClone(t *testing.T) {
+	root := New()
+	root.AddNamed("dbHost", "db.local")
+	child := root.Scope("child")
+	child.AddNamed("cacheURL", func() string { return "redis://cache" })
+
+	var target namedTarget
+	child.Clone().MustInject(&target)
+	if target.DBHost != "db.local" || target.CacheURL != "redis://cache" {
+		t.Errorf("named values not injected: %+v", target)
+	}
+}
+
+func TestPsyringe_AddNamed_errors(t *testing.T) {
+	p := New()
+	p.AddNamed("dbHost", "db.local")
+	// Same type, different name is fine.
+	p.AddNamed("cacheURL", "redis://cache")
+
+	err := p.AddNamedErr("dbHost", func() string { return "other" })
+	wantPattern := `^adding constructor func\(\) string named "dbHost" failed: injection type string named "dbHost" already registered at .*/named_test.go:\d+$`
+	if err == nil {
+		t.Fatalf("got nil error; want %q", wantPattern)
+	}
+	if !regexp.MustCompile(wantPattern).MatchString(err.Error()) {
+		t.Errorf("got error %q; want match %q", err, wantPattern)
+	}
+
+	err = p.Scope("child").AddNamedErr("dbHost", "other")
+	wantPattern = `^adding string value named "dbHost" failed: injection type string named "dbHost" already registered at .*/named_test.go:\d+ \(scope <root>\)$`
+	if err == nil {
+		t.Fatalf("got nil error; want %q", wantPattern)
+	}
+	if !regexp.MustCompile(wantPattern).MatchString(err.Error()) {
+		t.Errorf("got error %q; want match %q", err, wantPattern)
+	}
+
+	if err := p.AddNamedErr("x", nil); err == nil || err.Error() != `cannot add nil (named "x")` {
+		t.Errorf("got error %v; want %q", err, `cannot add nil (named "x")`)
+	}
+}
+
+func TestPsyringe_AddNamed_injectErrors(t *testing.T) {
+	p := New()
+	p.AddNamed("dbPort", func() (int, error) { return 0, fmt.Errorf("no port") })
+	err := p.Inject(&namedTarget{})
+	want := `inject into *psyringe.namedTarget target failed: getting field DBPort (int named "dbPort") failed: invoking int constructor (func() (int, error)) failed: no port`
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+}
+
+func TestPsyringe_AddNamed_Test(t *testing.T) {
+	p := New()
+	p.AddNamed("dbPort", func(string) int { return 0 })
+	err := p.Test()
+	want := `unable to satisfy constructor func(string) int named "dbPort": unable to satisfy param 0: no constructor or value for string`
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package psyringe
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type nilDB struct{}
+
+func (*nilDB) Close() error { return nil }
+
+func TestHooks_NilValueConstructed(t *testing.T) {
+	cases := []struct {
+		name string
+		ctor interface{}
+		nil  bool
+	}{
+		{"nil pointer", func() *nilDB { return nil }, true},
+		{"nil interface", func() io.Closer { return nil }, true},
+		{"typed nil in interface", func() io.Closer { return (*nilDB)(nil) }, true},
+		{"nil map", func() map[string]int { return nil }, true},
+		{"nil slice", func() []int { return nil }, true},
+		{"non-nil pointer", func() *nilDB { return &nilDB{} }, false},
+		{"non-nil interface", func() io.Closer { return &nilDB{} }, false},
+		{"empty map", func() map[string]int { return map[string]int{} }, false},
+		{"zero int", func() int { return 0 }, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotOut, gotFunc reflect.Type
+			p := New(tc.ctor)
+			p.Hooks.NilValueConstructed = func(outType, funcType reflect.Type) error {
+				gotOut, gotFunc = outType, funcType
+				return nil
+			}
+			if err := p.RealiseAll(); err != nil {
+				t.Fatal(err)
+			}
+			ctorType := reflect.TypeOf(tc.ctor)
+			if !tc.nil {
+				if gotOut != nil {
+					t.Errorf("hook called for %s", gotOut)
+				}
+				return
+			}
+			if gotOut != ctorType.Out(0) || gotFunc != ctorType {
+				t.Errorf("hook called with %v, %v; want %s, %s", gotOut, gotFunc, ctorType.Out(0), ctorType)
+			}
+		})
+	}
+}
+
+func TestWithRejectNilConstructions(t *testing.T) {
+	cleanedUp := false
+	p := New(
+		WithRejectNilConstructions(),
+		func() (*nilDB, func()) { return nil, func() { cleanedUp = true } },
+	)
+	var target struct{ DB *nilDB }
+	err := p.Inject(&target)
+	want := "getting field DB (*psyringe.nilDB) failed: invoking *psyringe.nilDB constructor (func() (*psyringe.nilDB, func())) failed: constructor returned nil *psyringe.nilDB"
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("got error %v; want it to end %q", err, want)
+	}
+	var nilErr NilConstructed
+	if !errors.As(err, &nilErr) {
+		t.Errorf("got %T; want NilConstructed", err)
+	}
+	if !cleanedUp {
+		t.Errorf("cleanup not called")
+	}
+}
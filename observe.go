@@ -0,0 +1,66 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ObserveConstructor wraps the constructor of an injection type added to this
+// Psyringe, so that observe is called with its arguments, not including any
+// leading context.Context, each time it is called. The first argument is
+// either a reflect.Type, or an example value of the injection type. It is
+// intended for test helpers which record calls to constructors, see
+// psyringetest.TestPsyringe.Spy.
+//
+// The constructor is otherwise unchanged: it is still called at most once,
+// unless transient, and any error it returns is still returned. A constructor
+// with multiple outputs is wrapped for all of them. If it has already been
+// called, it is called afresh next time its value is needed, as with Replace.
+//
+// It returns an error if the injection type was not added to this Psyringe,
+// or was added as a value. observe may be called concurrently.
+func (p *Psyringe) ObserveConstructor(example interface{}, observe func(args []interface{})) error {
+	if example == nil {
+		return fmt.Errorf("cannot observe nil")
+	}
+	t, ok := example.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(example)
+	}
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	it, ok := p.injectionTypes[t]
+	if !ok {
+		return fmt.Errorf("cannot observe %s: not added", t)
+	}
+	if it.Ctor == nil {
+		return fmt.Errorf("cannot observe %s: added as a value", t)
+	}
+	p.unshare()
+	observed := *it.Ctor.ctorFunc
+	construct := observed.construct
+	observed.construct = func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error) {
+		args := make([]interface{}, len(in))
+		for i, v := range in {
+			if v.IsValid() {
+				args[i] = v.Interface()
+			}
+		}
+		observe(args)
+		return construct(ctx, in)
+	}
+	for out, other := range p.injectionTypes {
+		if other.Ctor == nil || other.Ctor.ctorFunc != it.Ctor.ctorFunc {
+			continue
+		}
+		c := *other.Ctor
+		c.ctorFunc = &observed
+		observedIt := *other
+		observedIt.Ctor = &c
+		p.setInjectionType(out, &observedIt)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Option configures a Psyringe when passed to New or NewErr. Options must come
+// before any constructors and values. They are kept by Clone, and inherited by
+// Scope.
+type Option func(*Psyringe)
+
+// WithDebugFunc sets the func debug messages are written to, instead of the
+// file named by the PSYRINGE_DEBUG_FILE environment variable.
+func WithDebugFunc(debugf func(format string, a ...interface{})) Option {
+	return func(p *Psyringe) { p.debugFunc = debugf }
+}
+
+// WithHooks sets the Hooks called during injection. NoValueForStructField and
+// UnexportedFieldSkipped are left as they were if nil in hooks.
+func WithHooks(hooks Hooks) Option {
+	return func(p *Psyringe) {
+		if hooks.NoValueForStructField == nil {
+			hooks.NoValueForStructField = p.Hooks.NoValueForStructField
+		}
+		if hooks.UnexportedFieldSkipped == nil {
+			hooks.UnexportedFieldSkipped = p.Hooks.UnexportedFieldSkipped
+		}
+		p.Hooks = hooks
+	}
+}
+
+// newHooks returns noop hooks to avoid the need to check for nil during
+// injection.
+func newHooks() Hooks {
+	return Hooks{
+		NoValueForStructField:  func(string, reflect.StructField) error { return nil },
+		UnexportedFieldSkipped: func(string, reflect.StructField) error { return nil },
+	}
+}
+
+// WithRejectNilConstructions sets the NilValueConstructed hook to return a
+// NilConstructed error, so that a constructor returning a nil value fails,
+// rather than the nil being injected and causing a panic far from its cause.
+// Any hooks set with WithHooks must be set first, since WithHooks replaces
+// NilValueConstructed too.
+func WithRejectNilConstructions() Option {
+	return func(p *Psyringe) {
+		p.Hooks.NilValueConstructed = func(outType, funcType reflect.Type) error {
+			return NilConstructed{OutType: outType, FuncType: funcType}
+		}
+	}
+}
+
+// WithStrictInjection turns on strict mode, see Strict.
+func WithStrictInjection() Option {
+	return func(p *Psyringe) { p.strict = true }
+}
+
+// WithAddTimeCycleCheck turns checking for dependency cycles as each
+// constructor is added on or off. It is on by default, and adding a
+// constructor which closes a cycle fails with a DependencyCycle error giving
+// the full path, leaving the constructor unadded.
+//
+// Each check walks every constructor the added one depends on, so adding a
+// long chain of constructors costs time quadratic in its length; see
+// BenchmarkPsyringe_Add, where checking adds around half again to the cost of
+// adding six constructors. Turning it off makes adding cheaper, but cycles are
+// then only found by Test, or when injecting.
+func WithAddTimeCycleCheck(check bool) Option {
+	return func(p *Psyringe) { p.allowAddCycle = !check }
+}
+
+// WithAllowErrorInjectionType allows error itself to be an injection type.
+// Otherwise adding a constructor like func() error, or binding a value to
+// error, fails, since a constructor returning just an error almost always
+// means to return (T, error), and everything depending on it would silently
+// get a nil error.
+func WithAllowErrorInjectionType() Option {
+	return func(p *Psyringe) { p.allowErrorType = true }
+}
+
+// WithName names the Psyringe, as SetName does, and its root scope, in place
+// of "<root>". Errors which mention scopes then mention it even when there are
+// no child scopes, which helps tell apart errors from more than one Psyringe.
+func WithName(name string) Option {
+	return func(p *Psyringe) { p.setName(name) }
+}
+
+// newWithOptions returns a new Psyringe configured by the Options at the start
+// of args, and the remaining args. It returns an error if any Option follows a
+// constructor or value.
+func newWithOptions(args []interface{}) (*Psyringe, []interface{}, error) {
+	p := newPsyringe()
+	i := 0
+	for ; i < len(args); i++ {
+		opt, ok := args[i].(Option)
+		if !ok {
+			break
+		}
+		opt(p)
+	}
+	for j, arg := range args[i:] {
+		if _, ok := arg.(Option); ok {
+			return p, nil, fmt.Errorf("option (argument %d) must come before constructors and values", i+j)
+		}
+	}
+	return p, args[i:], nil
+}
@@ -0,0 +1,73 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNew_options(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	debug := func(format string, a ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, fmt.Sprintf(format, a...))
+	}
+	var injected Counter
+	hooks := Hooks{ValueInjected: func(string, reflect.StructField, reflect.Type) { injected.Increment() }}
+	p := New(WithDebugFunc(debug), WithHooks(hooks), WithStrictInjection(), WithName("app"), 1)
+
+	for _, q := range []*Psyringe{p, p.Clone(), p.Scope("child")} {
+		var target struct {
+			Int    int
+			String string
+		}
+		err := q.Inject(&target)
+		if err == nil || !strings.HasSuffix(err.Error(), "unable to inject field *struct { Int int; String string }.String (string)") {
+			t.Errorf("got error %v; want unfilled field String", err)
+		}
+	}
+	if got := injected.Value(); got != 3 {
+		t.Errorf("got %d ValueInjected calls; want 3", got)
+	}
+	mu.Lock()
+	if len(messages) < 2 || !strings.HasPrefix(messages[0], `psyringe "app": added value for int at `) ||
+		messages[1] != `psyringe "app": injecting into a *struct { Int int; String string }` {
+		t.Errorf("got debug messages %q", messages)
+	}
+	mu.Unlock()
+
+	var missing struct{ String string }
+	err := New(WithName("app")).Realise(&missing.String)
+	if want := `psyringe "app": realise into *string target failed: no constructor or value for string (scope app)`; err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestNew_WithAddTimeCycleCheck(t *testing.T) {
+	ctors := []interface{}{
+		func(s string) int { return 0 },
+		func(i int) string { return "" },
+	}
+	if _, err := NewErr(ctors...); err == nil {
+		t.Errorf("got nil error adding cycle")
+	}
+	p, err := NewErr(append([]interface{}{WithAddTimeCycleCheck(false)}, ctors...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Test().(TestReport); !ok {
+		t.Errorf("Test found no cycle")
+	}
+}
+
+func TestNew_optionAfterValue(t *testing.T) {
+	_, err := NewErr(1, WithStrictInjection())
+	want := "option (argument 1) must come before constructors and values"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
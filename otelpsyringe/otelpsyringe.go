@@ -0,0 +1,68 @@
+// Package otelpsyringe traces injection with OpenTelemetry, producing a span
+// for each target passed to Inject, and a child span for each constructor
+// called to inject it.
+package otelpsyringe
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/samsalisbury/psyringe"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys set on spans.
+const (
+	// TargetKey is the type of the target injected into.
+	TargetKey = attribute.Key("psyringe.target")
+	// InjectionTypeKey is the injection type a constructor was called for.
+	InjectionTypeKey = attribute.Key("psyringe.injection_type")
+	// ConstructorKey is the signature of the constructor called.
+	ConstructorKey = attribute.Key("psyringe.constructor")
+)
+
+// Install sets p's TraceInject and TraceConstructor hooks to start spans
+// using tracer. Spans named "psyringe.Inject" are started for each target,
+// and "psyringe.Construct" for each constructor, as children of the span
+// which first needed its value. Any error is recorded on the span, and sets
+// its status.
+//
+// Like other hooks, these are inherited by clones and scopes created from p
+// after Install is called. Install should be called before p is used
+// concurrently.
+func Install(p *psyringe.Psyringe, tracer trace.Tracer) {
+	p.Hooks.TraceInject = func(ctx context.Context, targetType reflect.Type) (context.Context, func(error)) {
+		ctx, span := tracer.Start(ctx, "psyringe.Inject",
+			trace.WithAttributes(TargetKey.String(typeName(targetType))))
+		return ctx, end(span)
+	}
+	p.Hooks.TraceConstructor = func(ctx context.Context, funcType, outType reflect.Type) (context.Context, func(error)) {
+		ctx, span := tracer.Start(ctx, "psyringe.Construct",
+			trace.WithAttributes(
+				InjectionTypeKey.String(outType.String()),
+				ConstructorKey.String(funcType.String()),
+			))
+		return ctx, end(span)
+	}
+}
+
+// end returns a func ending span, recording err if not nil.
+func end(span trace.Span) func(error) {
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// typeName returns the name of t, or "<nil>" if t is nil.
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
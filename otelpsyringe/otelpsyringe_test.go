@@ -0,0 +1,85 @@
+package otelpsyringe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samsalisbury/psyringe"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstall(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		C struct{}
+		D struct{}
+	)
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	p := psyringe.New(
+		func() A { return A{} },
+		func(A) B { return B{} },
+		func(A) (C, error) { return C{}, fmt.Errorf("c error") },
+		func(B, C) D { return D{} },
+	)
+	Install(p, tracer)
+	var target struct{ D D }
+	if err := p.Clone().Inject(&target); err == nil {
+		t.Fatal("got nil error")
+	}
+
+	// Inject fails as soon as C does, so B's span may still be ending.
+	spans := recorder.Ended()
+	for deadline := time.Now().Add(time.Second); len(spans) < 5 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+		spans = recorder.Ended()
+	}
+	if len(spans) != 5 {
+		t.Fatalf("got %d spans; want 5", len(spans))
+	}
+	byType := map[string]sdktrace.ReadOnlySpan{}
+	var inject sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		if s.Name() == "psyringe.Inject" {
+			inject = s
+			continue
+		}
+		for _, a := range s.Attributes() {
+			if a.Key == InjectionTypeKey {
+				byType[a.Value.AsString()] = s
+			}
+		}
+	}
+	if inject == nil {
+		t.Fatal("no inject span")
+	}
+	parent := func(s sdktrace.ReadOnlySpan) trace.SpanID { return s.Parent().SpanID() }
+	d := byType["otelpsyringe.D"]
+	if d == nil || parent(d) != inject.SpanContext().SpanID() {
+		t.Fatalf("D span not a child of inject span")
+	}
+	for _, name := range []string{"otelpsyringe.B", "otelpsyringe.C"} {
+		if s := byType[name]; s == nil || parent(s) != d.SpanContext().SpanID() {
+			t.Errorf("%s span not a child of D span", name)
+		}
+	}
+	a := byType["otelpsyringe.A"]
+	if a == nil || (parent(a) != byType["otelpsyringe.B"].SpanContext().SpanID() &&
+		parent(a) != byType["otelpsyringe.C"].SpanContext().SpanID()) {
+		t.Errorf("A span not a child of B or C span")
+	}
+	if got := byType["otelpsyringe.C"].Status().Code; got != codes.Error {
+		t.Errorf("got C span status %v; want Error", got)
+	}
+	if got := inject.Status().Code; got != codes.Error {
+		t.Errorf("got inject span status %v; want Error", got)
+	}
+	if got := byType["otelpsyringe.B"].Status().Code; got != codes.Unset {
+		t.Errorf("got B span status %v; want Unset", got)
+	}
+}
@@ -0,0 +1,66 @@
+package psyringe
+
+import (
+	"reflect"
+)
+
+// AddOverride is like AddErr, but replaces any value or constructor already
+// added for the same injection type, instead of returning an error. The value
+// of each overridden injection type, and of any constructor depending on it,
+// is constructed afresh next time it is needed.
+//
+// Overriding an injection type added to a parent scope shadows it in this
+// Psyringe only, the parent is left unchanged.
+//
+// It is still an error for two arguments to have the same injection type. If
+// any error is returned, nothing is added.
+func (p *Psyringe) AddOverride(constructorsAndValues ...interface{}) error {
+	return p.addOverride(constructorsAndValues...)
+}
+
+// addOverride just exists to make callerinfo consistent in Psyringe.add.
+func (p *Psyringe) addOverride(constructorsAndValues ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	argTypes, err := argInjectionTypes(constructorsAndValues)
+	if err != nil {
+		return err
+	}
+	rollback := p.unshare()
+	p.overriding = true
+	defer func() { p.overriding = false }()
+	overridden := map[reflect.Type]bool{}
+	reset := map[*ctorFunc]bool{}
+	for i, thing := range constructorsAndValues {
+		for _, t := range argTypes[i] {
+			scoped, ok := p.injectionTypeRegistrationScope(t)
+			if !ok {
+				continue
+			}
+			old := scoped.injectionTypes[t]
+			event := DebugEvent{Kind: DebugOverrode, Type: t, Location: old.DebugAddedLocation}
+			if old.Ctor != nil {
+				event.Constructor = old.Ctor.funcType
+			}
+			if scoped != p {
+				event.Scope = scoped.scopePath()
+				p.debug(event)
+				continue
+			}
+			p.debug(event)
+			p.setInjectionType(t, nil)
+			overridden[t] = true
+			if old.Ctor != nil {
+				reset[old.Ctor.ctorFunc] = true
+			}
+		}
+		if err := p.add(thing); err != nil {
+			rollback()
+			return err
+		}
+	}
+	p.resetDependents(overridden, reset)
+	return nil
+}
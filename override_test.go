@@ -0,0 +1,67 @@
+package psyringe
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_AddOverride(t *testing.T) {
+	var intCalls, stringCalls Counter
+	p := New(
+		func() int { return int(intCalls.Increment()) },
+		func(i int) string { stringCalls.Increment(); return strings.Repeat("a", i) },
+	)
+	var d dependent
+	p.MustInject(&d)
+
+	// Overriding int resets the string depending on it, and types not yet
+	// added are just added.
+	if err := p.AddOverride(3, bytes.NewBufferString("new")); err != nil {
+		t.Fatal(err)
+	}
+	p.MustInject(&d)
+	if d.Int != 3 || d.String != "aaa" || d.Buffer.String() != "new" {
+		t.Errorf("got %+v", d)
+	}
+	if intCalls.Value() != 1 || stringCalls.Value() != 2 {
+		t.Errorf("got %d int calls and %d string calls; want 1 and 2", intCalls.Value(), stringCalls.Value())
+	}
+}
+
+func TestPsyringe_AddOverride_scope(t *testing.T) {
+	p := New(1, "parent")
+	child := p.Scope("child")
+	if err := child.AddOverride("child"); err != nil {
+		t.Fatal(err)
+	}
+	var d dependent
+	child.MustInject(&d)
+	if d.Int != 1 || d.String != "child" {
+		t.Errorf("child: got %+v", d)
+	}
+	d = dependent{}
+	p.MustInject(&d)
+	if d.String != "parent" {
+		t.Errorf("parent: got %+v", d)
+	}
+}
+
+func TestPsyringe_AddOverride_errors(t *testing.T) {
+	p := New(1)
+	const want = "injection type int provided by arguments 0 and 1"
+	if err := p.AddOverride(2, func() int { return 3 }); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	// Nothing is added or overridden after an error.
+	err := p.AddOverride(func(string) int { return 0 }, func(int) string { return "" })
+	if !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("got error %v; want dependency cycle", err)
+	}
+	var d dependent
+	p.MustInject(&d)
+	if d.Int != 1 || d.String != "" {
+		t.Errorf("got %+v", d)
+	}
+}
@@ -0,0 +1,244 @@
+package psyringe
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// targetPlan records where the value for each field of a struct type comes
+// from, so that injecting the same type again need not look up every field's
+// injection type again.
+type targetPlan struct {
+	// key identifies the registrations the plan was made from.
+	key planKey
+	// parentName is the name of the pointer type injected into.
+	parentName string
+	fields     []fieldPlan
+}
+
+// fieldPlan records where the value for a single field comes from.
+type fieldPlan struct {
+	// parent is the pointer type injected into.
+	parent reflect.Type
+	field  reflect.StructField
+	opt    injectOption
+	// name is the field's psyringe tag, if it has one.
+	name string
+	// err is returned instead of injecting the field.
+	err error
+	// source is the injection type the field's value comes from, or nil if
+	// the field is not injected.
+	source *injectionType
+	// depth is the number of scopes above the Psyringe injecting the field
+	// that source was added to.
+	depth int
+	// adapt is how source's value is adapted to the field's type, see
+	// WithPointerAdaptation.
+	adapt adaptation
+}
+
+// targetPlans holds the plans made for each target type. It is shared by
+// clones until either is added to, since they have the same registrations.
+type targetPlans struct {
+	sync.RWMutex
+	plans map[reflect.Type]*targetPlan
+}
+
+func newTargetPlans() *targetPlans {
+	return &targetPlans{plans: map[reflect.Type]*targetPlan{}}
+}
+
+// plan returns the plan for injecting into struct type t, making it if there is
+// not an up to date one. p and its ancestors must be read-locked.
+func (p *Psyringe) plan(t reflect.Type) *targetPlan {
+	p.plans.RLock()
+	plan, ok := p.plans.plans[t]
+	p.plans.RUnlock()
+	if ok && plan.key.current(p) {
+		return plan
+	}
+	plan = p.makePlan(t)
+	plan.key = p.planKey()
+	p.plans.Lock()
+	p.plans.plans[t] = plan
+	p.plans.Unlock()
+	return plan
+}
+
+// planKey identifies the registrations of a Psyringe and its ancestors: its
+// own version, and the identity and version of each ancestor. Versions only
+// ever increase, so the key changes whenever any of them is added to. Clones
+// share plans while their versions match, but a scope re-parented under a
+// clone, see GetScope, has a different key from the scope it was cloned from.
+type planKey struct {
+	version   uint64
+	ancestors []scopeVersion
+}
+
+// scopeVersion is the version of an ancestor when a planKey was made.
+type scopeVersion struct {
+	p       *Psyringe
+	version uint64
+}
+
+// planKey returns the current planKey of p. p and its ancestors must be
+// read-locked.
+func (p *Psyringe) planKey() planKey {
+	key := planKey{version: p.version}
+	for q := p.parent; q != nil; q = q.parent {
+		key.ancestors = append(key.ancestors, scopeVersion{p: q, version: q.version})
+	}
+	return key
+}
+
+// current reports whether key is still the planKey of p, without making a new
+// one. p and its ancestors must be read-locked.
+func (key planKey) current(p *Psyringe) bool {
+	if key.version != p.version {
+		return false
+	}
+	q := p.parent
+	for _, a := range key.ancestors {
+		if q != a.p || q.version != a.version {
+			return false
+		}
+		q = q.parent
+	}
+	return q == nil
+}
+
+// invalidatePlans discards the plans made by p, and any of its descendants. p
+// must be write-locked.
+func (p *Psyringe) invalidatePlans() {
+	p.version++
+	p.plans = newTargetPlans()
+}
+
+func (p *Psyringe) makePlan(t reflect.Type) *targetPlan {
+	parent := reflect.PtrTo(t)
+	plan := &targetPlan{
+		parentName: parent.String(),
+		fields:     make([]fieldPlan, t.NumField()),
+	}
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		fp.parent = parent
+		fp.field = t.Field(i)
+		fp.opt, fp.err = parseInjectTag(plan.parentName, fp.field)
+		if fp.err != nil || fp.opt == injectNever || fp.field.PkgPath != "" {
+			continue
+		}
+		if fp.name = fp.field.Tag.Get(nameTag); fp.name != "" {
+			fp.source, fp.depth = p.findNamed(fp.name, fp.field.Type)
+			continue
+		}
+		fp.source, fp.depth = p.find(fp.field.Type)
+		if fp.source != nil {
+			continue
+		}
+		bound, err := p.boundType(fp.field.Type, p.scopeChainInjectionTypes()...)
+		if err != nil {
+			fp.err = withPathStep(err, fp.step(), "getting field %s (%s) failed", fp.field.Name, fp.field.Type)
+			continue
+		}
+		if bound != nil {
+			fp.source, fp.depth = p.find(bound)
+			continue
+		}
+		if at, how := p.adaptedType(fp.field.Type); how != notAdapted {
+			fp.source, fp.depth = p.find(at)
+			fp.adapt = how
+		}
+	}
+	return plan
+}
+
+// find returns the injection type t, and how many scopes above p it was added,
+// or nil if it was not added to p or any of its ancestors.
+func (p *Psyringe) find(t reflect.Type) (*injectionType, int) {
+	depth := 0
+	for q := p; q != nil; q = q.parent {
+		if it, ok := q.injectionTypes[t]; ok {
+			return it, depth
+		}
+		depth++
+	}
+	// Slices added to by AddToSlice only count if nothing was added for t.
+	depth = 0
+	for q := p; q != nil; q = q.parent {
+		if s, ok := q.slices[t]; ok {
+			return s.it, depth
+		}
+		depth++
+	}
+	return nil, 0
+}
+
+// findNamed is like find, for named injection types.
+func (p *Psyringe) findNamed(name string, t reflect.Type) (*injectionType, int) {
+	depth := 0
+	for q := p; q != nil; q = q.parent {
+		if it, ok := q.named[name][t]; ok {
+			return it, depth
+		}
+		depth++
+	}
+	return nil, 0
+}
+
+// ancestor returns the Psyringe depth scopes above p.
+func (p *Psyringe) ancestor(depth int) *Psyringe {
+	for ; depth > 0; depth-- {
+		p = p.parent
+	}
+	return p
+}
+
+// ready reports whether every field in plan can be injected by p without
+// calling or waiting for a constructor.
+func (plan *targetPlan) ready(p *Psyringe) bool {
+	for _, fp := range plan.fields {
+		if fp.source == nil || fp.source.Ctor == nil {
+			continue
+		}
+		if !p.ancestor(fp.depth).calls.finished(fp.source.Ctor.ctorFunc) {
+			return false
+		}
+	}
+	return true
+}
+
+// value gets the value for the field planned by fp. It returns false if the
+// field has no injection type.
+func (fp *fieldPlan) value(ctx context.Context, p *Psyringe) (reflect.Value, bool, error) {
+	if fp.source == nil {
+		if fp.name == "" {
+			if v, ok := p.implicitValue(ctx, fp.field.Type); ok {
+				return v, true, nil
+			}
+			if v, ok := p.providerValue(fp.field.Type); ok {
+				return v, true, nil
+			}
+			v, ok := p.lazyValue(fp.field.Type)
+			return v, ok, nil
+		}
+		return reflect.Value{}, false, nil
+	}
+	if fp.adapt != notAdapted {
+		return fp.adaptedValue(ctx, p)
+	}
+	if fp.source.Ctor == nil {
+		return fp.source.Value, true, nil
+	}
+	v, err := fp.source.Ctor.getValue(ctx, p.ancestor(fp.depth), ctorPath(ctx))
+	if fp.name != "" {
+		return v, true, withPathStep(err, fp.step(), "getting field %s (%s named %q) failed", fp.field.Name, fp.field.Type, fp.name)
+	}
+	return v, true, withPathStep(err, fp.step(), "getting field %s (%s) failed", fp.field.Name, fp.field.Type)
+}
+
+// step returns the PathStep for errors getting the field planned by fp.
+func (fp *fieldPlan) step() PathStep {
+	return PathStep{Parent: fp.parent, Field: fp.field.Name}
+}
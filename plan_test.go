@@ -0,0 +1,108 @@
+package psyringe
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPsyringe_Inject_lateAddInvalidatesPlan(t *testing.T) {
+	p := New(1)
+	var d dependent
+	p.MustInject(&d)
+	if d.Int != 1 || d.String != "" {
+		t.Fatalf("got %+v", d)
+	}
+
+	p.Add("hello")
+	d = dependent{}
+	p.MustInject(&d)
+	if d.Int != 1 || d.String != "hello" {
+		t.Errorf("after Add: got %+v", d)
+	}
+
+	p.AddNamed("name", "world")
+	var n struct {
+		String string `psyringe:"name"`
+	}
+	p.MustInject(&n)
+	if n.String != "world" {
+		t.Errorf("after AddNamed: got %q", n.String)
+	}
+}
+
+func TestPsyringe_Inject_parentAddInvalidatesChildPlan(t *testing.T) {
+	p := New(1)
+	child := p.Scope("child")
+	var d dependent
+	child.MustInject(&d)
+	if d.String != "" {
+		t.Fatalf("got %+v", d)
+	}
+
+	p.Add("hello")
+	child.MustInject(&d)
+	if d.Int != 1 || d.String != "hello" {
+		t.Errorf("after parent Add: got %+v", d)
+	}
+}
+
+func TestPsyringe_Inject_clonePlans(t *testing.T) {
+	p := New(1)
+	p.MustInject(&dependent{})
+	clone := p.Clone()
+	if clone.plans != p.plans {
+		t.Errorf("clone does not share plans")
+	}
+
+	// Adding to the clone does not affect the original.
+	clone.Add(bytes.NewBufferString("clone"))
+	var d dependent
+	clone.MustInject(&d)
+	if d.Buffer == nil || d.Buffer.String() != "clone" {
+		t.Errorf("clone: got %+v", d)
+	}
+	d = dependent{}
+	p.MustInject(&d)
+	if d.Int != 1 || d.Buffer != nil {
+		t.Errorf("original: got %+v", d)
+	}
+}
+
+func TestPsyringe_Inject_interfaceBindingInvalidatesPlan(t *testing.T) {
+	p := New(bytes.NewBufferString("bound"))
+	var target struct{ Stringer interface{ String() string } }
+	p.MustInject(&target)
+	if target.Stringer != nil {
+		t.Fatalf("injected %v before binding enabled", target.Stringer)
+	}
+	p.EnableInterfaceBinding()
+	p.MustInject(&target)
+	if target.Stringer == nil || target.Stringer.String() != "bound" {
+		t.Errorf("got %v; want bound", target.Stringer)
+	}
+}
+
+func TestPsyringe_Inject_planKeyParentIdentity(t *testing.T) {
+	// a and b have the same version, so a sum of versions cannot tell a plan
+	// made under one from a plan made under the other.
+	a, b := New(1), New(2)
+	child := a.Scope("child")
+	var d dependent
+	child.MustInject(&d)
+	if d.Int != 1 {
+		t.Fatalf("got %+v", d)
+	}
+	key := child.planKey()
+	if !key.current(child) {
+		t.Fatalf("new key is not current")
+	}
+
+	child.parent = b
+	if key.current(child) {
+		t.Errorf("key is current under a different parent")
+	}
+	child.MustInject(&d)
+	if d.Int != 2 {
+		t.Errorf("under b: got Int %d; want 2", d.Int)
+	}
+}
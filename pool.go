@@ -0,0 +1,83 @@
+package psyringe
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ClonePool hands out clones of a Psyringe, reusing those put back rather than
+// allocating new ones, for services cloning a Psyringe per request at high
+// rates. Use Psyringe.NewPool to create one. It is safe for concurrent use.
+type ClonePool struct {
+	p    *Psyringe
+	pool sync.Pool
+}
+
+// NewPool returns a ClonePool of clones of p.
+func (p *Psyringe) NewPool() *ClonePool {
+	return &ClonePool{p: p}
+}
+
+// Get returns a clone of the pool's Psyringe, as Clone does, either reused or
+// new.
+func (cp *ClonePool) Get() *Psyringe {
+	if q, ok := cp.pool.Get().(*Psyringe); ok {
+		return q
+	}
+	return cp.p.Clone()
+}
+
+// Put returns q, which must have come from Get, to the pool, first making it
+// as it was when Get returned it: anything added to it is forgotten, and
+// values its constructors produced are discarded without being closed, as by
+// Reset. Call Close first if they need to be closed.
+//
+// Nothing may use q, nor any value constructed by it or scope created from it,
+// once it has been put back, since it may be handed out again straight away,
+// and its values are those of a different request.
+func (cp *ClonePool) Put(q *Psyringe) {
+	cp.p.reclone(q)
+	cp.pool.Put(q)
+}
+
+// reclone makes q a fresh clone of p again, as returned by Clone, reusing its
+// lock, calls and scope registry.
+func (p *Psyringe) reclone(q *Psyringe) {
+	mu, calls, scopes := q.mu, q.calls, q.scopes
+	p.mu.RLock()
+	p.share()
+	*q = *p
+	p.mu.RUnlock()
+	q.mu = mu
+	q.frozen = 0
+	q.calls = calls
+	q.calls.resetRealised(p.calls)
+	q.scopes = scopes
+	scopes.Lock()
+	for name := range scopes.children {
+		delete(scopes.children, name)
+	}
+	scopes.clonedFrom = p.scopes
+	scopes.Unlock()
+}
+
+// resetRealised discards every call in cs, replacing them with those in from
+// which have successfully generated their values.
+func (cs *ctorCalls) resetRealised(from *ctorCalls) {
+	cs.Lock()
+	defer cs.Unlock()
+	for f := range cs.calls {
+		delete(cs.calls, f)
+	}
+	for it := range cs.addressed {
+		delete(cs.addressed, it)
+	}
+	atomic.StoreInt32(&cs.injected, 0)
+	from.RLock()
+	defer from.RUnlock()
+	for f, call := range from.calls {
+		if call.realised() {
+			cs.calls[f] = call
+		}
+	}
+}
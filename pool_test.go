@@ -0,0 +1,71 @@
+package psyringe
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type poolRequest struct{ ID int }
+
+func TestClonePool(t *testing.T) {
+	var sharedCalls, requestCalls Counter
+	p := New(
+		func() string { sharedCalls.Increment(); return "shared" },
+		func(s string) *poolRequest { return &poolRequest{ID: int(requestCalls.Increment())} },
+	)
+	var s string
+	if err := p.Realise(&s); err != nil {
+		t.Fatal(err)
+	}
+	pool := p.NewPool()
+
+	q := pool.Get()
+	var first struct{ Request *poolRequest }
+	q.MustInject(&first)
+	q.Add(1.5)
+	pool.Put(q)
+
+	q = pool.Get()
+	if q.HasType(reflect.TypeOf(1.5)) {
+		t.Errorf("clone kept what was added before Put")
+	}
+	if q.Injected() {
+		t.Errorf("clone still injected after Put")
+	}
+	var second struct{ Request *poolRequest }
+	q.MustInject(&second)
+	pool.Put(q)
+	if first.Request == second.Request {
+		t.Errorf("request value reused after Put")
+	}
+	if sharedCalls.Value() != 1 || requestCalls.Value() != 2 {
+		t.Errorf("got shared calls %d, request calls %d; want 1, 2", sharedCalls.Value(), requestCalls.Value())
+	}
+}
+
+func TestClonePool_concurrent(t *testing.T) {
+	var requestCalls Counter
+	p := New(func() *poolRequest { return &poolRequest{ID: int(requestCalls.Increment())} })
+	pool := p.NewPool()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				q := pool.Get()
+				var a, b struct{ Request *poolRequest }
+				q.MustInject(&a, &b)
+				if a.Request != b.Request {
+					t.Errorf("two values within one request")
+				}
+				pool.Put(q)
+			}
+		}()
+	}
+	wg.Wait()
+	if requestCalls.Value() != 1000 {
+		t.Errorf("got %d constructor calls; want 1000", requestCalls.Value())
+	}
+}
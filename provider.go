@@ -0,0 +1,76 @@
+package psyringe
+
+import (
+	"context"
+	"reflect"
+)
+
+// providerType returns the type T if t is func() T or func() (T, error), and
+// whether it returns an error.
+func providerType(t reflect.Type) (elem reflect.Type, returnsErr, ok bool) {
+	if t.Kind() != reflect.Func || t.NumIn() != 0 {
+		return nil, false, false
+	}
+	switch {
+	case t.NumOut() == 1:
+		return t.Out(0), false, true
+	case t.NumOut() == 2 && t.Out(1) == terror:
+		return t.Out(0), true, true
+	default:
+		return nil, false, false
+	}
+}
+
+// providesFunc reports whether p injects a provider func into fields of type
+// t, see providerValue. p and its ancestors must be read-locked.
+func (p *Psyringe) providesFunc(t reflect.Type) bool {
+	elem, _, ok := providerType(t)
+	if !ok {
+		return false
+	}
+	if p.has(elem) {
+		return true
+	}
+	bound, _ := p.boundType(elem, p.scopeChainInjectionTypes()...)
+	return bound != nil
+}
+
+// providerValue returns a func of type t, either func() T or func() (T,
+// error), which gets the value of injection type T from p each time it is
+// called, if p has that injection type. Fields of type t get it when t itself
+// is not an injection type.
+//
+// A func() T panics if getting the value fails.
+func (p *Psyringe) providerValue(t reflect.Type) (reflect.Value, bool) {
+	if !p.providesFunc(t) {
+		return reflect.Value{}, false
+	}
+	elem, returnsErr, _ := providerType(t)
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		unlock := p.rlockChain()
+		v, ok, err := p.resolve(context.Background(), elem)
+		unlock()
+		if !ok && err == nil {
+			err = NoConstructorOrValue{Type: elem, Scope: p.errScope()}
+		}
+		if err != nil {
+			v = reflect.Zero(elem)
+		} else if v.Type() != elem {
+			// Bound to an interface, see EnableInterfaceBinding.
+			converted := reflect.New(elem).Elem()
+			converted.Set(v)
+			v = converted
+		}
+		if !returnsErr {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{v}
+		}
+		errv := reflect.Zero(terror)
+		if err != nil {
+			errv = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{v, errv}
+	}), true
+}
@@ -0,0 +1,100 @@
+package psyringe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	providedTx  struct{ n int64 }
+	providedDB  struct{}
+	providedErr struct{}
+)
+
+func TestPsyringe_Inject_provider(t *testing.T) {
+	var dbCalls, txCalls Counter
+	p := New(func() *providedDB { dbCalls.Increment(); return &providedDB{} })
+	if err := p.AddTransient(func(*providedDB) *providedTx {
+		return &providedTx{txCalls.Increment()}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		DB      func() *providedDB
+		Tx      func() (*providedTx, error)
+		Unknown func() string
+	}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.DB == nil || target.Tx == nil {
+		t.Fatal("provider funcs not injected")
+	}
+	if target.Unknown != nil {
+		t.Errorf("got a provider func for a type which is not an injection type")
+	}
+	if dbCalls.Value() != 0 {
+		t.Errorf("constructor called before provider func")
+	}
+
+	if target.DB() != target.DB() {
+		t.Errorf("got different values from singleton provider func")
+	}
+	for want := int64(1); want <= 2; want++ {
+		tx, err := target.Tx()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.n != want {
+			t.Errorf("got transaction %d; want %d", tx.n, want)
+		}
+	}
+	if dbCalls.Value() != 1 {
+		t.Errorf("got %d calls to singleton constructor; want 1", dbCalls.Value())
+	}
+
+	if err := p.TestTargets(&target); err == nil || strings.Contains(err.Error(), "field DB") {
+		t.Errorf("got TestTargets error %v; want only Unknown reported", err)
+	}
+}
+
+func TestPsyringe_Inject_providerErrors(t *testing.T) {
+	p := New(func() (providedErr, error) { return providedErr{}, fmt.Errorf("failed") })
+	var target struct {
+		WithErr func() (providedErr, error)
+		Panics  func() providedErr
+	}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	const want = "invoking psyringe.providedErr constructor (func() (psyringe.providedErr, error)) failed: failed"
+	if _, err := target.WithErr(); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	defer func() {
+		r := recover()
+		if err, ok := r.(error); !ok || err.Error() != want {
+			t.Errorf("got panic %v; want %q", r, want)
+		}
+	}()
+	target.Panics()
+}
+
+func TestPsyringe_Inject_providerExactWins(t *testing.T) {
+	exact := func() *providedDB { return nil }
+	p := New(&providedDB{}, newFunc(exact))
+	var target struct{ DB func() *providedDB }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.DB() != nil {
+		t.Errorf("got provider func; want the func added")
+	}
+}
+
+// newFunc returns a constructor for f, so that f is added as a value rather
+// than as a constructor.
+func newFunc(f func() *providedDB) func() func() *providedDB {
+	return func() func() *providedDB { return f }
+}
@@ -19,15 +19,22 @@ Injection Type
 Constructors and values added to psyringe have an implicit "injection type".
 This is the type of value that constructor or value represents in the graph. For
 non-constructor values, the injection type is the type of the value itself,
-determined by reflect.GetType(). For constructors, it is the type of the first
-output (return) value. It is important to understand this concept, since a
-single psyringe can have only one value or constructor per injection type.
+determined by reflect.GetType(). For constructors, it is the type of the output
+(return) value, and constructors with multiple outputs have one injection type
+per output. It is important to understand this concept, since a single psyringe
+can have only one value or constructor per injection type.
 
 Constructors
 
 Go does not have an explicit concept of "constructor". In Psyringe, constructors
-are defined as any function that returns either a single value, or two values
-where the second is an error. They can have any number of input parameters.
+are defined as any function that returns one or more values, optionally followed
+by an error. They can have any number of input parameters. A constructor with
+multiple outputs is still called at most once, providing all of its outputs.
+A final func() output, before any error, is not an injection type but a
+cleanup func, called by Close. Likewise, a first context.Context parameter is
+not an injection type, it is passed the context given to InjectCtx. A variadic
+parameter ...T is optional: it is passed the value of injection type []T if
+there is one, or no arguments otherwise.
 
 How Injection Works
 
@@ -46,45 +53,146 @@ Thus every value in a Psyringe is effectively a singleton. The Clone method
 allows taking snapshots of a Psyringe in order to re-use its constructor graph
 whilst generating new values. It is idiomatic to use multiple Psyringes with
 differing scopes to inject different fields into the same object.
+
+Cleanup
+
+Close tears down the values a Psyringe has constructed, in reverse dependency
+order, by calling their cleanup funcs, or their Close methods if they implement
+io.Closer.
 */
 package psyringe
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"reflect"
 	"runtime"
-	"sync"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // Psyringe is a dependency injection container.
+//
+// Its methods are safe to call concurrently, with the exception that
+// constructors must not add to the Psyringe they are being called by. Setting
+// Hooks is not synchronised, so should be done before the Psyringe is shared.
+// A constructor still running after the call which started it has returned,
+// as when InjectCtx is cancelled, holds the Psyringe's read lock until it
+// returns, so methods changing the Psyringe wait for it.
 type Psyringe struct {
+	// mu guards everything added to this Psyringe, and its settings. Methods
+	// which only read hold a read lock on this Psyringe and its ancestors for
+	// their whole duration, so concurrent injections do not block each other,
+	// and may take it again, see rwMutex. Each constructor called on a new
+	// goroutine holds its own read locks until it has finished, see
+	// ctor.getValue, since the call waiting for it may not.
+	mu             *rwMutex
 	parent         *Psyringe
 	scope          string
 	injectionTypes injectionTypes
-	Hooks          Hooks
-	allowAddCycle  bool
+	named          map[string]injectionTypes
+	slices         sliceTypes
+	// shared is set to 1, atomically, once injectionTypes, named and slices
+	// may be read by another Psyringe, see unshare. It is replaced along
+	// with them.
+	shared *int32
+	// changes are the changes made to injectionTypes, named and slices while
+	// p is write-locked, see unshare.
+	changes []change
+	calls   *ctorCalls
+	// plans caches how to inject each target type, see plan.
+	plans *targetPlans
+	// version is incremented each time this Psyringe is added to.
+	version       uint64
+	Hooks         Hooks
+	allowAddCycle bool
+	// allowErrorType is set by WithAllowErrorInjectionType.
+	allowErrorType bool
+	// skipLifecycle is set by WithLifecycleMethods(false).
+	skipLifecycle bool
+	// interfaceBinding is set by EnableInterfaceBinding.
+	interfaceBinding bool
+	// adaptPointers is set by WithPointerAdaptation.
+	adaptPointers bool
+	// strict is set by Strict.
+	strict bool
+	// overriding is set while AddOverride or AddShadow is adding, allowing
+	// injection types of parent scopes to be shadowed.
+	overriding bool
+	// modules are the names of modules added, in order, see AddModule.
+	modules []string
+	// addingModule is the name of the module being added by AddModule.
+	addingModule string
+	// scopes are the child scopes created by Scope.
+	scopes *scopeRegistry
+	// debugFunc is set by WithDebugFunc.
+	debugFunc func(format string, a ...interface{})
+	// debugEventFunc is set by SetDebugEventFunc.
+	debugEventFunc func(DebugEvent)
+	// collector is set by SetCollector.
+	collector Collector
+	// contextValues is set by WithContextValues.
+	contextValues bool
+	// self is set by AddSelf.
+	self bool
+	// constructorTimeout is set by SetConstructorTimeout.
+	constructorTimeout time.Duration
+	// maxConcurrency is set by WithMaxConcurrency.
+	maxConcurrency int
+	// sequential is set by SetSequential.
+	sequential bool
+	// ttls is set by SetTTL. It is shared by clones, so is replaced rather
+	// than changed.
+	ttls map[reflect.Type]time.Duration
+	// frozen is set to 1 by Freeze, and only ever read atomically.
+	frozen int32
+	// addAfterInject is set by SetAddAfterInjectPolicy.
+	addAfterInject AddAfterInjectPolicy
+	// name is set by SetName, and prefixes errors and debug messages.
+	name string
+	// stringLimit is set by SetStringLimit.
+	stringLimit int
 }
 
 // New creates a new Psyringe, and adds the provided constructors and values to
 // it. New will panic if any two arguments have the same injection type. See
 // package level documentation for definition of "injection type".
+//
+// Any Options, like WithStrictInjection, must be passed before the
+// constructors and values.
 func New(constructorsAndValues ...interface{}) *Psyringe {
-	p := newPsyringe()
-	if err := p.addErr(constructorsAndValues...); err != nil {
+	p, constructorsAndValues, err := newWithOptions(constructorsAndValues)
+	if err == nil {
+		err = p.addErr(constructorsAndValues...)
+	}
+	if err != nil {
 		panic(err)
 	}
 	return p
 }
 
+// MustNew is New, for symmetry with MustAdd, MustInject and MustTest. It
+// panics with the same error NewErr would return, so that a recover can
+// inspect it, for example as AddErrors. It is New itself, rather than a call
+// to it, so that Describe reports where MustNew was called.
+var MustNew = New
+
 // newPsyringe is used to initialise a new Psyringe.
 func newPsyringe() *Psyringe {
 	return &Psyringe{
-		scope:          "<root>",
+		mu:             &rwMutex{},
+		scope:          rootScopeName,
 		injectionTypes: injectionTypes{},
+		named:          map[string]injectionTypes{},
+		slices:         sliceTypes{},
+		shared:         new(int32),
+		calls:          newCtorCalls(),
+		plans:          newTargetPlans(),
+		scopes:         newScopeRegistry(nil),
 		Hooks:          newHooks(),
 	}
 }
@@ -92,7 +200,10 @@ func newPsyringe() *Psyringe {
 // NewErr is similar to New, but returns an error instead of panicking. This is
 // useful if you are dynamically generating the arguments.
 func NewErr(constructorsAndValues ...interface{}) (*Psyringe, error) {
-	p := newPsyringe()
+	p, constructorsAndValues, err := newWithOptions(constructorsAndValues)
+	if err != nil {
+		return p, err
+	}
 	return p, p.addErr(constructorsAndValues...)
 }
 
@@ -113,28 +224,72 @@ func (p *Psyringe) Add(constructorsAndValues ...interface{}) {
 
 // AddErr is similar to Add, but returns an error instead of panicking. This is
 // useful if you are dynamically generating the arguments.
+//
+// Every argument is tried, so that all the problems with them are reported at
+// once: if more than one fails, AddErr returns AddErrors, naming the position
+// and type of each. If any fails, none of the arguments are added.
 func (p *Psyringe) AddErr(constructorsAndValues ...interface{}) error {
 	return p.addErr(constructorsAndValues...)
 }
 
+// MustAdd is Add, for symmetry with MustNew, MustInject and MustTest. It
+// panics with the same error AddErr would return.
+func (p *Psyringe) MustAdd(constructorsAndValues ...interface{}) {
+	if err := p.addErr(constructorsAndValues...); err != nil {
+		panic(err)
+	}
+}
+
 // addErr just exists to make callerinfo consistent in Psyringe.add.
 func (p *Psyringe) addErr(constructorsAndValues ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	// errs has each error as it is returned alone, positioned has it naming
+	// the argument, for AddErrors.
+	var errs, positioned []error
 	for i, thing := range constructorsAndValues {
 		if thing == nil {
-			return fmt.Errorf("cannot add nil (argument %d)", i)
+			err := fmt.Errorf("cannot add nil (argument %d)", i)
+			errs, positioned = append(errs, err), append(positioned, err)
+			continue
 		}
 		if err := p.add(thing); err != nil {
-			return err
+			errs = append(errs, err)
+			positioned = append(positioned, errors.Wrapf(err, "argument %d (%T)", i, thing))
 		}
 	}
-	return nil
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		rollback()
+		return errs[0]
+	default:
+		rollback()
+		return AddErrors(positioned)
+	}
 }
 
 func (p *Psyringe) add(thing interface{}) error {
+	if b, ok := thing.(Binding); ok {
+		c, v, err := b.bound()
+		if err != nil {
+			return err
+		}
+		if c != nil {
+			return errors.Wrapf(p.addCtor(c), "adding constructor %s bound to %s failed",
+				c.funcType, b.iface)
+		}
+		return errors.Wrapf(p.addValue(b.iface, v), "adding %s value bound to %s failed",
+			v.Elem().Type(), b.iface)
+	}
 	v := reflect.ValueOf(thing)
 	t := v.Type()
-	if c := newCtor(t, v); c != nil {
-		return errors.Wrapf(p.addCtor(c), "adding constructor %s failed", c.funcType)
+	if ctors := newCtors(t, v); ctors != nil {
+		return errors.Wrapf(p.addCtors(ctors), "adding constructor %s failed", t)
 	}
 	return errors.Wrapf(p.addValue(t, v), "adding %s value failed", t)
 }
@@ -145,37 +300,215 @@ func (p *Psyringe) add(thing interface{}) error {
 // values once, and then invoke them multiple times for different instances.
 // This is especially important in long-running applications where the cost of
 // calling Add or New repeatedly may get expensive.
+//
+// Values already constructed by p are carried into the clone, which uses them
+// rather than calling their constructors again. Use CloneFresh to call every
+// constructor again instead.
 func (p *Psyringe) Clone() *Psyringe {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	// The maps of injection types are shared until either Psyringe is
+	// changed, see unshare.
+	p.share()
 	q := *p
-	q.injectionTypes = p.injectionTypes.Clone()
+	q.mu = &rwMutex{}
+	q.frozen = 0
+	q.calls = p.calls.cloneRealised()
+	q.scopes = newScopeRegistry(p.scopes)
 	return &q
 }
 
+// CloneFresh is like Clone, but the clone starts with no constructed values,
+// as if p had never injected anything: each constructor is called again the
+// first time the clone needs its value, and any error a constructor returned
+// to p is forgotten. Values added directly are kept, as with Clone. Scopes
+// found from the clone by GetScope start with no constructed values either.
+// Nothing constructed by p is closed or changed.
+func (p *Psyringe) CloneFresh() *Psyringe {
+	q := p.Clone()
+	q.calls = newCtorCalls()
+	q.scopes.fresh = true
+	return q
+}
+
 // Inject takes a list of targets, which must be pointers to structs. It
 // tries to inject a value for each field in each target, if a value is known
 // for that field's type. All targets, and all fields in each target, are
 // resolved concurrently where the graph allows. In the instance that the
 // Psyringe knows no injection type for a given field's type, that field is
-// passed over, leaving it with whatever value it already had.
+// passed over, leaving it with whatever value it already had, unless in strict
+// mode (see Strict).
+//
+// Exported embedded structs, or pointers to structs, which are not injection
+// types are injected into as if their fields were the target's, allocating
+// nil pointers first. Fields of an embedded struct are injected even if
+// shadowed by the target's own.
+//
+// A field of type func() T or func() (T, error), where T is an injection type
+// but the func type is not, gets a func which gets the value of T each time it
+// is called. This defers calling T's constructor until it is needed, or
+// repeats it if it is transient, see AddTransient. A func() T panics if
+// getting the value fails.
+//
+// A target may also be a slice or array of pointers to structs, such as a
+// []*Handler, which is injected into as if each element had been passed
+// separately, concurrently with the other targets. Errors injecting into an
+// element name its index, as in "inject into target[3] (*Handler) failed".
+//
+// If injecting a single field fails, that error is returned. If more than one
+// field fails, all errors are returned together as InjectErrors, ordered by
+// target type and then field name.
 //
 // See package documentation for details on how a Psyringe injects values.
 func (p *Psyringe) Inject(targets ...interface{}) error {
-	wg := sync.WaitGroup{}
-	wg.Add(len(targets))
-	errs := make(chan error)
-	go func() {
-		wg.Wait()
-		close(errs)
-	}()
-	for _, t := range targets {
-		go func(target interface{}) {
-			defer wg.Done()
-			if err := p.inject(target); err != nil {
-				errs <- errors.Wrapf(err, "inject into %T target failed", target)
+	return p.InjectCtx(context.Background(), targets...)
+}
+
+// InjectCtx is like Inject, but passes ctx to any constructors called whose
+// first parameter is a context.Context. If ctx is done before injection
+// finishes, injecting any fields still waiting on constructors fails with
+// ctx.Err() wrapped with the injection type being constructed, and
+// constructors not yet called are not called.
+//
+// The outcome of a constructor aborted this way is memoized like any other
+// error, so it is best used with a Clone of the Psyringe made for each context.
+// A constructor already called keeps running after InjectCtx returns, and p
+// stays read-locked until it returns, so changes like Add wait for it.
+func (p *Psyringe) InjectCtx(ctx context.Context, targets ...interface{}) error {
+	unlock := p.rlockChain()
+	limited := p.limited(ctx)
+	return p.injectTargets(limited, targets, unlock, func(target interface{}) []error {
+		return p.inject(limited, target, injectMode{})
+	})
+}
+
+// InjectNonZero is like Inject, but leaves alone any field already holding a
+// non-zero value, so that targets partly filled in by hand keep the values
+// they were given. This includes non-nil pointer and interface fields. The
+// FieldAlreadySet hook is called for each field left alone.
+func (p *Psyringe) InjectNonZero(targets ...interface{}) error {
+	unlock := p.rlockChain()
+	ctx := p.limited(context.Background())
+	return p.injectTargets(ctx, targets, unlock, func(target interface{}) []error {
+		return p.inject(ctx, target, injectMode{keepSet: true})
+	})
+}
+
+// forEachTarget calls f concurrently for each target, as far as the limiter in
+// ctx allows, or directly if there is just one, and returns all the errors it
+// returns, each wrapped with a message starting with action, after p's name,
+// and naming the type of target. Errors are ordered by target type, then by position in
+// targets, so that the same problems always produce the same error. A slice
+// or array target is replaced by its elements, see expandTargets.
+func (p *Psyringe) forEachTarget(ctx context.Context, action string, targets []interface{}, f func(target interface{}) []error) error {
+	return p.forEachTargetThen(ctx, action, targets, f, nil, nil)
+}
+
+// injectTargets injects into targets with f, as forEachTarget does, and
+// then, once p's locks have been released by calling unlock, calls the
+// AfterInject method of each target injected into successfully, so that it is
+// free to use p, see afterInject.
+func (p *Psyringe) injectTargets(ctx context.Context, targets []interface{}, unlock func(), f func(target interface{}) []error) error {
+	return p.forEachTargetThen(ctx, "inject into", targets, f, unlock, (*Psyringe).afterInject)
+}
+
+// forEachTargetThen is forEachTarget, except that once f has been called for
+// every target, it calls unlock, unless it is nil, and then then, unless it is
+// nil, for each target f returned no errors for. The errors then returns are
+// wrapped and ordered as f's are. unlock is called even if f panics.
+func (p *Psyringe) forEachTargetThen(ctx context.Context, action string, targets []interface{}, f func(target interface{}) []error, unlock func(), then func(p *Psyringe, target interface{}) []error) error {
+	release := func() {
+		if unlock != nil {
+			unlock()
+			unlock = nil
+		}
+	}
+	defer release()
+	action = p.withName(action)
+	if len(targets) == 1 && !isTargetList(targets[0]) {
+		targetErrs := f(targets[0])
+		release()
+		if len(targetErrs) == 0 && then != nil {
+			targetErrs = then(p, targets[0])
+		}
+		var errs InjectErrors
+		for _, err := range targetErrs {
+			errs = append(errs, errors.Wrapf(err, "%s %T target failed", action, targets[0]))
+		}
+		return errs.errOrNil()
+	}
+	all := expandTargets(targets)
+	targetErrs := make([][]error, len(all))
+	each(ctx, len(all), func(i int) {
+		for _, err := range f(all[i].target) {
+			targetErrs[i] = append(targetErrs[i], all[i].wrap(err, action))
+		}
+	})
+	release()
+	if then != nil {
+		each(ctx, len(all), func(i int) {
+			if len(targetErrs[i]) != 0 {
+				return
 			}
-		}(t)
+			for _, err := range then(p, all[i].target) {
+				targetErrs[i] = append(targetErrs[i], all[i].wrap(err, action))
+			}
+		})
+	}
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return fmt.Sprintf("%T", all[order[i]].target) < fmt.Sprintf("%T", all[order[j]].target)
+	})
+	var errs InjectErrors
+	for _, i := range order {
+		errs = append(errs, targetErrs[i]...)
 	}
-	return <-errs
+	return errs.errOrNil()
+}
+
+// listedTarget is a target passed to forEachTarget, or an element of a slice
+// or array passed to it.
+type listedTarget struct {
+	target interface{}
+	// index is the position of target in the slice or array it came from, or
+	// -1 if it was passed directly.
+	index int
+}
+
+// wrap wraps err, from doing action to lt, with a message naming its type,
+// and index, if any.
+func (lt listedTarget) wrap(err error, action string) error {
+	if lt.index < 0 {
+		return errors.Wrapf(err, "%s %T target failed", action, lt.target)
+	}
+	return errors.Wrapf(err, "%s target[%d] (%T) failed", action, lt.index, lt.target)
+}
+
+// isTargetList reports whether target is a slice or array of targets.
+func isTargetList(target interface{}) bool {
+	t := reflect.TypeOf(target)
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array)
+}
+
+// expandTargets returns targets, with each slice or array in it replaced by
+// its elements, so that a []*Handler can be injected into like the same
+// pointers passed separately.
+func expandTargets(targets []interface{}) []listedTarget {
+	all := make([]listedTarget, 0, len(targets))
+	for _, target := range targets {
+		if !isTargetList(target) {
+			all = append(all, listedTarget{target: target, index: -1})
+			continue
+		}
+		v := reflect.ValueOf(target)
+		for i := 0; i < v.Len(); i++ {
+			all = append(all, listedTarget{target: v.Index(i).Interface(), index: i})
+		}
+	}
+	return all
 }
 
 // MustInject wraps Inject and panics if Inject returns an error.
@@ -185,29 +518,118 @@ func (p *Psyringe) MustInject(targets ...interface{}) {
 	}
 }
 
+// InjectValue is like Inject, for code which has a reflect.Value of the target
+// rather than the target itself, such as a router or codec. The value is
+// either a pointer to a struct, or an addressable struct, which is injected
+// into in place.
+func (p *Psyringe) InjectValue(v reflect.Value) error {
+	if v.Kind() == reflect.Struct {
+		if !v.CanAddr() {
+			return fmt.Errorf("inject into %s target failed: target must be addressable", v.Type())
+		}
+		v = v.Addr()
+	}
+	if !v.IsValid() {
+		return errors.Wrap(InvalidTargetError{Kind: TargetNotPointer}, "inject into invalid target failed")
+	}
+	if !v.CanInterface() {
+		return fmt.Errorf("inject into %s target failed: target was obtained from an unexported field", v.Type())
+	}
+	return p.Inject(v.Interface())
+}
+
+// Realise takes a list of targets, which must be non-nil pointers to any type.
+// It sets the value each target points to to the value of the corresponding
+// injection type, calling constructors as necessary. For example, given a *int
+// target, Realise sets the int it points to to the value of injection type int.
+// All targets are resolved concurrently where the graph allows.
+//
+// Unlike Inject, it is an error if the Psyringe knows no injection type for a
+// target, and the NoValueForStructField hook is never called.
+func (p *Psyringe) Realise(targets ...interface{}) error {
+	defer p.rlockChain()()
+	ctx := p.limited(context.Background())
+	return p.forEachTarget(ctx, "realise into", targets, func(target interface{}) []error {
+		return p.realise(ctx, target)
+	})
+}
+
+// realise sets the value target points to.
+func (p *Psyringe) realise(ctx context.Context, target interface{}) []error {
+	v := reflect.ValueOf(target)
+	if target == nil || v.Kind() != reflect.Ptr {
+		return invalidTarget(TargetNotPointer, reflect.TypeOf(target))
+	}
+	if v.IsNil() {
+		return invalidTarget(TargetNil, v.Type())
+	}
+	t := v.Type().Elem()
+	p.debug(DebugEvent{Kind: DebugRealising, Type: t})
+	val, ok, err := p.resolve(ctx, t)
+	if !ok {
+		return []error{NoConstructorOrValue{Type: t, Scope: p.errScope()}}
+	}
+	if err != nil {
+		return []error{err}
+	}
+	v.Elem().Set(val)
+	return nil
+}
+
 // Test checks that all constructors' parameters are satisfied within this
 // Psyringe, and that there are no dependency cycles.
 // This method can be used in your own tests to ensure you have a complete
 // acyclic graph. Generally it is not recommended to use Test outside of your
 // tests, as it is not built for speed.
+//
+// If there are any problems, Test returns a TestReport listing all of them, in
+// the same order each time.
 func (p *Psyringe) Test() error {
+	defer p.rlockChain()()
+	report := TestReport{Psyringe: p.name}
 	// Get sorted types - as this is a test better to have consistent output.
 	ctors := p.injectionTypes.AddedAsCtors()
 	ctorTypes := ctors.Keys()
+	tested := map[*ctorFunc]bool{}
 	for _, outType := range ctorTypes {
 		c := ctors[outType].Ctor
-		if err := c.testParametersAreRegisteredIn(p); err != nil {
-			return errors.Wrapf(err, "unable to satisfy constructor %s", c.funcType)
+		if tested[c.ctorFunc] {
+			continue
+		}
+		tested[c.ctorFunc] = true
+		for _, err := range c.testParametersAreRegisteredIn(p) {
+			report.add(c, "", err)
 		}
 	}
+	p.testNamed(&report)
+	p.testSlices(&report)
+	cycles := map[string]bool{}
+	tested = map[*ctorFunc]bool{}
 	for _, outType := range ctorTypes {
 		c := ctors[outType].Ctor
-		s := seen{}
-		if err := p.detectCycle(s, c); err != nil {
-			return errors.Wrapf(err, "dependency cycle: %s", outType)
+		if tested[c.ctorFunc] {
+			continue
+		}
+		tested[c.ctorFunc] = true
+		err := p.detectCycle(c)
+		if err == nil {
+			continue
+		}
+		// Report each cycle only once, however it is reached.
+		if key := err.(DependencyCycle).key(); !cycles[key] {
+			cycles[key] = true
+			report.add(c, "", err)
 		}
 	}
-	return nil
+	return report.errOrNil()
+}
+
+// MustTest wraps Test and panics with the TestReport if Test returns an
+// error. It is handy in TestMain, or as a sanity check at startup.
+func (p *Psyringe) MustTest() {
+	if err := p.Test(); err != nil {
+		panic(err)
+	}
 }
 
 // Scope creates a child psyringe with p as its parent. Calls to Clone on this
@@ -220,15 +642,43 @@ func (p *Psyringe) Test() error {
 // in the parent graph, p.
 //
 // Scope panics if the name is already used by this psyringe's parents, or any
-// of its parents, recursively.
+// of its parents, recursively. The child can be found again by name using
+// GetScope.
 func (p *Psyringe) Scope(name string) (child *Psyringe) {
+	if err := p.frozenErr(); err != nil {
+		panic(err)
+	}
 	if p.scopeNameInUse(name) {
 		panic(fmt.Errorf("scope %q already defined", name))
 	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	q := New()
 	q.parent = p
 	q.scope = name
 	q.Hooks = q.parent.Hooks
+	q.interfaceBinding = p.interfaceBinding
+	q.adaptPointers = p.adaptPointers
+	q.strict = p.strict
+	q.allowAddCycle = p.allowAddCycle
+	q.allowErrorType = p.allowErrorType
+	q.skipLifecycle = p.skipLifecycle
+	q.debugFunc = p.debugFunc
+	q.debugEventFunc = p.debugEventFunc
+	q.collector = p.collector
+	q.contextValues = p.contextValues
+	q.self = p.self
+	q.constructorTimeout = p.constructorTimeout
+	q.maxConcurrency = p.maxConcurrency
+	q.sequential = p.sequential
+	q.addAfterInject = p.addAfterInject
+	q.stringLimit = p.stringLimit
+	if p.name != "" {
+		q.name = p.name + "/" + name
+	}
+	p.scopes.Lock()
+	p.scopes.children[name] = q
+	p.scopes.Unlock()
 	return q
 }
 
@@ -242,22 +692,43 @@ func (s seen) clone() seen {
 	return t
 }
 
-// detectCycle returns an error if constructing rootType depends on rootType
-// transitively.
-func (p *Psyringe) detectCycle(s seen, c *ctor) error {
-	// We have now seen the injection type of c.
+// detectCycle returns a DependencyCycle error if constructing the injection
+// type of c depends on any output of c, or if any of its dependencies depends
+// on itself, transitively.
+func (p *Psyringe) detectCycle(c *ctor) error {
+	if path := p.findCycle(seen{}, c); path != nil {
+		return DependencyCycle{Path: append([]reflect.Type{c.outType}, path...), Scope: p.errScope()}
+	}
+	return nil
+}
+
+// findCycle returns the dependencies of c leading to a cycle, ending with the
+// first type seen twice, or nil if there is no cycle.
+func (p *Psyringe) findCycle(s seen, c *ctor) []reflect.Type {
+	// We have now seen the injection types of c, and any other outputs of the
+	// same constructor.
 	s = s.clone()
-	s[c.outType] = struct{}{}
+	for _, t := range c.outTypes {
+		s[t] = struct{}{}
+	}
 	for _, t := range c.inTypes {
+		if !p.injectionTypes.Contains(t) {
+			if bound, _ := p.boundType(t, p.injectionTypes); bound != nil {
+				t = bound
+			}
+		}
 		if _, ok := s[t]; ok {
-			return fmt.Errorf("depends on %s", t)
+			return []reflect.Type{t}
 		}
 		c, ok := p.injectionTypes.AddedAsCtors()[t]
+		if !ok && !p.injectionTypes.Contains(t) {
+			c, ok = p.slices.injectionTypes()[t]
+		}
 		if !ok {
 			continue
 		}
-		if err := p.detectCycle(s, c.Ctor); err != nil {
-			return errors.Wrapf(err, "depends on %s", t)
+		if path := p.findCycle(s, c.Ctor); path != nil {
+			return append([]reflect.Type{t}, path...)
 		}
 	}
 	return nil
@@ -265,88 +736,293 @@ func (p *Psyringe) detectCycle(s seen, c *ctor) error {
 
 // inject just tries to inject a value for each field in target, no errors if it
 // doesn't know how to inject a value for a given field's type, those fields are
-// just left as-is.
-func (p *Psyringe) inject(target interface{}) error {
+// just left as-is, unless mode says otherwise. Errors are returned ordered by
+// field name. The InjectStarted and InjectFinished hooks are called before and
+// after, and the Collector after.
+func (p *Psyringe) inject(ctx context.Context, target interface{}, mode injectMode) []error {
+	p.markInjected()
+	if p.contextValues {
+		ctx = NewContext(ctx, p)
+	}
+	if p.Hooks.InjectStarted == nil && p.Hooks.InjectFinished == nil && p.Hooks.TraceInject == nil && p.collector == nil {
+		return p.injectTarget(ctx, target, mode)
+	}
+	targetType := reflect.TypeOf(target)
+	if p.Hooks.InjectStarted != nil {
+		p.Hooks.InjectStarted(targetType)
+	}
+	end := func(error) {}
+	if p.Hooks.TraceInject != nil {
+		ctx, end = p.Hooks.TraceInject(ctx, targetType)
+	}
+	start := time.Now()
+	errs := p.injectTarget(ctx, target, mode)
+	duration, err := time.Since(start), InjectErrors(errs).errOrNil()
+	end(err)
+	if p.Hooks.InjectFinished != nil {
+		p.Hooks.InjectFinished(targetType, duration, err)
+	}
+	if p.collector != nil {
+		p.collector.Injected(fmt.Sprintf("%T", target), duration, err)
+	}
+	return errs
+}
+
+// injectTarget does the work of inject, without calling hooks.
+func (p *Psyringe) injectTarget(ctx context.Context, target interface{}, mode injectMode) []error {
+	if target == nil {
+		return invalidTarget(TargetNotPointer, nil)
+	}
 	v := reflect.ValueOf(target)
 	ptr := v.Type()
 	if ptr.Kind() != reflect.Ptr {
-		return fmt.Errorf("target must be a pointer")
+		return invalidTarget(TargetNotPointer, ptr)
 	}
 	t := ptr.Elem()
 	if t.Kind() != reflect.Struct {
-		return fmt.Errorf("target must be a pointer to struct")
+		return invalidTarget(TargetNotStruct, ptr)
 	}
 	if v.IsNil() {
-		return fmt.Errorf("target is nil")
-	}
-	debugf("injecting into a %s", ptr)
-	nfs := t.NumField()
-	wg := sync.WaitGroup{}
-	wg.Add(nfs)
-	errs := make(chan error)
-	go func() {
-		wg.Wait()
-		close(errs)
-	}()
-	for i := 0; i < nfs; i++ {
-		go func(f reflect.Value, field reflect.StructField) {
-			defer wg.Done()
-			if field.PkgPath != "" {
-				debugf("not injecting unexported field %s.%s (%s)", ptr, field.Name, field.Type)
-				return
-			}
-			debugf("injecting field %s.%s (%s)", ptr, field.Name, field.Type)
-			parentName := fmt.Sprintf("%T", target)
-			if fv, ok, err := p.getValueForStructField(p.Hooks, parentName, field); ok && err == nil {
-				f.Set(fv)
-			} else if err != nil {
-				errs <- err
-			}
-			// If !ok there is no value for this field type, that's OK continue.
-		}(v.Elem().Field(i), t.Field(i))
+		return invalidTarget(TargetNil, ptr)
 	}
+	p.debug(DebugEvent{Kind: DebugInjecting, Target: ptr})
+	plan := p.plan(t)
+	nfs := len(plan.fields)
+	fieldErrs := make([]error, nfs)
+	if plan.ready(p) {
+		// Nothing to wait for, so avoid the cost of a goroutine per field.
+		for i := range plan.fields {
+			fieldErrs[i] = p.injectField(ctx, plan.parentName, v.Elem().Field(i), &plan.fields[i], mode)
+		}
+		return sortedFieldErrs(t, fieldErrs)
+	}
+	each(ctx, nfs, func(i int) {
+		fieldErrs[i] = p.injectField(ctx, plan.parentName, v.Elem().Field(i), &plan.fields[i], mode)
+	})
+	return sortedFieldErrs(t, fieldErrs)
+}
 
-	return <-errs
+// injectField injects a value into the field f of a struct of type
+// parentName, as planned by fp, unless mode.keepSet is true and f is already
+// non-zero. It returns any error doing so.
+func (p *Psyringe) injectField(ctx context.Context, parentName string, f reflect.Value, fp *fieldPlan, mode injectMode) error {
+	if mode.report != nil && mode.field == nil {
+		return mode.report.injectField(ctx, p, parentName, f, fp, mode)
+	}
+	field := fp.field
+	if fp.err != nil {
+		mode.source(FieldSkipped, "")
+		return fp.err
+	}
+	if fp.opt == injectNever {
+		p.debug(DebugEvent{Kind: DebugFieldSkipped, Target: fp.parent, Field: field, Reason: `tagged inject:"-"`})
+		mode.source(FieldSkipped, `tagged inject:"-"`)
+		return nil
+	}
+	if field.PkgPath != "" {
+		p.debug(DebugEvent{Kind: DebugFieldSkipped, Target: fp.parent, Field: field, Reason: "unexported"})
+		mode.source(FieldSkipped, "unexported")
+		return p.unexportedFieldSkipped(parentName, field)
+	}
+	if field.Anonymous && fp.source == nil && fp.name == "" && deepInjectable(field.Type) && !p.providesFromContext(field.Type) {
+		// Embedded structs which are not injection types are injected into,
+		// as if their fields were the target's.
+		return p.injectNested(ctx, f, fp, mode)
+	}
+	if mode.keepSet && !f.IsZero() {
+		p.debug(DebugEvent{Kind: DebugFieldSkipped, Target: fp.parent, Field: field, Reason: "already set"})
+		mode.source(FieldSkipped, "already set")
+		if p.Hooks.FieldAlreadySet != nil {
+			p.Hooks.FieldAlreadySet(parentName, field)
+		}
+		return nil
+	}
+	p.debug(DebugEvent{Kind: DebugFieldInjecting, Target: fp.parent, Field: field})
+	fv, ok, err := fp.value(ctx, p)
+	if !ok {
+		if mode.deep && fp.name == "" && deepInjectable(field.Type) {
+			return p.injectNested(ctx, f, fp, mode)
+		}
+		// We have no value, constructor, nor parent. Give up.
+		mode.source(FieldFromHook, "")
+		if err := p.Hooks.NoValueForStructField(parentName, field); err != nil {
+			return err
+		}
+		// There is no value for this field type, that's OK unless in
+		// strict mode.
+		return p.unfilledField(parentName, field, fp.opt)
+	}
+	switch {
+	case fp.source == nil:
+		mode.source(FieldFromPsyringe, "")
+	case fp.source.Ctor == nil:
+		mode.source(FieldFromValue, "")
+	default:
+		mode.source(FieldFromConstructor, "")
+	}
+	if err != nil {
+		return err
+	}
+	if p.Hooks.FieldOverwritten != nil && !f.IsZero() {
+		if err := p.Hooks.FieldOverwritten(parentName, field, f, fv); err != nil {
+			return err
+		}
+	}
+	f.Set(fv)
+	if p.Hooks.ValueInjected != nil {
+		p.Hooks.ValueInjected(parentName, field, fv.Type())
+	}
+	return nil
 }
 
-func (p *Psyringe) getValueForStructField(leafHooks Hooks, parentTypeName string, field reflect.StructField) (reflect.Value, bool, error) {
-	t := field.Type
-	name := field.Name
-	if v, ok := p.injectionTypes.AddedAsValues()[t]; ok {
-		// We have a value, return it.
-		return v.Value, true, nil
+// sortedFieldErrs returns the non-nil errors in fieldErrs, which are indexed
+// by field of t, ordered by field name.
+func sortedFieldErrs(t reflect.Type, fieldErrs []error) []error {
+	var errs []error
+	order := make([]int, 0, len(fieldErrs))
+	for i, err := range fieldErrs {
+		if err != nil {
+			order = append(order, i)
+		}
 	}
-	if c, ok := p.injectionTypes.AddedAsCtors()[t]; ok {
-		// We have a constructor, call it.
-		v, err := c.Ctor.getValue(p)
-		return v, true, errors.Wrapf(err, "getting field %s (%s) failed", name, t)
+	sort.Slice(order, func(i, j int) bool {
+		return t.Field(order[i]).Name < t.Field(order[j]).Name
+	})
+	for _, i := range order {
+		errs = append(errs, fieldErrs[i])
 	}
-	// Look in higher scopes.
-	if p.parent != nil {
-		// We have a parent, so try to get the value from there.
-		return p.parent.getValueForStructField(leafHooks, parentTypeName, field)
+	return errs
+}
+
+// unexportedFieldSkipped calls the UnexportedFieldSkipped hook if the field
+// could otherwise have been injected.
+func (p *Psyringe) unexportedFieldSkipped(parentTypeName string, field reflect.StructField) error {
+	if p.Hooks.UnexportedFieldSkipped == nil {
+		return nil
 	}
-	// We have no value, constructor, nor parent. Give up.
-	return reflect.Value{}, false, leafHooks.NoValueForStructField(parentTypeName, field)
+	if _, registered := p.injectionTypeRegistrationScope(field.Type); !registered {
+		return nil
+	}
+	return p.Hooks.UnexportedFieldSkipped(parentTypeName, field)
 }
 
-func (p *Psyringe) getValueForConstructor(forCtor *ctor, paramIndex int, t reflect.Type) (reflect.Value, error) {
-	debugf("getting a %s for arg %d for constructor of %s", t, paramIndex, forCtor.outType)
-	if v, ok := p.injectionTypes.WithRealisedValues()[t]; ok {
-		return v.Value, nil
+func (p *Psyringe) getValueForStructField(ctx context.Context, leafHooks Hooks, parentTypeName string, field reflect.StructField) (reflect.Value, bool, error) {
+	if name := field.Tag.Get(nameTag); name != "" {
+		v, ok, err := p.resolveNamed(ctx, name, field.Type)
+		if !ok {
+			return reflect.Value{}, false, leafHooks.NoValueForStructField(parentTypeName, field)
+		}
+		return v, true, errors.Wrapf(err, "getting field %s (%s named %q) failed", field.Name, field.Type, name)
 	}
-	c, ok := p.injectionTypes.AddedAsCtors()[t]
+	v, ok, err := p.resolve(ctx, field.Type)
 	if !ok {
-		return reflect.Value{}, errors.Errorf("no constructor or value for %s", t)
+		// We have no value, constructor, nor parent. Give up.
+		return reflect.Value{}, false, leafHooks.NoValueForStructField(parentTypeName, field)
+	}
+	return v, true, errors.Wrapf(err, "getting field %s (%s) failed", field.Name, field.Type)
+}
+
+// resolve gets the value of injection type t from this Psyringe or its
+// ancestors, calling its constructor if necessary. It returns false if t is not
+// a known injection type.
+func (p *Psyringe) resolve(ctx context.Context, t reflect.Type) (reflect.Value, bool, error) {
+	v, ok, err := p.resolveExact(ctx, t)
+	if ok {
+		return v, ok, err
+	}
+	if v, ok := p.implicitValue(ctx, t); ok {
+		return v, true, nil
+	}
+	bound, err := p.boundType(t, p.scopeChainInjectionTypes()...)
+	if err != nil {
+		return reflect.Value{}, true, err
 	}
-	v, err := c.Ctor.getValue(p)
-	return v, errors.Wrapf(err, "getting argument %d failed", paramIndex)
+	if bound == nil {
+		return p.resolveAdapted(ctx, t)
+	}
+	return p.resolveExact(ctx, bound)
+}
+
+// resolveExact is like resolve, but without interface binding.
+func (p *Psyringe) resolveExact(ctx context.Context, t reflect.Type) (reflect.Value, bool, error) {
+	it, depth := p.find(t)
+	if it == nil {
+		return reflect.Value{}, false, nil
+	}
+	if it.Ctor == nil {
+		// We have a value, return it.
+		return it.Value, true, nil
+	}
+	// We have a constructor, call it in the scope it was added to.
+	v, err := it.Ctor.getValue(ctx, p.ancestor(depth), ctorPath(ctx))
+	return v, true, err
+}
+
+func (p *Psyringe) getValueForConstructor(ctx context.Context, forCtor *ctor, paramIndex int, t reflect.Type, path []*ctor) (reflect.Value, error) {
+	p.debug(DebugEvent{Kind: DebugArgumentNeeded, Type: t, Param: paramIndex, Constructor: forCtor.funcType})
+	// Parameters are satisfied by the nearest scope with the injection type,
+	// so that a child scope can shadow its parent's.
+	it, depth := p.find(t)
+	if it == nil {
+		if v, ok := p.implicitValue(ctx, t); ok {
+			return v, nil
+		}
+		bound, err := p.boundType(t, p.scopeChainInjectionTypes()...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if bound == nil {
+			if at, how := p.adaptedType(t); how != notAdapted {
+				return p.getAdaptedValueForConstructor(ctx, forCtor, paramIndex, t, at, how, path)
+			}
+			missing, _ := missingParam(NoConstructorOrValue{Type: t, Scope: p.errScope()}, forCtor, paramIndex)
+			return reflect.Value{}, missing
+		}
+		return p.getValueForConstructor(ctx, forCtor, paramIndex, bound, path)
+	}
+	if it.Ctor == nil {
+		return it.Value, nil
+	}
+	v, err := it.Ctor.getValue(ctx, p.ancestor(depth), path)
+	return v, withPathStep(err, PathStep{Constructor: forCtor.funcType, Param: paramIndex},
+		"getting argument %d failed", paramIndex)
 }
 
 func (p *Psyringe) addCtor(c *ctor) error {
 	return p.registerInjectionType(c.outType, &injectionType{Ctor: c})
 }
 
+// addCtors adds the ctors for each output of a single constructor. If any
+// output's injection type is already registered, none are added.
+//
+// Note: addCtors calls registerInjectionType directly, rather than via addCtor,
+// so that it records the correct caller location.
+func (p *Psyringe) addCtors(ctors []*ctor) error {
+	if len(ctors) == 1 {
+		return p.registerInjectionType(ctors[0].outType, &injectionType{Ctor: ctors[0]})
+	}
+	for i, c := range ctors {
+		if err := p.checkNotRegistered(c.outType); err != nil {
+			return errors.Wrapf(err, "return %d", i)
+		}
+		for _, other := range ctors[:i] {
+			if other.outType == c.outType {
+				return fmt.Errorf("return %d: injection type %s already registered by return %d",
+					i, c.outType, other.outIndex)
+			}
+		}
+	}
+	for i, c := range ctors {
+		if err := p.registerInjectionType(c.outType, &injectionType{Ctor: c}); err != nil {
+			for _, added := range ctors[:i] {
+				p.setInjectionType(added.outType, nil)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *Psyringe) addValue(t reflect.Type, v reflect.Value) error {
 	return p.registerInjectionType(t, &injectionType{Value: v})
 }
@@ -376,35 +1052,84 @@ func (p *Psyringe) scopeNameInUse(name string) bool {
 	return p.parent.scopeNameInUse(name)
 }
 
+// checkNotRegistered returns an error if t is already registered in this
+// Psyringe or its ancestors.
+func (p *Psyringe) checkNotRegistered(t reflect.Type) error {
+	scopedPsyringe, registered := p.injectionTypeRegistrationScope(t)
+	if !registered || (p.overriding && scopedPsyringe != p) {
+		return nil
+	}
+	existing := scopedPsyringe.injectionTypes[t]
+	message := fmt.Sprintf("injection type %s already registered", t)
+	if existing.Module != "" {
+		message += fmt.Sprintf(" by module %q", existing.Module)
+	}
+	if p.addingModule != "" {
+		message += fmt.Sprintf(" (now being added by module %q)", p.addingModule)
+	}
+	message = p.withName(message + " at " + existing.DebugAddedLocation)
+	if scopedPsyringe.scope == p.scope {
+		return errors.New(message)
+	}
+	return fmt.Errorf("%s (scope %s)", message, scopedPsyringe.scopePath())
+}
+
 func (p *Psyringe) registerInjectionType(t reflect.Type, it *injectionType) error {
-	if scopedPsyringe, registered := p.injectionTypeRegistrationScope(t); registered {
-		message := fmt.Sprintf("injection type %s already registered at %s",
-			t, scopedPsyringe.injectionTypes[t].DebugAddedLocation)
-		if scopedPsyringe.scope == p.scope {
-			return errors.New(message)
-		}
-		return fmt.Errorf("%s (scope %s)", message, scopedPsyringe.scope)
+	if err := p.checkNotErrorType(t); err != nil {
+		return err
+	}
+	if err := p.checkNotRegistered(t); err != nil {
+		return err
+	}
+	if err := p.checkAddAfterInject(t); err != nil {
+		return err
 	}
 	_, file, line, _ := runtime.Caller(5)
 	it.DebugAddedLocation = fmt.Sprintf("%s:%d", file, line)
-	if err := p.injectionTypes.Add(t, it); err != nil {
-		return err
+	it.Module = p.addingModule
+	if p.injectionTypes.Contains(t) {
+		return fmt.Errorf("type %s already registered", t)
 	}
+	p.setInjectionType(t, it)
+	p.debug(addedEvent(t, "", it))
 	if p.allowAddCycle || it.Ctor == nil {
 		return nil
 	}
-	return errors.Wrapf(p.detectCycle(seen{}, it.Ctor),
-		"dependency cycle: %s", it.Ctor.outType)
+	if err := p.detectCycle(it.Ctor); err != nil {
+		// Leave p as it was, so that the constructor can be fixed and added
+		// again.
+		p.setInjectionType(t, nil)
+		return err
+	}
+	return nil
+}
+
+// checkNotErrorType returns an error if t is the built-in error type, unless
+// WithAllowErrorInjectionType was used: a constructor returning just an error
+// is almost always meant to return (T, error).
+func (p *Psyringe) checkNotErrorType(t reflect.Type) error {
+	if t != terror || p.allowErrorType {
+		return nil
+	}
+	return fmt.Errorf("refusing to register injection type error; did you mean to return (T, error)?")
 }
 
 func (p *Psyringe) testValueOrConstructorIsRegistered(paramType reflect.Type) error {
-	if p.injectionTypes.Contains(paramType) {
+	if _, ok := p.injectionTypeRegistrationScope(paramType); ok || p.providesFromContext(paramType) || p.providesSelf(paramType) || p.hasSlice(paramType) {
+		return nil
+	}
+	if bound, err := p.boundType(paramType, p.scopeChainInjectionTypes()...); bound != nil || err != nil {
+		return err
+	}
+	if _, how := p.adaptedType(paramType); how != notAdapted {
 		return nil
 	}
-	return errors.Errorf("no constructor or value for %s", paramType)
+	return NoConstructorOrValue{Type: paramType, Scope: p.errScope()}
 }
 
-var debugf = func(string, ...interface{}) {}
+// debugf is set to write debug messages to the file named by
+// PSYRINGE_DEBUG_FILE, or is nil if that is not set, see Psyringe.debug.
+var debugf func(format string, a ...interface{})
 
 const debugFileKey = "PSYRINGE_DEBUG_FILE"
 
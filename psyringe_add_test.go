@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 type named string
@@ -15,20 +19,21 @@ func TestPsyringe_Add_success(t *testing.T) {
 
 		// objects
 		//
-		"a plain string",                      // string
-		int(256),                              // int
-		float64(256),                          // float64
-		float32(256),                          // float32
-		named("string"),                       // named
-		&bytes.Buffer{},                       // *bytes.Buffer{}
-		func() (int, int) { return 1, 2 },     // func() (int, int)
-		func() (error, int) { return nil, 1 }, // func() (error, int)
+		"a plain string", // string
+		int(256),         // int
+		float64(256),     // float64
+		float32(256),     // float32
+		named("string"),  // named
+		&bytes.Buffer{},  // *bytes.Buffer{}
 
 		// constructors
 		//
-		func() io.Reader { return nil },               // io.Reader
-		func() (io.Writer, error) { return nil, nil }, // io.Writer
-		func() error { return nil },                   // error
+		func() io.Reader { return nil },                     // io.Reader
+		func() (io.Writer, error) { return nil, nil },       // io.Writer
+		func() (uint, uint8) { return 1, 2 },                // uint, uint8
+		func() (uint16, uint32, error) { return 1, 2, nil }, // uint16, uint32
+		func() (io.ReadCloser, int8) { return nil, 1 },      // io.ReadCloser, int8
+		func() func() (int, int) { return nil },             // func() (int, int)
 	)
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
@@ -57,13 +62,12 @@ func TestPsyringe_Add_alreadyRegisteredError(t *testing.T) {
 	e(1, 2, "int")
 	e(func() {}, func() {}, "func()")
 	e(struct{}{}, struct{}{}, "struct {}")
-	// Note: func() (string, string) is not a constructor type, thus its
-	// injection type is itself. (Constructors either have one return value, or
-	// one plus an error.
+	// Note: func() (string, string) is a constructor with two outputs of the
+	// same injection type, so it collides with itself.
 	e(
 		func() (string, string) { return "", "" },
 		func() (string, string) { return "hello", "world" },
-		"func() (string, string)",
+		"string",
 	)
 	// This one illustrates that for non constructors,
 	// only the value of the item counts, not any particular
@@ -138,3 +142,312 @@ func TestPsyringe_Add_cycle(t *testing.T) {
 		t.Errorf("got error %q; want %q", got, want)
 	}
 }
+
+func TestPsyringe_Add_multipleOutputsAlreadyRegistered(t *testing.T) {
+	p := New("a string")
+	err := p.AddErr(func() (int, string, error) { return 1, "", nil })
+	wantPattern := regexp.MustCompile(`^adding constructor func\(\) \(int, string, error\) failed: return 1: injection type string already registered at .*/psyringe_add_test.go:\d+$`)
+	if err == nil {
+		t.Fatalf("got nil error; want match %q", wantPattern)
+	}
+	if !wantPattern.MatchString(err.Error()) {
+		t.Errorf("got error %q; want match %q", err, wantPattern)
+	}
+	// None of the outputs should have been registered.
+	if p.injectionTypes.Contains(reflect.TypeOf(1)) {
+		t.Errorf("int registered despite failure adding string")
+	}
+}
+
+func TestPsyringe_Add_errorInjectionType(t *testing.T) {
+	p := New()
+	err := p.AddErr(func() error { return nil })
+	want := "adding constructor func() error failed: refusing to register injection type error; did you mean to return (T, error)?"
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+	if p.HasType(terror) {
+		t.Errorf("error registered despite failure")
+	}
+	if err := p.AddNamedErr("failure", func() error { return nil }); err == nil {
+		t.Errorf("got nil error adding named error")
+	}
+
+	p = New(WithAllowErrorInjectionType())
+	if err := p.AddErr(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Scope("child").HasType(terror) {
+		t.Errorf("error not registered")
+	}
+}
+
+func TestPsyringe_Add_multipleOutputsCycle(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+	)
+	err := New().AddErr(func(B) (A, B) { return nil, nil })
+	want := "adding constructor func(psyringe.B) (psyringe.A, psyringe.B) failed: dependency cycle: psyringe.A: depends on psyringe.B"
+	if err == nil {
+		t.Fatalf("got nil error; want %q", want)
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+}
+
+func TestPsyringe_Add_cycleCompletedByLast(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+		C *struct{}
+	)
+	p := New(func(B) A { return nil }, func(C) B { return nil })
+	err := p.AddErr(func(A) C { return nil })
+	dc, ok := errors.Cause(err).(DependencyCycle)
+	if !ok {
+		t.Fatalf("got error %v; want DependencyCycle", err)
+	}
+	want := []reflect.Type{reflect.TypeOf(C(nil)), reflect.TypeOf(A(nil)), reflect.TypeOf(B(nil)), reflect.TypeOf(C(nil))}
+	if !reflect.DeepEqual(dc.Path, want) {
+		t.Errorf("got path %v; want %v", dc.Path, want)
+	}
+	// The constructor closing the cycle is not added.
+	if p.Has(C(nil)) {
+		t.Errorf("C added despite cycle")
+	}
+	if err := p.Test(); err == nil || !strings.Contains(err.Error(), "no constructor or value for psyringe.C") {
+		t.Errorf("got Test error %v; want missing C", err)
+	}
+}
+
+func TestPsyringe_Add_cycleCheckOff(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+		C *struct{}
+	)
+	p := New(WithAddTimeCycleCheck(false), func(B) A { return nil }, func(C) B { return nil })
+	if err := p.AddErr(func(A) C { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Test()
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: psyringe.A: depends on psyringe.B: depends on psyringe.C: depends on psyringe.A") {
+		t.Errorf("got Test error %v; want cycle", err)
+	}
+}
+
+func TestPsyringe_AddErr_allErrors(t *testing.T) {
+	type A *struct{}
+	p := New("a string")
+	err := p.AddErr(
+		1,
+		nil,
+		"another string",
+		func() (*bytes.Buffer, error) { return nil, nil },
+		func(A) A { return nil },
+	)
+	addErrs, ok := err.(AddErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want AddErrors", err, err)
+	}
+	wantPatterns := []string{
+		`^cannot add nil \(argument 1\)$`,
+		`^argument 2 \(string\): adding string value failed: injection type string already registered at .*/psyringe_add_test.go:\d+$`,
+		`^argument 4 \(func\(psyringe.A\) psyringe.A\): adding constructor func\(psyringe.A\) psyringe.A failed: dependency cycle: `,
+	}
+	if len(addErrs) != len(wantPatterns) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(addErrs), len(wantPatterns), err)
+	}
+	for i, pattern := range wantPatterns {
+		if !regexp.MustCompile(pattern).MatchString(addErrs[i].Error()) {
+			t.Errorf("got error %d %q; want match %q", i, addErrs[i], pattern)
+		}
+	}
+	if got, want := err.Error(), addErrs[0].Error()+"\n"+addErrs[1].Error()+"\n"+addErrs[2].Error(); got != want {
+		t.Errorf("got message %q; want %q", got, want)
+	}
+}
+
+func TestPsyringe_AddErr_atomic(t *testing.T) {
+	p := New("a string")
+	err := p.AddErr(1, func() *bytes.Buffer { return nil }, "another string", 2.5)
+	if err == nil {
+		t.Fatal("got nil error; want already registered")
+	}
+	for _, example := range []interface{}{1, (*bytes.Buffer)(nil), 2.5} {
+		if p.Has(example) {
+			t.Errorf("%T added despite failure", example)
+		}
+	}
+	if !p.Has("") {
+		t.Errorf("string added before the failed call removed")
+	}
+	// The arguments can all be added once the problem is fixed.
+	if err := p.AddErr(1, func() *bytes.Buffer { return nil }, 2.5); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNew_allErrors(t *testing.T) {
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok {
+			t.Fatalf("got panic %v; want AddErrors", r)
+		}
+		if _, ok := err.(AddErrors); !ok {
+			t.Errorf("got panic %v (%T); want AddErrors", err, err)
+		}
+		msg := err.Error()
+		if !strings.Contains(msg, "cannot add nil (argument 1)") || !strings.Contains(msg, "argument 2 (int)") {
+			t.Errorf("panic message does not list every error:\n%s", msg)
+		}
+	}()
+	New(1, nil, 2)
+}
+
+func TestPsyringe_AddErr_atomicTest(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+	)
+	p := New(func(string) A { return A{} })
+	before := fmt.Sprint(p.Test())
+	// B's constructor would satisfy nothing, and A collides.
+	err := p.AddErr(func() B { return B{} }, "a string", func() A { return A{} })
+	if err == nil {
+		t.Fatal("got nil error; want already registered")
+	}
+	for _, example := range []interface{}{B{}, ""} {
+		if p.Has(example) {
+			t.Errorf("%T added despite failure", example)
+		}
+	}
+	if after := fmt.Sprint(p.Test()); after != before {
+		t.Errorf("got Test error %q after failed AddErr; want %q as before", after, before)
+	}
+}
+
+func TestPsyringe_add_atomic(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		S struct{ Int int }
+	)
+	cases := map[string]func(p *Psyringe) error{
+		"AddTransient": func(p *Psyringe) error {
+			return p.AddTransient(func() A { return A{} }, func() string { return "" })
+		},
+		"AddStruct": func(p *Psyringe) error {
+			return p.AddStruct(S{}, nil)
+		},
+		"AddToSlice": func(p *Psyringe) error {
+			return p.AddToSlice(A{}, nil)
+		},
+		"AddShadow": func(p *Psyringe) error {
+			return p.AddShadow(A{}, "one", "two")
+		},
+		"AddIfMissing": func(p *Psyringe) error {
+			_, err := p.AddIfMissing(A{}, func(B) B { return B{} })
+			return err
+		},
+		"AddModule": func(p *Psyringe) error {
+			return p.AddModule(Module{Name: "m", Provides: []interface{}{A{}, "again"}})
+		},
+	}
+	for name, add := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New("a string")
+			if err := add(p); err == nil {
+				t.Fatal("got nil error; want failure")
+			}
+			for _, example := range []interface{}{A{}, B{}, S{}, []A{}} {
+				if p.Has(example) {
+					t.Errorf("%T added despite failure", example)
+				}
+			}
+			if err := p.Test(); err != nil {
+				t.Errorf("got Test error %v; want nil as before", err)
+			}
+		})
+	}
+}
+
+func TestMustNew(t *testing.T) {
+	p := MustNew(1, "a string")
+	if !p.Has(1) || !p.Has("") {
+		t.Errorf("MustNew did not add its arguments")
+	}
+	if r, _ := p.Describe(1); !strings.Contains(r.Location, "psyringe_add_test.go:") {
+		t.Errorf("got location %q; want this file", r.Location)
+	}
+
+	defer func() {
+		r := recover()
+		if _, ok := r.(AddErrors); !ok {
+			t.Errorf("got panic %v (%T); want AddErrors", r, r)
+		}
+	}()
+	MustNew(nil, 1, 2)
+}
+
+func TestPsyringe_MustAdd(t *testing.T) {
+	p := MustNew()
+	p.MustAdd(1)
+	defer func() {
+		err, ok := recover().(error)
+		if !ok {
+			t.Fatal("MustAdd did not panic with an error")
+		}
+		wantPattern := regexp.MustCompile(`^adding int value failed: injection type int already registered at .*/psyringe_add_test.go:\d+$`)
+		if !wantPattern.MatchString(err.Error()) {
+			t.Errorf("got error %q; want match %q", err, wantPattern)
+		}
+	}()
+	p.MustAdd(2)
+}
+
+// TestPsyringe_AddErr_copyOnlyShared checks that adding copies the maps of
+// injection types only while a clone shares them, and that a failed AddErr
+// leaves p as it was either way.
+func TestPsyringe_AddErr_copyOnlyShared(t *testing.T) {
+	mapOf := func(p *Psyringe) uintptr { return reflect.ValueOf(p.injectionTypes).Pointer() }
+	p := New(1)
+	before := mapOf(p)
+	p.Add("a string")
+	if mapOf(p) != before {
+		t.Error("Add copied injection types not shared with a clone")
+	}
+	if err := p.AddErr(int8(1), "another string"); err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	if p.Has(int8(1)) {
+		t.Error("failed AddErr added int8")
+	}
+
+	clone := p.Clone()
+	p.Add(1.0)
+	if mapOf(p) == before {
+		t.Error("Add did not copy injection types shared with a clone")
+	}
+	if clone.Has(1.0) {
+		t.Error("adding to p added to its clone")
+	}
+	copied := mapOf(p)
+	clone.Add(int16(1))
+	if err := clone.AddErr(int8(1), 2); err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	if clone.Has(int8(1)) || !clone.Has(int16(1)) || p.Has(int16(1)) {
+		t.Error("failed AddErr to clone did not leave it as it was")
+	}
+	p.Add(int32(1))
+	if mapOf(p) != copied {
+		t.Error("Add copied injection types no longer shared")
+	}
+}
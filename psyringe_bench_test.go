@@ -28,16 +28,14 @@ func BenchmarkPsyringe_Add(b *testing.B) {
 
 	b.Run("with detectCycle", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			p := New()
-			p.allowAddCycle = false
+			p := New(WithAddTimeCycleCheck(true))
 			exercise(p)
 		}
 	})
 
 	b.Run("without detectCycle", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			p := New()
-			p.allowAddCycle = true
+			p := New(WithAddTimeCycleCheck(false))
 			exercise(p)
 		}
 	})
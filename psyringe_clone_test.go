@@ -1,6 +1,7 @@
 package psyringe
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 )
@@ -155,3 +156,98 @@ func TestPsyringe_Clone_identity(t *testing.T) {
 	}
 
 }
+
+func TestPsyringe_CloneFresh_identity(t *testing.T) {
+	var target struct {
+		StringPtr *string
+		Int       int
+	}
+	calls := 0
+	var ctor = func() *string { calls++; s := "A String."; return &s }
+
+	p := New(ctor, 7)
+	p.MustInject(&target)
+	sp := target.StringPtr
+
+	fresh := p.CloneFresh()
+	fresh.MustInject(&target)
+	spFresh := target.StringPtr
+	if calls != 2 {
+		t.Errorf("got %d constructor calls; want 2", calls)
+	}
+	if spFresh == sp {
+		t.Errorf("fresh clone injected the original's value")
+	}
+	if target.Int != 7 {
+		t.Errorf("got Int %d; want 7", target.Int)
+	}
+
+	fresh.MustInject(&target)
+	if target.StringPtr != spFresh {
+		t.Errorf("fresh clone is not stable, ctor called twice")
+	}
+
+	// The original's value is untouched.
+	p.MustInject(&target)
+	if target.StringPtr != sp {
+		t.Errorf("original's value changed by fresh clone")
+	}
+	// A plain clone of the original still carries its value.
+	p.Clone().MustInject(&target)
+	if target.StringPtr != sp {
+		t.Errorf("Clone after CloneFresh did not clone the value")
+	}
+	if calls != 2 {
+		t.Errorf("got %d constructor calls; want 2", calls)
+	}
+}
+
+func TestPsyringe_CloneFresh_error(t *testing.T) {
+	fail := true
+	p := New(func() (*string, error) {
+		if fail {
+			return nil, fmt.Errorf("failed")
+		}
+		s := "ok"
+		return &s, nil
+	})
+	var target struct{ StringPtr *string }
+	if err := p.Inject(&target); err == nil {
+		t.Fatal("got nil error; want constructor error")
+	}
+	fail = false
+	if err := p.CloneFresh().Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.StringPtr == nil || *target.StringPtr != "ok" {
+		t.Errorf("got %v; want pointer to ok", target.StringPtr)
+	}
+	if err := p.Inject(&target); err == nil {
+		t.Errorf("got nil error from original; want memoized constructor error")
+	}
+}
+
+func TestPsyringe_CloneFresh_scope(t *testing.T) {
+	calls := 0
+	root := New()
+	child := root.Scope("request")
+	child.Add(func() *string { calls++; s := "A String."; return &s })
+	var target struct{ StringPtr *string }
+	child.MustInject(&target)
+	sp := target.StringPtr
+
+	scope, ok := root.CloneFresh().GetScope("request")
+	if !ok {
+		t.Fatal("scope not found")
+	}
+	scope.MustInject(&target)
+	if calls != 2 || target.StringPtr == sp {
+		t.Errorf("scope of fresh clone reused the original's value")
+	}
+
+	scope, _ = root.Clone().GetScope("request")
+	scope.MustInject(&target)
+	if calls != 2 || target.StringPtr != sp {
+		t.Errorf("scope of clone did not reuse the original's value")
+	}
+}
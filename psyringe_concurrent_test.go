@@ -0,0 +1,210 @@
+package psyringe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPsyringe_concurrentAddInject checks that adding to a Psyringe while other
+// goroutines inject from it, its clones, and its child scopes, is free from
+// data races. Run with -race.
+func TestPsyringe_concurrentAddInject(t *testing.T) {
+	p := New(func() int { return 1 })
+	child := p.Scope("child")
+	child.Add(func() string { return "1" })
+
+	const n = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, 6*n)
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		// Each iteration adds a value of a new injection type.
+		i, v := i, reflect.New(reflect.ArrayOf(i+1, reflect.TypeOf(byte(0)))).Elem().Interface()
+		run(func() error { return p.AddErr(v) })
+		run(func() error { return p.AddNamedErr(fmt.Sprint(i), v) })
+		run(func() error {
+			target := &struct{ Int int }{}
+			if err := p.Inject(target); err != nil {
+				return err
+			}
+			if target.Int != 1 {
+				return fmt.Errorf("got Int %d; want 1", target.Int)
+			}
+			return nil
+		})
+		run(func() error {
+			target := &struct{ String string }{}
+			if err := child.Inject(target); err != nil {
+				return err
+			}
+			if target.String != "1" {
+				return fmt.Errorf("got String %q; want %q", target.String, "1")
+			}
+			return nil
+		})
+		run(func() error { return p.Clone().Inject(&struct{ Int int }{}) })
+		run(func() error { return p.Test() })
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := len(p.Graph().Nodes()); got != n+1 {
+		t.Errorf("got %d injection types; want %d", got, n+1)
+	}
+}
+
+// TestPsyringe_concurrentAddNestedRead checks that a constructor can get values
+// from the Psyringe calling it, which takes its read lock again, while Add is
+// waiting for the write lock.
+func TestPsyringe_concurrentAddNestedRead(t *testing.T) {
+	type holder struct {
+		Provider func() *bytes.Buffer
+		Lazy     Lazy[*bytes.Buffer]
+	}
+	cases := map[string]func(p *Psyringe, h holder) error{
+		"Get": func(p *Psyringe, _ holder) error {
+			_, err := Get[*bytes.Buffer](p)
+			return err
+		},
+		"provider": func(_ *Psyringe, h holder) error {
+			h.Provider()
+			return nil
+		},
+		"Lazy": func(_ *Psyringe, h holder) error {
+			_, err := h.Lazy.Get()
+			return err
+		},
+	}
+	for name, get := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(func() *bytes.Buffer { return &bytes.Buffer{} })
+			var h holder
+			p.MustInject(&h)
+			started := make(chan struct{})
+			p.Add(func() (string, error) {
+				close(started)
+				// Give Add time to start waiting.
+				time.Sleep(20 * time.Millisecond)
+				return "", get(p, h)
+			})
+			injected := make(chan error, 1)
+			go func() { injected <- p.Inject(&struct{ String string }{}) }()
+			<-started
+			added := make(chan error, 1)
+			go func() { added <- p.AddErr(1) }()
+			for _, c := range []chan error{injected, added} {
+				select {
+				case err := <-c:
+					if err != nil {
+						t.Error(err)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("deadlocked")
+				}
+			}
+		})
+	}
+}
+
+// TestPsyringe_concurrentChangeCancelled checks that changing a Psyringe
+// while a constructor started by a cancelled InjectCtx is still running is
+// free from data races, by waiting for the constructor. Run with -race.
+func TestPsyringe_concurrentChangeCancelled(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := New(func() *bytes.Buffer {
+		close(started)
+		<-release
+		return &bytes.Buffer{}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	var target struct{ Buffer *bytes.Buffer }
+	if err := p.InjectCtx(ctx, &target); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v; want %v", err, context.Canceled)
+	}
+	changes := []func() error{
+		func() error { return p.AddErr(1) },
+		func() error { p.SetName("changed"); return nil },
+		func() error { p.SetCollector(&testCollector{}); return nil },
+		func() error { p.SetConstructorTimeout(time.Second); return nil },
+	}
+	errs := make(chan error, len(changes))
+	for _, change := range changes {
+		change := change
+		go func() { errs <- change() }()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for range changes {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("deadlocked")
+		}
+	}
+}
+
+type (
+	cycleA *struct{}
+	cycleB *struct{}
+	cycleC *struct{}
+)
+
+// TestPsyringe_Inject_concurrentCycle checks that a dependency cycle entered
+// from several targets at once, so that each constructor in it is called on a
+// different goroutine, is reported rather than waiting forever.
+func TestPsyringe_Inject_concurrentCycle(t *testing.T) {
+	const n = 2000
+	done := make(chan error)
+	go func() {
+		for i := 0; i < n; i++ {
+			p := New(WithAddTimeCycleCheck(false),
+				func(cycleB) cycleA { return nil },
+				func(cycleC) cycleB { return nil },
+				func(cycleA) cycleC { return nil },
+			)
+			var (
+				a struct{ A cycleA }
+				b struct{ B cycleB }
+				c struct{ C cycleC }
+			)
+			err := p.Inject(&a, &b, &c)
+			if !errors.Is(err, DependencyCycle{}) {
+				done <- fmt.Errorf("iteration %d: got error %v; want dependency cycle", i, err)
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("injecting a cycle from several targets deadlocked")
+	}
+}
@@ -1,8 +1,14 @@
 package psyringe
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 type TestFormatter struct{}
@@ -32,3 +38,135 @@ func TestInjectErrors(t *testing.T) {
 	//tf := &TestFormatter{}
 	//t.Logf("%#s", tf)
 }
+
+func TestPsyringe_Inject_allErrors(t *testing.T) {
+	p := New(
+		func() (int, error) { return 0, fmt.Errorf("int error") },
+		func() (string, error) { return "", fmt.Errorf("string error") },
+	)
+	type B struct {
+		String string
+		Int    int
+	}
+	type A struct {
+		Int int
+	}
+
+	before := runtime.NumGoroutine()
+	err := p.Inject(&B{}, &A{})
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got a %T; want InjectErrors", err)
+	}
+	want := []string{
+		"inject into *psyringe.A target failed: getting field Int (int) failed: invoking int constructor (func() (int, error)) failed: int error",
+		"inject into *psyringe.B target failed: getting field Int (int) failed: invoking int constructor (func() (int, error)) failed: int error",
+		"inject into *psyringe.B target failed: getting field String (string) failed: invoking string constructor (func() (string, error)) failed: string error",
+	}
+	if len(ie.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ie.Errors()), len(want), err)
+	}
+	for i, err := range ie.Errors() {
+		if got := err.Error(); got != want[i] {
+			t.Errorf("error %d: got %q; want %q", i, got, want[i])
+		}
+	}
+	if got, want := err.Error(), strings.Join(want, "\n"); got != want {
+		t.Errorf("got error %q; want %q", got, want)
+	}
+
+	// Inject must not leave goroutines behind, give them a moment to exit.
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before Inject; %d after", before, after)
+	}
+}
+
+// TestPsyringe_Inject_deterministic_output ensures that Inject always returns
+// the same error for a given Psyringe, like Test does, even though arguments
+// are got concurrently.
+func TestPsyringe_Inject_deterministic_output(t *testing.T) {
+	// Each test case is run 1000 times try to catch nondeterministic output.
+	testCases := []struct {
+		desc string
+		// ctors are shuffled before each test
+		ctors   []interface{}
+		wantErr string
+	}{
+		{
+			desc: "missing params lowest index",
+			ctors: []interface{}{
+				func(int, string) byte { return 0 },
+			},
+			wantErr: "inject into *struct { Byte uint8 } target failed: getting field Byte (uint8) failed: " +
+				"unable to satisfy constructor func(int, string) uint8: unable to satisfy param 0: no constructor or value for int",
+		},
+		{
+			desc: "failed params lowest index",
+			ctors: []interface{}{
+				func(int, string) byte { return 0 },
+				func() (string, error) { return "", fmt.Errorf("string error") },
+				func() (int, error) { return 0, fmt.Errorf("int error") },
+			},
+			wantErr: "inject into *struct { Byte uint8 } target failed: getting field Byte (uint8) failed: " +
+				"invoking uint8 constructor (func(int, string) uint8) failed: getting argument 0 failed: " +
+				"invoking int constructor (func() (int, error)) failed: int error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				for i := range tc.ctors {
+					j := rand.Intn(i + 1)
+					tc.ctors[i], tc.ctors[j] = tc.ctors[j], tc.ctors[i]
+				}
+				var target struct{ Byte byte }
+				err := New(tc.ctors...).Inject(&target)
+				if err == nil {
+					t.Fatalf("iter %d: got nil err; want %q", i, tc.wantErr)
+				}
+				if got := err.Error(); got != tc.wantErr {
+					t.Fatalf("iter %d: got error %q; want %q", i, got, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+var errPanicked = fmt.Errorf("panicked")
+
+func TestInjectErrors_constructorPanic(t *testing.T) {
+	p := New(
+		func() (int, error) { panic(errPanicked) },
+		func(int) string { return "a" },
+		func(string) byte { return 1 },
+	)
+	var target struct{ Byte byte }
+	err := p.Inject(&target)
+	const want = "inject into *struct { Byte uint8 } target failed: getting field Byte (uint8) failed: " +
+		"invoking uint8 constructor (func(string) uint8) failed: getting argument 0 failed: " +
+		"invoking string constructor (func(int) string) failed: getting argument 0 failed: " +
+		"int constructor (func() (int, error)) panicked: panicked"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+	var panicked ConstructorPanic
+	if !errors.As(err, &panicked) {
+		t.Fatalf("errors.As found no ConstructorPanic in %q", err)
+	}
+	if panicked.OutType != reflect.TypeOf(0) || panicked.Recovered != errPanicked {
+		t.Errorf("got panic of %v constructor with %v", panicked.OutType, panicked.Recovered)
+	}
+	if !strings.Contains(string(panicked.Stack), "psyringe_inject_errors_test.go") {
+		t.Errorf("stack does not include the panicking constructor:\n%s", panicked.Stack)
+	}
+	if !errors.Is(err, errPanicked) {
+		t.Errorf("errors.Is(err, errPanicked) = false; want true")
+	}
+}
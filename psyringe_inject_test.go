@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -115,6 +116,134 @@ func TestPsyringe_Inject_uninjectable(t *testing.T) {
 	}
 }
 
+func TestPsyringe_Inject_invalidTargetError(t *testing.T) {
+	cases := []struct {
+		target interface{}
+		kind   InvalidTargetKind
+		typ    reflect.Type
+	}{
+		{1, TargetNotPointer, reflect.TypeOf(1)},
+		{new(int), TargetNotStruct, reflect.TypeOf(new(int))},
+		{(*struct{})(nil), TargetNil, reflect.TypeOf((*struct{})(nil))},
+	}
+	for _, c := range cases {
+		err := New().Inject(c.target)
+		var ite InvalidTargetError
+		if !errors.As(err, &ite) {
+			t.Errorf("got error %v (%T); want an InvalidTargetError", err, err)
+			continue
+		}
+		if ite.Kind != c.kind || ite.Type != c.typ {
+			t.Errorf("got kind %d, type %v; want %d, %v", ite.Kind, ite.Type, c.kind, c.typ)
+		}
+	}
+}
+
+func TestPsyringe_Inject_nilTarget(t *testing.T) {
+	cases := map[string]func(p *Psyringe) error{
+		"Inject":                func(p *Psyringe) error { return p.Inject(nil) },
+		"Inject list":           func(p *Psyringe) error { return p.Inject(nil, &struct{}{}) },
+		"InjectNonZero":         func(p *Psyringe) error { return p.InjectNonZero(nil) },
+		"InjectNonZero list":    func(p *Psyringe) error { return p.InjectNonZero(&struct{}{}, nil) },
+		"InjectDeep":            func(p *Psyringe) error { return p.InjectDeep(nil) },
+		"Inject list of slices": func(p *Psyringe) error { return p.Inject([]interface{}{nil}) },
+	}
+	for name, inject := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := inject(New())
+			var ite InvalidTargetError
+			if !errors.As(err, &ite) || ite.Kind != TargetNotPointer || ite.Type != nil {
+				t.Fatalf("got error %v; want InvalidTargetError for nil", err)
+			}
+			if !strings.Contains(err.Error(), "(<nil>) failed: target must be a pointer") &&
+				!strings.Contains(err.Error(), "<nil> target failed: target must be a pointer") {
+				t.Errorf("got error %q; want it to name the nil target", err)
+			}
+		})
+	}
+}
+
+func TestPsyringe_Inject_slice(t *testing.T) {
+	type Handler struct{ String string }
+	handlers := []*Handler{{}, {}, {}}
+	var other struct{ Int int }
+	p := New("hello", 1)
+	if err := p.Inject(handlers, &other); err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range handlers {
+		if h.String != "hello" {
+			t.Errorf("got handlers[%d].String %q; want %q", i, h.String, "hello")
+		}
+	}
+	if other.Int != 1 {
+		t.Errorf("got Int %d; want 1", other.Int)
+	}
+
+	array := [2]*Handler{{}, {}}
+	if err := p.Inject(array); err != nil {
+		t.Fatal(err)
+	}
+	if array[0].String != "hello" || array[1].String != "hello" {
+		t.Errorf("array elements not injected")
+	}
+
+	if err := p.Inject([]*Handler{}); err != nil {
+		t.Errorf("got error %q injecting empty slice; want nil", err)
+	}
+}
+
+func TestPsyringe_Inject_sliceErrors(t *testing.T) {
+	type Handler struct{ String string }
+	err := New().Inject([]*Handler{{}, nil, {}, nil})
+	expected := "inject into target[1] (*psyringe.Handler) failed: target is nil\n" +
+		"inject into target[3] (*psyringe.Handler) failed: target is nil"
+	if err == nil || err.Error() != expected {
+		t.Errorf("got error %v; want %q", err, expected)
+	}
+
+	err = New().Inject([]int{1})
+	expected = "inject into target[0] (int) failed: target must be a pointer"
+	if err == nil || err.Error() != expected {
+		t.Errorf("got error %v; want %q", err, expected)
+	}
+}
+
+func TestPsyringe_InjectValue(t *testing.T) {
+	type Target struct{ String string }
+	p := New("hello")
+
+	var byPtr Target
+	if err := p.InjectValue(reflect.ValueOf(&byPtr)); err != nil {
+		t.Fatal(err)
+	}
+	if byPtr.String != "hello" {
+		t.Errorf("pointer target not injected")
+	}
+
+	var addressable Target
+	if err := p.InjectValue(reflect.ValueOf(&addressable).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if addressable.String != "hello" {
+		t.Errorf("addressable struct target not injected")
+	}
+
+	cases := map[string]reflect.Value{
+		"inject into psyringe.Target target failed: target must be addressable": reflect.ValueOf(Target{}),
+		"inject into *psyringe.Target target failed: target is nil":             reflect.ValueOf((*Target)(nil)),
+		"inject into int target failed: target must be a pointer":               reflect.ValueOf(1),
+		"inject into invalid target failed: target must be a pointer":           {},
+		"inject into *string target failed: target must be a pointer to struct": reflect.ValueOf(new(string)),
+	}
+	for expected, v := range cases {
+		err := p.InjectValue(v)
+		if err == nil || err.Error() != expected {
+			t.Errorf("got error %v; want %q", err, expected)
+		}
+	}
+}
+
 func TestPsyringe_Inject_customErrors(t *testing.T) {
 	newString := func() (string, error) {
 		return "", fmt.Errorf("an error")
@@ -313,10 +442,19 @@ func TestPsyringe_Inject_concurrentErrors(t *testing.T) {
 		t.Fatalf("got no error")
 	}
 
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got a %T; want InjectErrors", err)
+	}
+	if len(ie.Errors()) != 2 {
+		t.Fatalf("got %d errors; want 2", len(ie.Errors()))
+	}
 	expectedErr := "this error should always occur"
-	actualErr := errors.Cause(err).Error()
-	if actualErr != expectedErr {
-		t.Errorf("got %q; want %q", actualErr, expectedErr)
+	for _, err := range ie.Errors() {
+		actualErr := errors.Cause(err).Error()
+		if actualErr != expectedErr {
+			t.Errorf("got %q; want %q", actualErr, expectedErr)
+		}
 	}
 
 }
@@ -354,3 +492,207 @@ func TestPsyringe_Inject_concurrentCalls(t *testing.T) {
 	}()
 
 }
+
+func TestPsyringe_Inject_dependencyCycle(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+		C *struct{}
+	)
+	p := New()
+	// Cycles are normally rejected by Add, so allow them here to make sure
+	// Inject copes with them rather than deadlocking.
+	p.allowAddCycle = true
+	p.Add(
+		func(B) A { return nil },
+		func(C) B { return nil },
+		func(A) C { return nil },
+	)
+	var target struct{ A A }
+
+	errs := make(chan error)
+	go func() { errs <- p.Inject(&target) }()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("Inject deadlocked on dependency cycle")
+	}
+	if err == nil {
+		t.Fatal("got nil error; want dependency cycle error")
+	}
+	want := "dependency cycle: psyringe.A: depends on psyringe.B: depends on psyringe.C: depends on psyringe.A"
+	if got := errors.Cause(err).Error(); got != want {
+		t.Errorf("got cause %q; want %q", got, want)
+	}
+	if _, ok := errors.Cause(err).(DependencyCycle); !ok {
+		t.Errorf("got cause of type %T; want DependencyCycle", errors.Cause(err))
+	}
+}
+
+func TestPsyringe_Inject_memoizedErrors(t *testing.T) {
+	var calls Counter
+	p := New(func() (string, error) {
+		return "", fmt.Errorf("failure %d", calls.Increment())
+	})
+
+	const want = `inject into *psyringe.dependent target failed: getting field String (string) failed: invoking string constructor (func() (string, error)) failed: failure 1`
+	for i := 0; i < 3; i++ {
+		err := p.Inject(&dependent{})
+		if err == nil {
+			t.Fatalf("inject %d: got nil error; want %q", i, want)
+		}
+		if got := err.Error(); got != want {
+			t.Errorf("inject %d: got error %q; want %q", i, got, want)
+		}
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+
+	// A clone of a psyringe whose constructor failed gets a fresh attempt.
+	const wantClone = `inject into *psyringe.dependent target failed: getting field String (string) failed: invoking string constructor (func() (string, error)) failed: failure 2`
+	err := p.Clone().Inject(&dependent{})
+	if err == nil {
+		t.Fatalf("clone: got nil error; want %q", wantClone)
+	}
+	if got := err.Error(); got != wantClone {
+		t.Errorf("clone: got error %q; want %q", got, wantClone)
+	}
+}
+
+func TestPsyringe_Inject_multipleOutputs(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() (int, string, error) {
+			calls.Increment()
+			return 3, "abc", nil
+		},
+		func(i int, s string) *bytes.Buffer {
+			return bytes.NewBufferString(strings.Repeat(s, i))
+		},
+	)
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	clone := p.Clone()
+
+	var d dependent
+	p.MustInject(&d)
+	if d.Int != 3 || d.String != "abc" || d.Buffer.String() != "abcabcabc" {
+		t.Errorf("got %+v", d)
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+
+	// Clones share a single call between all outputs too.
+	clone.MustInject(&d)
+	if calls.Value() != 2 {
+		t.Errorf("constructor called %d times after injecting clone; want 2", calls.Value())
+	}
+	p.Clone().MustInject(&d)
+	if calls.Value() != 2 {
+		t.Errorf("constructor called %d times after cloning realised; want 2", calls.Value())
+	}
+}
+
+func TestPsyringe_Inject_realisedFields(t *testing.T) {
+	var calls Counter
+	p := New("hello", func() int { return int(calls.Increment()) })
+	targetType := reflect.TypeOf(dependent{})
+	if p.plan(targetType).ready(p) {
+		t.Errorf("fields ready before int constructor called")
+	}
+
+	// Once every constructor needed has been called, injecting is done
+	// without waiting and gives the same results.
+	for i := 0; i < 3; i++ {
+		var d dependent
+		p.MustInject(&d)
+		if d.Int != 1 || d.String != "hello" || d.Buffer != nil {
+			t.Errorf("inject %d: got %+v", i, d)
+		}
+		if !p.plan(targetType).ready(p) {
+			t.Errorf("inject %d: fields not ready after int constructor called", i)
+		}
+	}
+
+	// A failed constructor does not need waiting for either.
+	q := New(func() (string, error) { return "", fmt.Errorf("failure") })
+	for i := 0; i < 2; i++ {
+		err := q.Inject(&dependent{})
+		const want = `inject into *psyringe.dependent target failed: getting field String (string) failed: invoking string constructor (func() (string, error)) failed: failure`
+		if err == nil || err.Error() != want {
+			t.Errorf("inject %d: got error %v; want %q", i, err, want)
+		}
+	}
+	if !q.plan(targetType).ready(q) {
+		t.Errorf("fields not ready after string constructor failed")
+	}
+}
+
+type (
+	serverOption func(*server)
+	server       struct {
+		addr string
+		opts int
+	}
+)
+
+func newServer(addr string, opts ...serverOption) *server {
+	s := &server{addr: addr}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func TestPsyringe_Inject_variadic(t *testing.T) {
+	countOpt := serverOption(func(s *server) { s.opts++ })
+	cases := []struct {
+		name   string
+		things []interface{}
+		want   server
+	}{
+		{"mixed, no options", []interface{}{newServer, ":80"}, server{":80", 0}},
+		{"mixed, options", []interface{}{newServer, ":80", []serverOption{countOpt, countOpt}}, server{":80", 2}},
+		{"variadic only, no options", []interface{}{
+			func(opts ...serverOption) *server { return newServer(":0", opts...) },
+		}, server{":0", 0}},
+		{"variadic only, options", []interface{}{
+			func(opts ...serverOption) *server { return newServer(":0", opts...) },
+			[]serverOption{countOpt},
+		}, server{":0", 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := New(c.things...)
+			if err := p.Test(); err != nil {
+				t.Fatal(err)
+			}
+			var target struct{ Server *server }
+			if err := p.Inject(&target); err != nil {
+				t.Fatal(err)
+			}
+			if *target.Server != c.want {
+				t.Errorf("got %+v; want %+v", *target.Server, c.want)
+			}
+		})
+	}
+}
+
+func TestPsyringe_Test_variadicFixedParamMissing(t *testing.T) {
+	err := New(newServer).Test()
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	const want = "unable to satisfy param 0: no constructor or value for string"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q; want it to contain %q", err, want)
+	}
+	if strings.Contains(err.Error(), "param 1") {
+		t.Errorf("got error %q; variadic param 1 should not be reported", err)
+	}
+}
@@ -0,0 +1,146 @@
+package psyringe
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ctxKey struct{}
+
+func TestPsyringe_InjectCtx(t *testing.T) {
+	p := New(
+		func(ctx context.Context) string {
+			s, _ := ctx.Value(ctxKey{}).(string)
+			return s
+		},
+		func(ctx context.Context, s string) (int, error) {
+			return len(s), ctx.Err()
+		},
+	)
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		String string
+		Int    int
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "hello")
+	if err := p.InjectCtx(ctx, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.String != "hello" {
+		t.Errorf("got String %q; want %q", target.String, "hello")
+	}
+	if target.Int != 5 {
+		t.Errorf("got Int %d; want 5", target.Int)
+	}
+}
+
+func TestPsyringe_Inject_contextBackground(t *testing.T) {
+	p := New(func(ctx context.Context) (int, error) {
+		if ctx == nil {
+			return 0, errors.New("nil context")
+		}
+		return 1, nil
+	})
+	var target struct{ Int int }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Int != 1 {
+		t.Errorf("got Int %d; want 1", target.Int)
+	}
+}
+
+type ctxSlow struct{}
+type ctxDependent struct{}
+
+func TestPsyringe_InjectCtx_cancelled(t *testing.T) {
+	var dependentCalls Counter
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := New(
+		func() ctxSlow {
+			close(started)
+			<-release
+			return ctxSlow{}
+		},
+		func(ctxSlow) ctxDependent {
+			dependentCalls.Increment()
+			return ctxDependent{}
+		},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	var target struct{ Dependent ctxDependent }
+	err := p.InjectCtx(ctx, &target)
+	close(release)
+	if err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	if errors.Cause(err) != context.Canceled {
+		t.Errorf("got error cause %v; want %v", errors.Cause(err), context.Canceled)
+	}
+	// Either the field or the dependent constructor may notice first.
+	wantSuffixes := []string{
+		"constructing psyringe.ctxDependent: context canceled",
+		"getting argument 0 failed: constructing psyringe.ctxSlow: context canceled",
+	}
+	if !strings.HasSuffix(err.Error(), wantSuffixes[0]) && !strings.HasSuffix(err.Error(), wantSuffixes[1]) {
+		t.Errorf("got error %q; want suffix %q or %q", err, wantSuffixes[0], wantSuffixes[1])
+	}
+
+	// The aborted constructor is not called, and its outcome is memoized.
+	err = p.Inject(&target)
+	if err == nil {
+		t.Fatal("got nil error; want error")
+	}
+	if errors.Cause(err) != context.Canceled {
+		t.Errorf("got error cause %v; want %v", errors.Cause(err), context.Canceled)
+	}
+	if dependentCalls.Value() != 0 {
+		t.Errorf("dependent constructor called %d times; want 0", dependentCalls.Value())
+	}
+}
+
+// TestPsyringe_InjectCtx_cancelledLocked checks that a constructor still
+// running after InjectCtx is cancelled keeps p read-locked, so that changing
+// what it reads waits for it rather than racing with it. Run with -race.
+func TestPsyringe_InjectCtx_cancelledLocked(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p := New(func() ctxSlow {
+		close(started)
+		<-release
+		return ctxSlow{}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	var target struct{ Slow ctxSlow }
+	if err := p.InjectCtx(ctx, &target); errors.Cause(err) != context.Canceled {
+		t.Fatalf("got error %v; want %v", err, context.Canceled)
+	}
+	set := make(chan struct{})
+	go func() {
+		p.SetConstructorTimeout(time.Second)
+		p.SetCollector(&testCollector{})
+		close(set)
+	}()
+	select {
+	case <-set:
+		t.Error("changed p while its constructor was running")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	<-set
+}
@@ -0,0 +1,60 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestPsyringe_InjectNonZero(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() int { calls.Increment(); return 1 },
+		"injected",
+		bytes.NewBufferString("injected"),
+		Bind((*io.Reader)(nil), func() *bytes.Reader { return bytes.NewReader(nil) }),
+	)
+	var skipped []string
+	mu := sync.Mutex{}
+	p.Hooks.FieldAlreadySet = func(parentTypeName string, field reflect.StructField) {
+		mu.Lock()
+		defer mu.Unlock()
+		skipped = append(skipped, fmt.Sprintf("%s.%s", parentTypeName, field.Name))
+	}
+
+	type target struct {
+		Int    int
+		String string
+		Buffer *bytes.Buffer
+		Reader io.Reader
+	}
+	preset := bytes.NewBufferString("preset")
+	presetReader := &bytes.Buffer{}
+	set := target{Int: 2, String: "preset", Buffer: preset, Reader: presetReader}
+	if err := p.InjectNonZero(&set); err != nil {
+		t.Fatal(err)
+	}
+	if set.Int != 2 || set.String != "preset" || set.Buffer != preset || set.Reader != presetReader {
+		t.Errorf("preset fields overwritten: got %+v", set)
+	}
+	if calls.Value() != 0 {
+		t.Errorf("int constructor called %d times for a preset field; want 0", calls.Value())
+	}
+	sort.Strings(skipped)
+	want := []string{"*psyringe.target.Buffer", "*psyringe.target.Int", "*psyringe.target.Reader", "*psyringe.target.String"}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("got skipped %q; want %q", skipped, want)
+	}
+
+	var unset target
+	if err := p.InjectNonZero(&unset); err != nil {
+		t.Fatal(err)
+	}
+	if unset.Int != 1 || unset.String != "injected" || unset.Buffer.String() != "injected" || unset.Reader == nil {
+		t.Errorf("zero fields not injected: got %+v", unset)
+	}
+}
@@ -43,7 +43,7 @@ var panickers = map[string]func(){ // These tests are very brittle; see note 1 b
 	},
 
 	// MustInject
-	`^inject into \*psyringe.HasIntField target failed: getting field Int \(int\) failed: invoking int constructor \(func\(string\) int\) failed: no constructor or value for string`: func() {
+	`^inject into \*psyringe.HasIntField target failed: getting field Int \(int\) failed: unable to satisfy constructor func\(string\) int: unable to satisfy param 0: no constructor or value for string`: func() {
 		p, err := NewErr(func(s string) int { return len(s) })
 		if err != nil {
 			panic("inconclusive; NewErr failed: " + err.Error())
@@ -0,0 +1,94 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestPsyringe_Realise(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() *bytes.Buffer {
+			calls.Increment()
+			return bytes.NewBufferString("hello")
+		},
+		func(b *bytes.Buffer) int { return b.Len() },
+		"a string",
+	)
+
+	var (
+		n   int
+		s   string
+		buf *bytes.Buffer
+	)
+	if err := p.Realise(&n, &s, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("got int %d; want 5", n)
+	}
+	if s != "a string" {
+		t.Errorf("got string %q; want %q", s, "a string")
+	}
+	if buf == nil || buf.String() != "hello" {
+		t.Errorf("got buffer %v; want %q", buf, "hello")
+	}
+
+	var buf2 *bytes.Buffer
+	p.Realise(&buf2)
+	if buf2 != buf {
+		t.Errorf("got a different *bytes.Buffer on second call")
+	}
+	if calls.Value() != 1 {
+		t.Errorf("constructor called %d times; want 1", calls.Value())
+	}
+}
+
+func TestPsyringe_Realise_errors(t *testing.T) {
+	p := New(func() (int, error) { return 0, fmt.Errorf("int error") })
+
+	testCases := []struct {
+		desc    string
+		target  interface{}
+		wantErr string
+	}{
+		{
+			desc:    "not a pointer",
+			target:  1,
+			wantErr: "realise into int target failed: target must be a pointer",
+		},
+		{
+			desc:    "nil",
+			target:  nil,
+			wantErr: "realise into <nil> target failed: target must be a pointer",
+		},
+		{
+			desc:    "nil pointer",
+			target:  (*int)(nil),
+			wantErr: "realise into *int target failed: target is nil",
+		},
+		{
+			desc:    "unknown type",
+			target:  new(string),
+			wantErr: "realise into *string target failed: no constructor or value for string",
+		},
+		{
+			desc:    "constructor error",
+			target:  new(int),
+			wantErr: "realise into *int target failed: invoking int constructor (func() (int, error)) failed: int error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := p.Realise(tc.target)
+			if err == nil {
+				t.Fatalf("got nil error; want %q", tc.wantErr)
+			}
+			if got := err.Error(); got != tc.wantErr {
+				t.Errorf("got error %q; want %q", got, tc.wantErr)
+			}
+		})
+	}
+}
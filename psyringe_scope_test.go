@@ -111,3 +111,102 @@ func TestPsyringe_Scope_error_nonUniqueScopeName(t *testing.T) {
 		t.Fatalf("got nil; want error %q", expected)
 	}
 }
+
+func TestPsyringe_Scope_chain(t *testing.T) {
+	root := New(1)
+	middle := root.Scope("middle")
+	middle.Add(func(float64) string { return "" })
+	leaf := middle.Scope("leaf")
+
+	if leaf.ScopeName() != "leaf" || leaf.Parent() != middle || middle.Parent() != root || root.Parent() != nil {
+		t.Errorf("got wrong scope names or parents")
+	}
+	if got, want := fmt.Sprint(leaf.ScopePath()), "[<root> middle leaf]"; got != want {
+		t.Errorf("got scope path %s; want %s", got, want)
+	}
+
+	var target struct{ String string }
+	const wantInject = "inject into *struct { String string } target failed: getting field String (string) failed: unable to satisfy constructor func(float64) string: unable to satisfy param 0: no constructor or value for float64 (scope <root>/middle)"
+	if err := leaf.Inject(&target); err == nil || err.Error() != wantInject {
+		t.Errorf("got inject error %v; want %q", err, wantInject)
+	}
+
+	const wantTest = "unable to satisfy constructor func(float64) string: unable to satisfy param 0: no constructor or value for float64 (scope <root>/middle)"
+	if err := middle.Test(); err == nil || err.Error() != wantTest {
+		t.Errorf("got test error %v; want %q", err, wantTest)
+	}
+
+	wantAdd := regexp.MustCompile(`^adding string value failed: injection type string already registered at .*/psyringe_scope_test.go:\d+ \(scope <root>/middle\)$`)
+	if err := leaf.AddErr("again"); err == nil || !wantAdd.MatchString(err.Error()) {
+		t.Errorf("got add error %v; want match for %s", err, wantAdd)
+	}
+
+	const wantCycle = "adding constructor func(int8) int16 failed: dependency cycle: int16: depends on int8: depends on int16 (scope <root>/middle/leaf)"
+	leaf.Add(func(int16) int8 { return 0 })
+	if err := leaf.AddErr(func(int8) int16 { return 0 }); err == nil || err.Error() != wantCycle {
+		t.Errorf("got cycle error %v; want %q", err, wantCycle)
+	}
+}
+
+func TestPsyringe_GetScope(t *testing.T) {
+	var calls Counter
+	root := New(func() int { return int(calls.Increment()) })
+	request := root.Scope("request")
+	request.Add(func(i int) string { return fmt.Sprint("request ", i) })
+	root.Scope("session")
+
+	if got, ok := root.GetScope("request"); !ok || got != request {
+		t.Errorf("got scope %v, %t; want request scope", got, ok)
+	}
+	if _, ok := root.GetScope("missing"); ok {
+		t.Errorf("found missing scope")
+	}
+	if got, want := fmt.Sprint(root.Scopes()), "[request session]"; got != want {
+		t.Errorf("got scopes %s; want %s", got, want)
+	}
+
+	// Clone root, fetch request scope, inject.
+	for i := 1; i <= 2; i++ {
+		clone := root.Clone()
+		if got, want := fmt.Sprint(clone.Scopes()), "[request session]"; got != want {
+			t.Errorf("clone %d: got scopes %s; want %s", i, got, want)
+		}
+		scope, ok := clone.GetScope("request")
+		if !ok {
+			t.Fatalf("clone %d: request scope not found", i)
+		}
+		if again, _ := clone.GetScope("request"); again != scope {
+			t.Errorf("clone %d: got different request scope the second time", i)
+		}
+		if scope.Parent() != clone {
+			t.Errorf("clone %d: request scope's parent is not the clone", i)
+		}
+		var target struct{ String string }
+		scope.MustInject(&target)
+		if want := fmt.Sprint("request ", i); target.String != want {
+			t.Errorf("clone %d: got %q; want %q", i, target.String, want)
+		}
+	}
+}
+
+func TestPsyringe_GetScope_perRequestClone(t *testing.T) {
+	type requestID string
+	root := New()
+	request := root.Scope("request")
+	for i := 1; i <= 3; i++ {
+		clone := root.Clone()
+		clone.Add(requestID(fmt.Sprint("req-", i)))
+		scope, ok := clone.GetScope("request")
+		if !ok {
+			t.Fatalf("request %d: request scope not found", i)
+		}
+		if scope.plans == request.plans {
+			t.Errorf("request %d: scope shares plans with the original", i)
+		}
+		var target struct{ ID requestID }
+		scope.MustInject(&target)
+		if want := requestID(fmt.Sprint("req-", i)); target.ID != want {
+			t.Errorf("request %d: got %q; want %q", i, target.ID, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package psyringe
 import (
 	"bytes"
 	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -104,9 +105,10 @@ func TestPsyringe_Test_dependencyCycle(t *testing.T) {
 			ctors: []interface{}{
 				func(B) A { return nil },
 				func(B) B { return nil },
-				func(C) C { return nil }, // This one is not reached.
+				func(C) C { return nil },
 			},
-			wantErr: "dependency cycle: psyringe.A: depends on psyringe.B: depends on psyringe.B",
+			wantErr: "dependency cycle: psyringe.A: depends on psyringe.B: depends on psyringe.B\n" +
+				"dependency cycle: psyringe.C: depends on psyringe.C",
 		},
 		{
 			desc: "first arg satisfied",
@@ -159,11 +161,12 @@ func TestPsyringe_Test_deterministic_output(t *testing.T) {
 		{
 			desc: "missing ctors sorted",
 			ctors: []interface{}{
-				func(int) A { return nil }, // No ctor for int (want)
+				func(int) A { return nil }, // No ctor for int (first)
 				func(int) B { return nil }, // No ctor for int.
 
 			},
-			wantErr: "unable to satisfy constructor func(int) psyringe.A: unable to satisfy param 0: no constructor or value for int",
+			wantErr: "unable to satisfy constructor func(int) psyringe.A: unable to satisfy param 0: no constructor or value for int\n" +
+				"unable to satisfy constructor func(int) psyringe.B: unable to satisfy param 0: no constructor or value for int",
 		},
 	}
 
@@ -191,3 +194,67 @@ func TestPsyringe_Test_deterministic_output(t *testing.T) {
 		})
 	}
 }
+
+func TestPsyringe_Test_report(t *testing.T) {
+	type (
+		A *struct{}
+		B *struct{}
+		C *struct{}
+	)
+	p := New()
+	p.allowAddCycle = true
+	p.Add(
+		func(int, float64) A { return nil },
+		func(C) B { return nil },
+		func(B) (C, string) { return nil, "" },
+	)
+	p.AddNamed("n", func(complex64) A { return nil })
+	err := p.Test()
+	report, ok := err.(TestReport)
+	if !ok {
+		t.Fatalf("got error %v (%T); want TestReport", err, err)
+	}
+	want := []string{
+		"unable to satisfy constructor func(int, float64) psyringe.A: unable to satisfy param 0: no constructor or value for int",
+		"unable to satisfy constructor func(int, float64) psyringe.A: unable to satisfy param 1: no constructor or value for float64",
+		`unable to satisfy constructor func(complex64) psyringe.A named "n": unable to satisfy param 0: no constructor or value for complex64`,
+		"dependency cycle: psyringe.B: depends on psyringe.C: depends on psyringe.B",
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("got %d problems; want %d:\n%s", len(report.Problems), len(want), report)
+	}
+	for i, problem := range report.Problems {
+		if problem.Error() != want[i] {
+			t.Errorf("got problem %d %q; want %q", i, problem, want[i])
+		}
+	}
+	if got, want := report.Error(), strings.Join(want, "\n"); got != want {
+		t.Errorf("got error:\n%s\nwant:\n%s", got, want)
+	}
+	if report.Problems[2].Name != "n" {
+		t.Errorf("got problem 2 name %q; want %q", report.Problems[2].Name, "n")
+	}
+	dc, ok := report.Problems[3].Err.(DependencyCycle)
+	if !ok {
+		t.Fatalf("got problem 3 error %T; want DependencyCycle", report.Problems[3].Err)
+	}
+	if len(dc.Path) != 3 {
+		t.Errorf("got cycle path %v; want 3 types", dc.Path)
+	}
+}
+
+func TestPsyringe_MustTest(t *testing.T) {
+	New(func(int) string { return "" }, 1).MustTest()
+
+	defer func() {
+		r := recover()
+		report, ok := r.(TestReport)
+		if !ok {
+			t.Fatalf("got panic %v (%T); want TestReport", r, r)
+		}
+		if len(report.Problems) != 1 {
+			t.Errorf("got %d problems; want 1:\n%s", len(report.Problems), report)
+		}
+	}()
+	New(func(int) string { return "" }).MustTest()
+}
@@ -0,0 +1,93 @@
+// Package psyringehttp serves HTTP requests using handlers injected by a
+// Psyringe, cloned for each request.
+package psyringehttp
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+// ErrorHandlerFunc handles an error injecting into a handler. It is
+// responsible for writing the response.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option configures a handler returned by Handler.
+type Option func(*handler)
+
+// WithErrorHandler sets the func called instead of the handler when injecting
+// into it fails. By default, the response is a 500 Internal Server Error.
+func WithErrorHandler(f ErrorHandlerFunc) Option {
+	return func(h *handler) { h.onError = f }
+}
+
+// WithCloseErrorHandler sets the func called when closing the values
+// constructed for a request fails, see Psyringe.Close. By default, the error
+// is logged.
+func WithCloseErrorHandler(f func(r *http.Request, err error)) Option {
+	return func(h *handler) { h.onCloseError = f }
+}
+
+// Handler returns an http.Handler which, for each request, clones p, adds the
+// request's *http.Request, http.ResponseWriter and context.Context to the
+// clone as values, and injects into the handler returned by newHandler, which
+// must be a pointer to a struct. It then calls the handler's ServeHTTP, and
+// closes the values constructed for the request.
+//
+// Constructors taking a context.Context as their first parameter are passed
+// the request's context. p must not already have the injection types
+// *http.Request, http.ResponseWriter or context.Context.
+func Handler(p *psyringe.Psyringe, newHandler func() http.Handler, opts ...Option) http.Handler {
+	h := &handler{
+		p:            p,
+		newHandler:   newHandler,
+		onError:      internalServerError,
+		onCloseError: logCloseError,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type handler struct {
+	p            *psyringe.Psyringe
+	newHandler   func() http.Handler
+	onError      ErrorHandlerFunc
+	onCloseError func(r *http.Request, err error)
+}
+
+// ServeHTTP injects a new handler for r, and calls it.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p := h.p.Clone()
+	defer func() {
+		if err := p.Close(context.Background()); err != nil {
+			h.onCloseError(r, err)
+		}
+	}()
+	err := p.AddErr(r,
+		psyringe.Bind((*http.ResponseWriter)(nil), w),
+		psyringe.Bind((*context.Context)(nil), ctx),
+	)
+	if err != nil {
+		h.onError(w, r, err)
+		return
+	}
+	handler := h.newHandler()
+	if err := p.InjectCtx(ctx, handler); err != nil {
+		h.onError(w, r, err)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+func internalServerError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func logCloseError(r *http.Request, err error) {
+	log.Printf("psyringehttp: closing values for %s %s failed: %s", r.Method, r.URL, err)
+}
@@ -0,0 +1,130 @@
+package psyringehttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+type (
+	user      string
+	greeting  string
+	greetPage struct {
+		Greeting greeting
+		W        http.ResponseWriter
+	}
+)
+
+func (h *greetPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(h.W, h.Greeting)
+}
+
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	body, err := io.ReadAll(w.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	var mu sync.Mutex
+	var constructed []string
+	p := psyringe.New(
+		func(r *http.Request) (user, error) {
+			u := r.URL.Query().Get("user")
+			if u == "" {
+				return "", fmt.Errorf("no user")
+			}
+			return user(u), nil
+		},
+		func(ctx context.Context, u user) greeting {
+			mu.Lock()
+			defer mu.Unlock()
+			constructed = append(constructed, string(u))
+			return greeting("hello " + u)
+		},
+	)
+	h := Handler(p, func() http.Handler { return &greetPage{} })
+
+	for _, u := range []string{"alice", "bob", "alice"} {
+		code, body := get(t, h, "/?user="+u)
+		if code != 200 {
+			t.Errorf("got status %d; want 200", code)
+		}
+		if want := "hello " + u; body != want {
+			t.Errorf("got body %q; want %q", body, want)
+		}
+	}
+	// Each request has its own clone, so nothing is shared between them.
+	if got, want := strings.Join(constructed, ","), "alice,bob,alice"; got != want {
+		t.Errorf("constructed greetings for %s; want %s", got, want)
+	}
+
+	code, body := get(t, h, "/")
+	if code != 500 {
+		t.Errorf("got status %d; want 500", code)
+	}
+	if want := "Internal Server Error\n"; body != want {
+		t.Errorf("got body %q; want %q", body, want)
+	}
+}
+
+func TestHandler_WithErrorHandler(t *testing.T) {
+	p := psyringe.New(func(r *http.Request) (greeting, error) {
+		return "", fmt.Errorf("no greeting for %s", r.URL.Path)
+	})
+	h := Handler(p, func() http.Handler { return &greetPage{} },
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusTeapot)
+		}))
+
+	code, body := get(t, h, "/tea")
+	if code != http.StatusTeapot {
+		t.Errorf("got status %d; want %d", code, http.StatusTeapot)
+	}
+	if want := "no greeting for /tea\n"; !strings.HasSuffix(body, want) {
+		t.Errorf("got body %q; want suffix %q", body, want)
+	}
+}
+
+type (
+	closer     struct{ closed *int }
+	closerPage struct {
+		Closer closer
+		http.Handler
+	}
+)
+
+func (c closer) Close() error {
+	*c.closed++
+	return fmt.Errorf("close failed")
+}
+
+func TestHandler_closesValues(t *testing.T) {
+	closed := 0
+	p := psyringe.New(func() closer { return closer{&closed} })
+	var closeErr error
+	h := Handler(p, func() http.Handler {
+		return &closerPage{Handler: http.NotFoundHandler()}
+	}, WithCloseErrorHandler(func(r *http.Request, err error) { closeErr = err }))
+
+	get(t, h, "/")
+	get(t, h, "/")
+	if closed != 2 {
+		t.Errorf("closed %d times; want 2", closed)
+	}
+	if closeErr == nil || !strings.Contains(closeErr.Error(), "close failed") {
+		t.Errorf("got close error %v; want it to contain %q", closeErr, "close failed")
+	}
+}
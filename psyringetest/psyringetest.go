@@ -0,0 +1,103 @@
+// Package psyringetest helps test code using a Psyringe, by replacing
+// constructors with fakes for the duration of a test, recording calls to
+// constructors, and failing tests when a Psyringe is incomplete.
+package psyringetest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+// TestPsyringe is a Psyringe with extra methods for use in tests. Its
+// Psyringe's own methods, such as Replace and Realise, are available too.
+type TestPsyringe struct {
+	*psyringe.Psyringe
+}
+
+// Wrap returns a TestPsyringe for p. Changes made through it are made to p.
+func Wrap(p *psyringe.Psyringe) *TestPsyringe {
+	return &TestPsyringe{Psyringe: p}
+}
+
+// ReplaceScoped replaces constructors and values, as Psyringe.Replace does,
+// until the test t finishes, when they are restored, see
+// Psyringe.ReplaceRestorable. It fails t if they cannot be replaced.
+//
+// Subtests may replace the same injection types again: each replacement is
+// undone as its own test finishes.
+func (tp *TestPsyringe) ReplaceScoped(t testing.TB, constructorsAndValues ...interface{}) {
+	t.Helper()
+	restore, err := tp.Psyringe.ReplaceRestorable(constructorsAndValues...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(restore)
+}
+
+// Spy records each call to the constructor of an injection type added to tp
+// in the SpyRecord returned, see Psyringe.ObserveConstructor. The example is
+// either a reflect.Type, or an example value of the injection type. It fails t
+// if the constructor cannot be observed.
+func (tp *TestPsyringe) Spy(t testing.TB, example interface{}) *SpyRecord {
+	t.Helper()
+	record := &SpyRecord{}
+	if err := tp.Psyringe.ObserveConstructor(example, record.record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// SpyRecord records the calls to a constructor, see TestPsyringe.Spy. It is
+// safe for concurrent use.
+type SpyRecord struct {
+	mu   sync.Mutex
+	args [][]interface{}
+}
+
+// Calls returns the number of times the constructor has been called.
+func (r *SpyRecord) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.args)
+}
+
+// Args returns the arguments passed to the ith call to the constructor,
+// counting from 0, not including any leading context.Context. It returns nil
+// if there has been no such call.
+func (r *SpyRecord) Args(i int) []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i < 0 || i >= len(r.args) {
+		return nil
+	}
+	return r.args[i]
+}
+
+func (r *SpyRecord) record(args []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.args = append(r.args, args)
+}
+
+// RequireComplete fails t with the report from p.Test if any constructor in
+// p cannot be satisfied.
+func RequireComplete(t testing.TB, p *psyringe.Psyringe) {
+	t.Helper()
+	if err := p.Test(); err != nil {
+		t.Fatalf("psyringe is incomplete: %s", err)
+	}
+}
+
+// RequireInjects injects into each target, failing t if p.TestTargets reports
+// a field which cannot be injected, or if injecting fails.
+func RequireInjects(t testing.TB, p *psyringe.Psyringe, targets ...interface{}) {
+	t.Helper()
+	if err := p.TestTargets(targets...); err != nil {
+		t.Fatalf("cannot inject targets: %s", err)
+	}
+	if err := p.Inject(targets...); err != nil {
+		t.Fatalf("injecting failed: %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+package psyringetest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/samsalisbury/psyringe"
+)
+
+type (
+	store   struct{ name string }
+	service struct{ Store *store }
+)
+
+// fakeT records the failure of a test, rather than failing.
+type fakeT struct {
+	testing.TB
+	failure string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatal(args ...interface{}) {
+	t.failure = fmt.Sprint(args...)
+	runtime.Goexit()
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.Fatal(fmt.Sprintf(format, args...))
+}
+
+// failure returns the message f failed a test with, or "".
+func failure(f func(t testing.TB)) string {
+	t := &fakeT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(t)
+	}()
+	<-done
+	return t.failure
+}
+
+func newPsyringe() *psyringe.Psyringe {
+	return psyringe.New("real", func(name string) *store { return &store{name} })
+}
+
+func TestTestPsyringe_ReplaceScoped(t *testing.T) {
+	tp := Wrap(newPsyringe())
+	t.Run("fake", func(t *testing.T) {
+		tp.ReplaceScoped(t, "fake")
+		var s service
+		RequireInjects(t, tp.Psyringe, &s)
+		if s.Store.name != "fake" {
+			t.Errorf("got store %q; want %q", s.Store.name, "fake")
+		}
+	})
+	var s service
+	RequireInjects(t, tp.Psyringe, &s)
+	if s.Store.name != "real" {
+		t.Errorf("got store %q after test; want %q", s.Store.name, "real")
+	}
+
+	got := failure(func(t testing.TB) { tp.ReplaceScoped(t, 1) })
+	if !strings.Contains(got, "int: not added") {
+		t.Errorf("got failure %q; want int not added", got)
+	}
+}
+
+func TestTestPsyringe_Spy(t *testing.T) {
+	tp := Wrap(newPsyringe())
+	record := tp.Spy(t, &store{})
+	RequireComplete(t, tp.Psyringe)
+	RequireInjects(t, tp.Psyringe, &service{}, &service{})
+	if record.Calls() != 1 {
+		t.Errorf("got %d calls; want 1", record.Calls())
+	}
+	if got := fmt.Sprint(record.Args(0)); got != "[real]" {
+		t.Errorf("got args %s; want [real]", got)
+	}
+
+	got := failure(func(t testing.TB) { tp.Spy(t, "") })
+	if want := "cannot observe string: added as a value"; got != want {
+		t.Errorf("got failure %q; want %q", got, want)
+	}
+}
+
+func TestRequire_failures(t *testing.T) {
+	p := psyringe.New(func(name string) *store { return &store{name} })
+	got := failure(func(t testing.TB) { RequireComplete(t, p) })
+	if !strings.HasPrefix(got, "psyringe is incomplete: ") {
+		t.Errorf("got failure %q; want incomplete", got)
+	}
+	got = failure(func(t testing.TB) { RequireInjects(t, psyringe.New(), &service{}) })
+	if !strings.HasPrefix(got, "cannot inject targets: ") {
+		t.Errorf("got failure %q; want cannot inject", got)
+	}
+}
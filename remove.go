@@ -0,0 +1,206 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Remove removes the constructors and values of the given injection types from
+// this Psyringe. Each argument is either a reflect.Type, or an example value of
+// the injection type to remove. Removing a slice type []T removes every
+// element added to it by AddToSlice, as well as any []T added directly. If any
+// type was not added to this Psyringe, nothing is removed, and an error is
+// returned. Injection types added to a parent scope cannot be removed from a
+// child.
+//
+// Removing an injection type which another constructor depends on is allowed,
+// use Test to check the graph is still complete. Values already constructed are
+// left in place for anything which has already used them, but are not closed by
+// Close.
+func (p *Psyringe) Remove(forTypes ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	types := make([]reflect.Type, len(forTypes))
+	for i, forType := range forTypes {
+		if forType == nil {
+			return fmt.Errorf("cannot remove nil (argument %d)", i)
+		}
+		if t, ok := forType.(reflect.Type); ok {
+			types[i] = t
+			continue
+		}
+		types[i] = reflect.TypeOf(forType)
+	}
+	if err := p.checkRemovable(types); err != nil {
+		return err
+	}
+	p.unshare()
+	p.remove(types)
+	return nil
+}
+
+// Replace removes the injection types of each constructor and value given, as
+// Remove does, and then adds them, as Add does. Either all are replaced, or
+// none are and an error is returned.
+//
+// When replacing a constructor that has already been called, the replacement
+// is called next time its value is needed. Values constructed by constructors
+// which depended on the old one are not reconstructed.
+func (p *Psyringe) Replace(constructorsAndValues ...interface{}) error {
+	return p.replace(constructorsAndValues...)
+}
+
+// replace just exists to make callerinfo consistent in Psyringe.add.
+func (p *Psyringe) replace(constructorsAndValues ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	for i, thing := range constructorsAndValues {
+		if thing == nil {
+			return fmt.Errorf("cannot replace nil (argument %d)", i)
+		}
+		if err := p.checkRemovable(injectionTypesOf(thing)); err != nil {
+			return errors.Wrapf(err, "replacing %T failed", thing)
+		}
+	}
+	rollback := p.unshare()
+	for _, thing := range constructorsAndValues {
+		p.remove(injectionTypesOf(thing))
+	}
+	for _, thing := range constructorsAndValues {
+		if err := p.add(thing); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceRestorable is like Replace, but also returns a func which restores
+// the constructors and values replaced, along with any values already
+// constructed when ReplaceRestorable was called, for example to undo
+// replacing a constructor with a fake when a test finishes. Unlike Replace,
+// values constructed by anything depending on those replaced are constructed
+// afresh, both after replacing and after restoring them.
+//
+// Where replacements of the same injection type are nested, restore funcs must
+// be called in reverse order.
+func (p *Psyringe) ReplaceRestorable(constructorsAndValues ...interface{}) (restore func(), err error) {
+	r := p.newReplacement(constructorsAndValues)
+	if err := p.replace(constructorsAndValues...); err != nil {
+		return nil, err
+	}
+	r.resetDependents()
+	return r.restore, nil
+}
+
+// replacement is the state of a Psyringe before replacing some of its
+// injection types, see ReplaceRestorable.
+type replacement struct {
+	p *Psyringe
+	// old are the injection types replaced, nil for any not added.
+	old map[reflect.Type]*injectionType
+	// oldSlices are the slice types replaced, nil for any not added.
+	oldSlices map[reflect.Type]*sliceType
+	// calls are the calls p had realised.
+	calls *ctorCalls
+}
+
+// newReplacement records the current state of the injection types
+// constructorsAndValues would replace.
+func (p *Psyringe) newReplacement(constructorsAndValues []interface{}) *replacement {
+	defer p.rlockChain()()
+	r := &replacement{
+		p:         p,
+		old:       map[reflect.Type]*injectionType{},
+		oldSlices: map[reflect.Type]*sliceType{},
+		calls:     p.calls.cloneRealised(),
+	}
+	for _, thing := range constructorsAndValues {
+		if thing == nil {
+			continue
+		}
+		for _, t := range injectionTypesOf(thing) {
+			r.old[t] = p.injectionTypes[t]
+			r.oldSlices[t] = p.slices[t]
+		}
+	}
+	return r
+}
+
+// resetDependents discards the values of everything depending on the
+// injection types replaced.
+func (r *replacement) resetDependents() {
+	defer r.p.lock()()
+	types := map[reflect.Type]bool{}
+	for t := range r.old {
+		types[t] = true
+	}
+	r.p.resetDependents(types, map[*ctorFunc]bool{})
+}
+
+// restore puts back the injection types replaced, and the values realised
+// before replacing them.
+func (r *replacement) restore() {
+	p := r.p
+	defer p.lock()()
+	p.unshare()
+	types := map[reflect.Type]bool{}
+	reset := map[*ctorFunc]bool{}
+	for t, it := range r.old {
+		if current, ok := p.injectionTypesWithSlices()[t]; ok && current.Ctor != nil {
+			reset[current.Ctor.ctorFunc] = true
+		}
+		p.setInjectionType(t, it)
+		p.setSlice(t, r.oldSlices[t])
+		types[t] = true
+	}
+	p.resetDependents(types, reset)
+	p.calls.merge(r.calls)
+}
+
+// checkRemovable returns an error if any of types was not added to p.
+func (p *Psyringe) checkRemovable(types []reflect.Type) error {
+	for _, t := range types {
+		if p.injectionTypeIsRegisteredAtThisScope(t) || p.slices[t] != nil {
+			continue
+		}
+		scoped, ok := p.injectionTypeRegistrationScope(t)
+		if !ok {
+			scoped = p.sliceScope(t)
+		}
+		if scoped != nil {
+			return fmt.Errorf("cannot remove injection type %s from scope %s: added in scope %s",
+				t, p.scopePath(), scoped.scopePath())
+		}
+		return fmt.Errorf("cannot remove injection type %s: not added", t)
+	}
+	return nil
+}
+
+// remove deletes types from p. p must be write-locked and unshared.
+func (p *Psyringe) remove(types []reflect.Type) {
+	for _, t := range types {
+		p.setInjectionType(t, nil)
+		p.setSlice(t, nil)
+	}
+}
+
+// injectionTypesOf returns the injection types constructorOrValue would be
+// added as.
+func injectionTypesOf(constructorOrValue interface{}) []reflect.Type {
+	if b, ok := constructorOrValue.(Binding); ok && b.err == nil {
+		return []reflect.Type{b.iface}
+	}
+	v := reflect.ValueOf(constructorOrValue)
+	t := v.Type()
+	if ctors := newCtors(t, v); ctors != nil {
+		return ctors[0].outTypes
+	}
+	return []reflect.Type{t}
+}
@@ -0,0 +1,94 @@
+package psyringe
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPsyringe_Remove(t *testing.T) {
+	p := New(1, func() string { return "hello" }, bytes.NewBufferString("world"))
+	if err := p.Remove(0, reflect.TypeOf("")); err != nil {
+		t.Fatal(err)
+	}
+	var d dependent
+	p.MustInject(&d)
+	if d.Int != 0 || d.String != "" || d.Buffer.String() != "world" {
+		t.Errorf("got %+v", d)
+	}
+	// Removed types can be added again.
+	p.Add(2)
+	p.MustInject(&d)
+	if d.Int != 2 {
+		t.Errorf("got int %d; want 2", d.Int)
+	}
+}
+
+func TestPsyringe_Remove_dependency(t *testing.T) {
+	p := New(1, func(int) string { return "hello" })
+	if err := p.Remove(0); err != nil {
+		t.Fatal(err)
+	}
+	const want = "unable to satisfy constructor func(int) string: unable to satisfy param 0: no constructor or value for int"
+	if err := p.Test(); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestPsyringe_Remove_errors(t *testing.T) {
+	p := New(1)
+	child := p.Scope("child")
+	child.Add("hello")
+
+	const wantUnadded = "cannot remove injection type float64: not added"
+	if err := child.Remove("", 1.0); err == nil || err.Error() != wantUnadded {
+		t.Errorf("got error %v; want %q", err, wantUnadded)
+	}
+	const wantParent = "cannot remove injection type int from scope <root>/child: added in scope <root>"
+	if err := child.Remove(0); err == nil || err.Error() != wantParent {
+		t.Errorf("got error %v; want %q", err, wantParent)
+	}
+	// Nothing was removed.
+	var d dependent
+	child.MustInject(&d)
+	if d.Int != 1 || d.String != "hello" {
+		t.Errorf("got %+v", d)
+	}
+}
+
+func TestPsyringe_Replace(t *testing.T) {
+	var calls Counter
+	p := New(func() int { return int(calls.Increment()) }, "hello")
+	var d dependent
+	p.MustInject(&d)
+
+	if err := p.Replace(func() int { return 10 }, "world"); err != nil {
+		t.Fatal(err)
+	}
+	p.MustInject(&d)
+	if d.Int != 10 || d.String != "world" {
+		t.Errorf("got %+v", d)
+	}
+	if calls.Value() != 1 {
+		t.Errorf("old constructor called %d times; want 1", calls.Value())
+	}
+}
+
+func TestPsyringe_Replace_atomic(t *testing.T) {
+	p := New(1, "hello")
+	const want = "replacing float64 failed: cannot remove injection type float64: not added"
+	if err := p.Replace(2, 1.5); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	// A cycle is only found once the replacement is added.
+	err := p.Replace(func(string) int { return 0 }, func(int) string { return "" })
+	if !errors.Is(err, DependencyCycle{}) {
+		t.Errorf("got error %v; want dependency cycle", err)
+	}
+	var d dependent
+	p.MustInject(&d)
+	if d.Int != 1 || d.String != "hello" {
+		t.Errorf("got %+v", d)
+	}
+}
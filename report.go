@@ -0,0 +1,239 @@
+package psyringe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// InjectionReport describes what InjectWithReport did to each field of its
+// target. It has only strings, bools and durations, so that it can be
+// marshalled as JSON, for example to compare with a golden file once the
+// durations have been zeroed.
+type InjectionReport struct {
+	// Target is the type of the target injected into, like "*main.Server".
+	Target string
+	// Fields describes each field of the target, in declaration order. The
+	// fields of embedded structs, and of structs injected into by deep
+	// injection, follow the field containing them.
+	Fields []FieldReport
+	// Warnings are the warnings returned by constructors called while
+	// injecting, see Warning, each like "*main.Cache: cache is cold", in the
+	// order they were returned.
+	Warnings []string
+	// Elapsed is how long injecting took.
+	Elapsed time.Duration
+}
+
+// FieldReport describes how a single field was injected, see
+// InjectionReport.
+type FieldReport struct {
+	// Target is the type of the struct containing the field, like
+	// "*main.Server".
+	Target string
+	// Field is the name of the field.
+	Field string
+	// Type is the type of the field.
+	Type string
+	// Source is where the field's value came from.
+	Source FieldSource
+	// InjectionType is the injection type providing the value, when Source
+	// is FieldFromValue or FieldFromConstructor. It differs from Type when
+	// Type is an interface bound to it.
+	InjectionType string
+	// Name is the field's psyringe tag, if it has one.
+	Name string
+	// Constructed is true if the constructor was called to inject this
+	// field, rather than its value having already been constructed.
+	Constructed bool
+	// Reason is why the field was skipped, when Source is FieldSkipped.
+	Reason string
+	// Elapsed is how long getting the value took, including calling any
+	// constructors needed.
+	Elapsed time.Duration
+	// Err is the error injecting the field, or empty.
+	Err string
+}
+
+// FieldSource is where the value injected into a field came from, see
+// FieldReport.
+type FieldSource int
+
+const (
+	// FieldFromValue is a field set to a value added to the Psyringe.
+	FieldFromValue FieldSource = iota + 1
+	// FieldFromConstructor is a field set to the value of a constructor.
+	FieldFromConstructor
+	// FieldFromPsyringe is a field set to a value the Psyringe provides
+	// itself: the context passed to InjectCtx, the Psyringe, a Resolver, a
+	// provider func or a Lazy.
+	FieldFromPsyringe
+	// FieldInjectedInto is an embedded struct, or a struct injected into by
+	// InjectDeep, whose own fields are reported after it.
+	FieldInjectedInto
+	// FieldFromHook is a field with no injection type, left to the
+	// NoValueForStructField hook.
+	FieldFromHook
+	// FieldSkipped is a field not injected, for the reason given.
+	FieldSkipped
+)
+
+var fieldSourceNames = map[FieldSource]string{
+	FieldFromValue:       "value",
+	FieldFromConstructor: "constructor",
+	FieldFromPsyringe:    "psyringe",
+	FieldInjectedInto:    "injected into",
+	FieldFromHook:        "hook",
+	FieldSkipped:         "skipped",
+}
+
+// String returns a short name for s, like "constructor".
+func (s FieldSource) String() string {
+	if name, ok := fieldSourceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("FieldSource(%d)", int(s))
+}
+
+// MarshalText marshals s as its String, so that it reads well in JSON.
+func (s FieldSource) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText is the inverse of MarshalText.
+func (s *FieldSource) UnmarshalText(text []byte) error {
+	for source, name := range fieldSourceNames {
+		if name == string(text) {
+			*s = source
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown field source %q", text)
+}
+
+// String returns a table with a row for each field, like:
+//
+//	*main.Server (1.3ms)
+//	FIELD   TYPE     SOURCE                INJECTION TYPE  CONSTRUCTED  ELAPSED
+//	DB      *sql.DB  constructor           *sql.DB         yes          1.2ms
+//	secret  string   skipped (unexported)                               0s
+//
+// followed by a line for each warning, like "warning: *main.Cache: cache is
+// cold".
+func (r InjectionReport) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s (%s)\n", r.Target, r.Elapsed)
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tTYPE\tSOURCE\tINJECTION TYPE\tCONSTRUCTED\tELAPSED")
+	for _, f := range r.Fields {
+		field := f.Field
+		if f.Target != r.Target {
+			field = strings.TrimPrefix(f.Target, "*") + "." + f.Field
+		}
+		if f.Name != "" {
+			field += fmt.Sprintf(" (named %q)", f.Name)
+		}
+		source := f.Source.String()
+		switch {
+		case f.Err != "":
+			source += ": " + f.Err
+		case f.Reason != "":
+			source += " (" + f.Reason + ")"
+		}
+		constructed := ""
+		if f.Source == FieldFromConstructor {
+			constructed = "no"
+			if f.Constructed {
+				constructed = "yes"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", field, f.Type, source, f.InjectionType, constructed, f.Elapsed)
+	}
+	w.Flush()
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&buf, "warning: %s\n", warning)
+	}
+	return buf.String()
+}
+
+// InjectWithReport injects into target as Inject does, and returns a report
+// of where the value of each field came from. Injection is sequential, as
+// with SetSequential, so that each constructor call is attributed to the first
+// field needing it, and each field's elapsed time is its own.
+//
+// Inject itself does no reporting, so it is no slower for this existing.
+func (p *Psyringe) InjectWithReport(target interface{}) (InjectionReport, error) {
+	unlock := p.rlockChain()
+	report := &injectReport{}
+	ctx := context.WithValue(context.Background(), limiterKey{}, make(limiter))
+	ctx = context.WithValue(ctx, warningsKey{}, report)
+	start := time.Now()
+	err := p.injectTargets(ctx, []interface{}{target}, unlock, func(target interface{}) []error {
+		return p.inject(ctx, target, injectMode{report: report})
+	})
+	return InjectionReport{
+		Target:   fmt.Sprintf("%T", target),
+		Fields:   report.fields,
+		Warnings: report.warnings,
+		Elapsed:  time.Since(start),
+	}, err
+}
+
+// injectReport collects the FieldReports for InjectWithReport.
+type injectReport struct {
+	sync.Mutex
+	fields   []FieldReport
+	warnings []string
+}
+
+// injectField injects the field f as Psyringe.injectField does, recording
+// how in a FieldReport.
+func (r *injectReport) injectField(ctx context.Context, p *Psyringe, parentName string, f reflect.Value, fp *fieldPlan, mode injectMode) error {
+	fr := &FieldReport{
+		Target: fp.parent.String(),
+		Field:  fp.field.Name,
+		Type:   fp.field.Type.String(),
+		Name:   fp.name,
+	}
+	cached := false
+	if fp.source != nil {
+		if c := fp.source.Ctor; c != nil {
+			fr.InjectionType = c.outType.String()
+			cached = !c.transient && p.ancestor(fp.depth).calls.finished(c.ctorFunc)
+		} else {
+			fr.InjectionType = fp.source.Value.Type().String()
+		}
+	}
+	r.Lock()
+	i := len(r.fields)
+	r.fields = append(r.fields, FieldReport{})
+	r.Unlock()
+	mode.field = fr
+	start := time.Now()
+	err := p.injectField(ctx, parentName, f, fp, mode)
+	fr.Elapsed = time.Since(start)
+	if err != nil {
+		fr.Err = err.Error()
+	}
+	if fr.Source != FieldFromValue && fr.Source != FieldFromConstructor {
+		fr.InjectionType = ""
+	}
+	fr.Constructed = fr.Source == FieldFromConstructor && err == nil && !cached
+	r.Lock()
+	r.fields[i] = *fr
+	r.Unlock()
+	return err
+}
+
+// source records where the value of the field being injected came from, if
+// mode is reporting, see InjectWithReport.
+func (mode injectMode) source(source FieldSource, reason string) {
+	if mode.field != nil {
+		mode.field.Source, mode.field.Reason = source, reason
+	}
+}
@@ -0,0 +1,84 @@
+package psyringe
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	reportConn   struct{}
+	reportName   string
+	ReportInner  struct{ Name reportName }
+	reportTarget struct {
+		ReportInner
+		Conn    *reportConn
+		Again   *reportConn
+		Name    reportName
+		Missing int
+		Skipped reportName `inject:"-"`
+		hidden  reportName
+	}
+)
+
+func TestPsyringe_InjectWithReport(t *testing.T) {
+	p := New(func() *reportConn { return &reportConn{} }, reportName("name"))
+	target := &reportTarget{}
+	report, err := p.InjectWithReport(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Conn == nil || target.Name != "name" || target.ReportInner.Name != "name" {
+		t.Fatalf("target not injected: %+v", target)
+	}
+	var got []string
+	for _, f := range report.Fields {
+		f.Elapsed = 0
+		got = append(got, fmt.Sprintf("%s.%s %s %q %t %q", f.Target, f.Field, f.Source, f.InjectionType, f.Constructed, f.Reason))
+	}
+	want := []string{
+		`*psyringe.reportTarget.ReportInner injected into "" false ""`,
+		`*psyringe.ReportInner.Name value "psyringe.reportName" false ""`,
+		`*psyringe.reportTarget.Conn constructor "*psyringe.reportConn" true ""`,
+		`*psyringe.reportTarget.Again constructor "*psyringe.reportConn" false ""`,
+		`*psyringe.reportTarget.Name value "psyringe.reportName" false ""`,
+		`*psyringe.reportTarget.Missing hook "" false ""`,
+		`*psyringe.reportTarget.Skipped skipped "" false "tagged inject:\"-\""`,
+		`*psyringe.reportTarget.hidden skipped "" false "unexported"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if s := report.String(); !strings.Contains(s, "ReportInner.Name") || !strings.Contains(s, "skipped (unexported)") {
+		t.Errorf("report table missing rows:\n%s", s)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Source":"constructor"`) {
+		t.Errorf("got JSON %s; want sources by name", data)
+	}
+	var decoded InjectionReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, report) {
+		t.Errorf("got %+v after JSON round trip; want %+v", decoded, report)
+	}
+}
+
+func TestPsyringe_InjectWithReport_error(t *testing.T) {
+	p := New(func() (*reportConn, error) { return nil, fmt.Errorf("no connection") })
+	var target struct{ Conn *reportConn }
+	report, err := p.InjectWithReport(&target)
+	if want := p.Inject(&target); err == nil || err.Error() != want.Error() {
+		t.Errorf("got error %v; want %v", err, want)
+	}
+	if len(report.Fields) != 1 || report.Fields[0].Err == "" || report.Fields[0].Constructed {
+		t.Errorf("got %+v; want one field with an error", report.Fields)
+	}
+}
@@ -0,0 +1,129 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// Reset discards every value and error this Psyringe's constructors have
+// produced, so that each constructor is called again next time its value is
+// needed. Everything added is kept. Reset waits for any calls to Inject and
+// the like already in progress to finish.
+//
+// Discarded values are not closed, call Close first if they need to be. Values
+// belonging to parent scopes are not discarded.
+func (p *Psyringe) Reset() {
+	defer p.lock()()
+	calls := newCtorCalls()
+	calls.injected = atomic.LoadInt32(&p.calls.injected)
+	p.calls = calls
+}
+
+// ResetType is like Reset, but only discards the value of a single injection
+// type, and of every constructor which depends on it directly or
+// transitively. forType is either a reflect.Type, or an example value of the
+// injection type. It returns an error if the injection type was not added to
+// this Psyringe.
+func (p *Psyringe) ResetType(forType interface{}) error {
+	return p.resetType("reset", forType, false)
+}
+
+// Invalidate is like ResetType, for an injection type added by a constructor
+// whose value has gone stale, such as rotated credentials: its constructor,
+// and every constructor whose value depended on it, directly or transitively,
+// are called again next time their values are needed. The example is either a
+// reflect.Type, or an example value of the injection type. It returns an error
+// if the injection type was not added to this Psyringe, or was added as a
+// value, since there is no constructor to call again.
+//
+// Like Reset, Invalidate waits for any calls to Inject and the like already in
+// progress to finish, so they never see a constructor half invalidated.
+func (p *Psyringe) Invalidate(example interface{}) error {
+	return p.resetType("invalidate", example, true)
+}
+
+// resetType does the work of ResetType, and of Invalidate if ctorOnly is true.
+// verb names the method in errors.
+func (p *Psyringe) resetType(verb string, forType interface{}, ctorOnly bool) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	if forType == nil {
+		return fmt.Errorf("cannot %s nil", verb)
+	}
+	t, ok := forType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(forType)
+	}
+	it, ok := p.injectionTypesWithSlices()[t]
+	if !ok {
+		return fmt.Errorf("cannot %s injection type %s: not added", verb, t)
+	}
+	if ctorOnly && it.Ctor == nil {
+		return fmt.Errorf("cannot %s injection type %s: added as a value", verb, t)
+	}
+	resetTypes := map[reflect.Type]bool{t: true}
+	reset := map[*ctorFunc]bool{}
+	if it.Ctor != nil {
+		reset[it.Ctor.ctorFunc] = true
+		for _, out := range it.Ctor.outTypes {
+			resetTypes[out] = true
+		}
+	}
+	p.resetDependents(resetTypes, reset)
+	return nil
+}
+
+// resetDependents discards the calls of each constructor in reset, and of every
+// constructor which depends on any of resetTypes, directly or transitively. p
+// must be write-locked.
+func (p *Psyringe) resetDependents(resetTypes map[reflect.Type]bool, reset map[*ctorFunc]bool) {
+	ctors := p.injectionTypesWithSlices().AddedAsCtors()
+	for changed := true; changed; {
+		changed = false
+		for _, it := range ctors {
+			c := it.Ctor
+			if reset[c.ctorFunc] || !p.dependsOnAny(c, resetTypes) {
+				continue
+			}
+			reset[c.ctorFunc] = true
+			changed = true
+			for _, out := range c.outTypes {
+				resetTypes[out] = true
+			}
+		}
+	}
+	// Named values are never constructor parameters, so named constructors
+	// are reset only if they depend on a type being reset.
+	for _, its := range p.named {
+		for _, it := range its.AddedAsCtors() {
+			if p.dependsOnAny(it.Ctor, resetTypes) {
+				reset[it.Ctor.ctorFunc] = true
+			}
+		}
+	}
+	p.calls.discard(reset)
+}
+
+// dependsOnAny reports whether any parameter of c is satisfied by one of
+// types.
+func (p *Psyringe) dependsOnAny(c *ctor, types map[reflect.Type]bool) bool {
+	for _, in := range c.inTypes {
+		if types[in] {
+			return true
+		}
+		if p.injectionTypes.Contains(in) {
+			continue
+		}
+		if bound, err := p.boundType(in, p.injectionTypes); err == nil && types[bound] {
+			return true
+		}
+		// With pointer adaptation, in may be satisfied by *in or its element.
+		if p.adaptPointers && (types[reflect.PtrTo(in)] || (in.Kind() == reflect.Ptr && types[in.Elem()])) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPsyringe_Reset(t *testing.T) {
+	var intCalls, stringCalls Counter
+	p := New(
+		func() int { return int(intCalls.Increment()) },
+		func(i int) string { stringCalls.Increment(); return strings.Repeat("a", i) },
+	)
+	var d dependent
+	p.MustInject(&d)
+	p.MustInject(&d)
+	if intCalls.Value() != 1 || stringCalls.Value() != 1 {
+		t.Fatalf("got %d int calls and %d string calls; want 1 and 1", intCalls.Value(), stringCalls.Value())
+	}
+
+	p.Reset()
+	p.MustInject(&d)
+	if intCalls.Value() != 2 || stringCalls.Value() != 2 {
+		t.Errorf("got %d int calls and %d string calls; want 2 and 2", intCalls.Value(), stringCalls.Value())
+	}
+	if d.Int != 2 || d.String != "aa" {
+		t.Errorf("got %+v", d)
+	}
+}
+
+func TestPsyringe_ResetType(t *testing.T) {
+	var intCalls, stringCalls, bufferCalls, namedCalls Counter
+	p := New(
+		func() int { return int(intCalls.Increment()) },
+		func(i int) string { stringCalls.Increment(); return strings.Repeat("a", i) },
+		func(s string) *bytes.Buffer { bufferCalls.Increment(); return bytes.NewBufferString(s) },
+	)
+	p.AddNamed("n", func(i int) string { namedCalls.Increment(); return "named" })
+	var d dependent
+	var n struct {
+		String string `psyringe:"n"`
+	}
+	p.MustInject(&d, &n)
+
+	// Resetting string resets the buffer constructor that depends on it, but
+	// not int, nor the named string.
+	if err := p.ResetType(""); err != nil {
+		t.Fatal(err)
+	}
+	p.MustInject(&d, &n)
+	for name, c := range map[string]struct {
+		got  *Counter
+		want int64
+	}{
+		"int":           {&intCalls, 1},
+		"string":        {&stringCalls, 2},
+		"*bytes.Buffer": {&bufferCalls, 2},
+		"named string":  {&namedCalls, 1},
+	} {
+		if c.got.Value() != c.want {
+			t.Errorf("%s constructor called %d times; want %d", name, c.got.Value(), c.want)
+		}
+	}
+
+	// Resetting int resets everything depending on it, transitively.
+	if err := p.ResetType(0); err != nil {
+		t.Fatal(err)
+	}
+	p.MustInject(&d, &n)
+	if intCalls.Value() != 2 || stringCalls.Value() != 3 || bufferCalls.Value() != 3 || namedCalls.Value() != 2 {
+		t.Errorf("got calls int %d, string %d, *bytes.Buffer %d, named %d; want 2, 3, 3, 2",
+			intCalls.Value(), stringCalls.Value(), bufferCalls.Value(), namedCalls.Value())
+	}
+	if d.Buffer.String() != "aa" {
+		t.Errorf("got buffer %q; want %q", d.Buffer, "aa")
+	}
+
+	const want = "cannot reset injection type float64: not added"
+	if err := p.ResetType(1.0); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestPsyringe_Reset_concurrent(t *testing.T) {
+	var calls Counter
+	p := New(func() int { return int(calls.Increment()) })
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var d dependent
+			p.MustInject(&d)
+			if d.Int == 0 {
+				t.Errorf("int not injected")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			p.Reset()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPsyringe_Invalidate(t *testing.T) {
+	var credCalls, clientCalls Counter
+	p := New(
+		func() int { return int(credCalls.Increment()) },
+		func(i int) string { clientCalls.Increment(); return strings.Repeat("a", i) },
+		1.5,
+	)
+	var d dependent
+	p.MustInject(&d)
+	if err := p.Invalidate(reflect.TypeOf(0)); err != nil {
+		t.Fatal(err)
+	}
+	p.MustInject(&d)
+	if credCalls.Value() != 2 || clientCalls.Value() != 2 {
+		t.Errorf("got calls int %d, string %d; want 2, 2", credCalls.Value(), clientCalls.Value())
+	}
+	if d.String != "aa" {
+		t.Errorf("got string %q; want %q", d.String, "aa")
+	}
+
+	const want = "cannot invalidate injection type float64: added as a value"
+	if err := p.Invalidate(1.0); err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestPsyringe_Invalidate_concurrent(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() int { return int(calls.Increment()) },
+		func(i int) string { return strings.Repeat("a", i) },
+	)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var d dependent
+			p.MustInject(&d)
+			// The string is always built from the int injected with it.
+			if len(d.String) != d.Int {
+				t.Errorf("got string %q with int %d", d.String, d.Int)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := p.Invalidate(0); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+package psyringe
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// rootScopeName is the name of a root Psyringe not named by WithName.
+const rootScopeName = "<root>"
+
+// ScopeName returns the name this Psyringe was given by Scope, or "<root>" if
+// it was not created by Scope, unless named by WithName.
+func (p *Psyringe) ScopeName() string {
+	return p.scope
+}
+
+// Parent returns the Psyringe this one was created from by Scope, or nil if it
+// was not created by Scope.
+func (p *Psyringe) Parent() *Psyringe {
+	return p.parent
+}
+
+// ScopePath returns the names of the scopes from the root Psyringe down to
+// this one, starting with "<root>".
+func (p *Psyringe) ScopePath() []string {
+	var path []string
+	for q := p; q != nil; q = q.parent {
+		path = append([]string{q.scope}, path...)
+	}
+	return path
+}
+
+// scopePath returns ScopePath formatted for errors, like "<root>/child".
+func (p *Psyringe) scopePath() string {
+	return strings.Join(p.ScopePath(), "/")
+}
+
+// errScope returns scopePath if p is a child scope, or a root named by
+// WithName, or "" otherwise, so that errors only mention scopes when there is
+// more than one, or the root has a name.
+func (p *Psyringe) errScope() string {
+	if p.parent == nil && p.scope == rootScopeName {
+		return ""
+	}
+	return p.scopePath()
+}
+
+// scopeRegistry records the child scopes created from a Psyringe, so they can
+// be found by name, see GetScope.
+type scopeRegistry struct {
+	sync.Mutex
+	children map[string]*Psyringe
+	// clonedFrom is the registry of the Psyringe this one's was cloned from,
+	// if any, whose children are re-parented on demand.
+	clonedFrom *scopeRegistry
+	// fresh is set for the registry of a Psyringe returned by CloneFresh,
+	// whose children re-parented from its clonedFrom start fresh too.
+	fresh bool
+}
+
+func newScopeRegistry(clonedFrom *scopeRegistry) *scopeRegistry {
+	return &scopeRegistry{children: map[string]*Psyringe{}, clonedFrom: clonedFrom}
+}
+
+// get returns the child named name, or nil if there is none.
+func (r *scopeRegistry) get(name string) *Psyringe {
+	r.Lock()
+	defer r.Unlock()
+	return r.children[name]
+}
+
+// names returns the names of the children in r, and those it was cloned from.
+func (r *scopeRegistry) names(into map[string]bool) {
+	r.Lock()
+	for name := range r.children {
+		into[name] = true
+	}
+	r.Unlock()
+	if r.clonedFrom != nil {
+		r.clonedFrom.names(into)
+	}
+}
+
+// GetScope returns the child scope created from p by Scope with name. If more
+// than one was created with the same name, the latest is returned; p keeps a
+// reference to only the latest child of each name.
+//
+// For a clone of p, scopes created from p are found too. They are returned as
+// clones of the original scope whose parent is the clone, so that injecting
+// through them uses the clone's values, and the same one is returned each time.
+func (p *Psyringe) GetScope(name string) (*Psyringe, bool) {
+	if child := p.scopes.get(name); child != nil {
+		return child, true
+	}
+	var original *Psyringe
+	fresh := p.scopes.fresh
+	for r := p.scopes.clonedFrom; r != nil && original == nil; r = r.clonedFrom {
+		if original = r.get(name); original == nil {
+			fresh = fresh || r.fresh
+		}
+	}
+	if original == nil {
+		return nil, false
+	}
+	p.scopes.Lock()
+	defer p.scopes.Unlock()
+	if child, ok := p.scopes.children[name]; ok {
+		// Re-parented concurrently.
+		return child, true
+	}
+	child := original.Clone()
+	if fresh {
+		child.calls = newCtorCalls()
+	}
+	child.parent = p
+	// The original's plans were made for its own parent.
+	child.plans = newTargetPlans()
+	p.scopes.children[name] = child
+	return child, true
+}
+
+// Scopes returns the names of the child scopes created from p by Scope, and
+// for a clone, from the Psyringe it was cloned from, sorted.
+func (p *Psyringe) Scopes() []string {
+	found := map[string]bool{}
+	p.scopes.names(found)
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
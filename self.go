@@ -0,0 +1,156 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// Resolver gets values from a Psyringe after it has finished injecting, for
+// components which resolve more values themselves, like a plugin registry
+// injecting into plugins as they load. See AddSelf.
+type Resolver interface {
+	// Inject is like Psyringe.Inject.
+	Inject(targets ...interface{}) error
+	// Realise is like Psyringe.Realise.
+	Realise(targets ...interface{}) error
+	// Has is like Psyringe.Has.
+	Has(example interface{}) bool
+}
+
+// tresolver is the type "psyringe.Resolver".
+var tresolver = reflect.TypeOf((*Resolver)(nil)).Elem()
+
+// AddSelf makes a Resolver for p available as injection type Resolver, unless
+// a value or constructor for Resolver is added. It is inherited by clones and
+// scopes, each of which injects a Resolver for itself: fields get one for the
+// Psyringe injecting them, and constructor parameters one for the Psyringe
+// the constructor was added to, or its clone.
+//
+// A constructor may call its Resolver before returning, but if doing so needs
+// the constructor's own value, or that of anything depending on it, the call
+// fails with a DependencyCycle rather than waiting forever.
+func (p *Psyringe) AddSelf() {
+	defer p.lock()()
+	p.self = true
+	_, file, line, _ := runtime.Caller(1)
+	p.debug(DebugEvent{Kind: DebugAdded, Type: tresolver, Location: fmt.Sprintf("%s:%d", file, line)})
+}
+
+// constructingKey is the context key under which manifest stores the
+// constructing value for a call to a constructor.
+type constructingKey struct{}
+
+// constructing records a constructor being called, so that values got for it,
+// including by a Resolver passed to it, can detect cycles, and a Resolver
+// knows whether the caller holds locks.
+type constructing struct {
+	// path is the chain of constructors being called, ending with the one
+	// being called.
+	path []*ctor
+	// call is the call of the constructor ending path, whose done channel is
+	// closed when the constructor has returned.
+	call *ctorCall
+}
+
+// withConstructing returns ctx carrying the constructor call ending path.
+func (p *Psyringe) withConstructing(ctx context.Context, path []*ctor, call *ctorCall) context.Context {
+	return context.WithValue(ctx, constructingKey{}, constructing{path: path, call: call})
+}
+
+// ctorPath returns the path of constructors ctx was passed through to a
+// Resolver, or nil.
+func ctorPath(ctx context.Context) []*ctor {
+	c, _ := ctx.Value(constructingKey{}).(constructing)
+	return c.path
+}
+
+// constructingCall returns the call of the constructor ctx was passed
+// through, or nil.
+func constructingCall(ctx context.Context) *ctorCall {
+	c, _ := ctx.Value(constructingKey{}).(constructing)
+	return c.call
+}
+
+// implicitValue returns the value of t provided by p without being added,
+// see WithContextValues and AddSelf.
+func (p *Psyringe) implicitValue(ctx context.Context, t reflect.Type) (reflect.Value, bool) {
+	if v, ok := p.contextValue(ctx, t); ok {
+		return v, true
+	}
+	return p.selfValue(ctx, t)
+}
+
+// resolver is the Resolver injected by a Psyringe with AddSelf.
+type resolver struct {
+	p *Psyringe
+	// in is the constructor call the resolver was passed to, if any.
+	in constructing
+}
+
+// selfValue returns a Resolver for p, if p has AddSelf and t is Resolver.
+func (p *Psyringe) selfValue(ctx context.Context, t reflect.Type) (reflect.Value, bool) {
+	if !p.providesSelf(t) {
+		return reflect.Value{}, false
+	}
+	c, _ := ctx.Value(constructingKey{}).(constructing)
+	var r Resolver = &resolver{p: p, in: c}
+	return reflect.ValueOf(&r).Elem(), true
+}
+
+// providesSelf reports whether p provides a Resolver for t, see AddSelf.
+func (p *Psyringe) providesSelf(t reflect.Type) bool {
+	return p.self && t == tresolver
+}
+
+// constructing reports whether the constructor r was passed to has not yet
+// returned, in which case its caller holds p's locks.
+func (r *resolver) constructing() bool {
+	if r.in.call == nil {
+		return false
+	}
+	select {
+	case <-r.in.call.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// ctx returns the context to resolve values with, carrying the path of
+// constructors being called if r's constructor has not yet returned.
+func (r *resolver) ctx() context.Context {
+	if !r.constructing() {
+		return context.Background()
+	}
+	return context.WithValue(context.Background(), constructingKey{}, r.in)
+}
+
+func (r *resolver) Inject(targets ...interface{}) error {
+	if !r.constructing() {
+		return r.p.Inject(targets...)
+	}
+	// The caller holds p's locks, so AfterInject runs with them held.
+	ctx := r.p.limited(r.ctx())
+	return r.p.forEachTargetThen(ctx, "inject into", targets, func(target interface{}) []error {
+		return r.p.inject(ctx, target, injectMode{})
+	}, nil, (*Psyringe).afterInject)
+}
+
+func (r *resolver) Realise(targets ...interface{}) error {
+	if !r.constructing() {
+		return r.p.Realise(targets...)
+	}
+	ctx := r.p.limited(r.ctx())
+	return r.p.forEachTarget(ctx, "realise into", targets, func(target interface{}) []error {
+		return r.p.realise(ctx, target)
+	})
+}
+
+func (r *resolver) Has(example interface{}) bool {
+	if !r.constructing() {
+		return r.p.Has(example)
+	}
+	return example != nil && r.p.has(reflect.TypeOf(example))
+}
@@ -0,0 +1,96 @@
+package psyringe
+
+import (
+	"errors"
+	"testing"
+)
+
+type (
+	plugin   struct{ Name string }
+	registry struct {
+		r       Resolver
+		plugins []*plugin
+	}
+)
+
+func (reg *registry) load() error {
+	p := &plugin{}
+	if err := reg.r.Inject(p); err != nil {
+		return err
+	}
+	reg.plugins = append(reg.plugins, p)
+	return nil
+}
+
+func TestPsyringe_AddSelf(t *testing.T) {
+	p := New(func(r Resolver) *registry { return &registry{r: r} }, "plugin name")
+	p.AddSelf()
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	if !p.HasType(tresolver) {
+		t.Errorf("Resolver not reported as known")
+	}
+	var target struct{ Registry *registry }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.Registry.load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := target.Registry.plugins[0].Name; got != "plugin name" {
+		t.Errorf("got plugin name %q; want %q", got, "plugin name")
+	}
+}
+
+func TestPsyringe_AddSelf_cloneAndScope(t *testing.T) {
+	p := New()
+	p.AddSelf()
+	q := p.Clone()
+	child := q.Scope("child")
+	type withResolver struct{ R Resolver }
+	for _, want := range []*Psyringe{p, q, child} {
+		var target withResolver
+		if err := want.Inject(&target); err != nil {
+			t.Fatal(err)
+		}
+		if got := target.R.(*resolver).p; got != want {
+			t.Errorf("got a Resolver for %s; want one for %s", got.scopePath(), want.scopePath())
+		}
+	}
+}
+
+func TestPsyringe_AddSelf_addedResolverWins(t *testing.T) {
+	added := &resolver{p: New()}
+	p := New(Bind((*Resolver)(nil), added))
+	p.AddSelf()
+	var target struct{ R Resolver }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.R != added {
+		t.Errorf("got a Resolver other than the one added")
+	}
+}
+
+func TestPsyringe_AddSelf_cycle(t *testing.T) {
+	type selfInjecting struct{}
+	p := New(func(r Resolver) (selfInjecting, error) {
+		var target struct{ S selfInjecting }
+		return selfInjecting{}, r.Inject(&target)
+	})
+	p.AddSelf()
+	var target struct{ S selfInjecting }
+	err := p.Inject(&target)
+	var dc DependencyCycle
+	if !errors.As(err, &dc) {
+		t.Fatalf("got error %v; want a DependencyCycle", err)
+	}
+}
+
+func TestPsyringe_AddSelf_off(t *testing.T) {
+	p := New(func(r Resolver) *registry { return &registry{r: r} })
+	if err := p.Test(); err == nil {
+		t.Errorf("got nil error; want Resolver parameter to be unsatisfied")
+	}
+}
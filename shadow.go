@@ -0,0 +1,38 @@
+package psyringe
+
+import "fmt"
+
+// AddShadow is like AddErr, but constructors and values whose injection types
+// were added to a parent scope shadow the parent's, rather than being an
+// error. Injecting through this Psyringe, or any of its own child scopes, then
+// uses its own constructor or value, including for the parameters of its own
+// constructors, while the parent, and constructors added to the parent, keep
+// using the parent's.
+//
+// It is still an error to add an injection type already added to this
+// Psyringe. If any error is returned, nothing is added.
+func (p *Psyringe) AddShadow(constructorsAndValues ...interface{}) error {
+	return p.addShadow(constructorsAndValues...)
+}
+
+// addShadow just exists to make callerinfo consistent in Psyringe.add.
+func (p *Psyringe) addShadow(constructorsAndValues ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	p.overriding = true
+	defer func() { p.overriding = false }()
+	for i, thing := range constructorsAndValues {
+		if thing == nil {
+			rollback()
+			return fmt.Errorf("cannot add nil (argument %d)", i)
+		}
+		if err := p.add(thing); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
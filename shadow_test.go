@@ -0,0 +1,68 @@
+package psyringe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_AddShadow(t *testing.T) {
+	type Greeting string
+	var parentCalls Counter
+	parent := New(
+		"parent",
+		func(s string) Greeting { parentCalls.Increment(); return Greeting("hello " + s) },
+	)
+	child := parent.Scope("child")
+	if err := child.AddShadow("child"); err != nil {
+		t.Fatal(err)
+	}
+	child.Add(func(s string) int { return len(s) })
+
+	var target struct {
+		Greeting Greeting
+		String   string
+		Int      int
+	}
+	child.MustInject(&target)
+	// The child's own constructor gets the child's string, but the parent's
+	// constructor keeps getting the parent's.
+	if target.String != "child" || target.Int != 5 || target.Greeting != "hello parent" {
+		t.Errorf("child: got %+v", target)
+	}
+	var parentTarget struct{ String string }
+	parent.MustInject(&parentTarget)
+	if parentTarget.String != "parent" {
+		t.Errorf("parent: got %+v", parentTarget)
+	}
+	if parentCalls.Value() != 1 {
+		t.Errorf("parent constructor called %d times; want 1", parentCalls.Value())
+	}
+
+	// Grandchild scopes see the nearest shadow.
+	var grandchildTarget struct{ String string }
+	child.Scope("grandchild").MustInject(&grandchildTarget)
+	if grandchildTarget.String != "child" {
+		t.Errorf("grandchild: got %+v", grandchildTarget)
+	}
+}
+
+func TestPsyringe_AddShadow_Test(t *testing.T) {
+	parent := New(func(float64) string { return "" })
+	child := parent.Scope("child")
+	if err := child.AddShadow(func(int) string { return "" }, 1); err != nil {
+		t.Fatal(err)
+	}
+	child.Add(func(s string) int8 { return 0 })
+	// The child is tested against its own view, where string is satisfied.
+	if err := child.Test(); err != nil {
+		t.Errorf("got error %v; want nil", err)
+	}
+	if err := parent.Test(); err == nil {
+		t.Errorf("got nil error testing parent; want float64 unsatisfied")
+	}
+
+	err := child.AddShadow(2)
+	if err == nil || !strings.Contains(err.Error(), "injection type int already registered at") {
+		t.Errorf("got error %v; want int already registered", err)
+	}
+}
@@ -0,0 +1,273 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// sliceType is an injection type []T made up of everything added to a
+// Psyringe by AddToSlice with injection type T. Like injectionType, it never
+// changes once created; adding another element replaces it.
+type sliceType struct {
+	it *injectionType
+	// elements are the constructors and values of each element, in the order
+	// they were added.
+	elements []sliceElement
+}
+
+// sliceElement is a single element of a sliceType. Exactly one of ctor and
+// value is set.
+type sliceElement struct {
+	ctor  *ctor
+	value reflect.Value
+}
+
+// AddToSlice adds constructors and values as elements of the injection type
+// []T, where T is the injection type each would have if passed to Add. Unlike
+// with Add, any number of constructors and values may have the same injection
+// type T. Fields and constructor parameters of type []T then get every element
+// added, in the order they were added, constructing them all together the
+// first time the slice is needed. Use Bind to add elements of an interface
+// type.
+//
+// A value or constructor for []T added with Add takes precedence over the
+// elements. Elements added to a child scope make up a separate slice, which
+// shadows any elements added to its parents.
+//
+// Constructors added to a slice must have a single output, and must not
+// return a cleanup func; Close closes each element implementing io.Closer
+// instead. Test checks the parameters of each element's constructor.
+func (p *Psyringe) AddToSlice(constructorsAndValues ...interface{}) error {
+	return p.addToSliceErr(constructorsAndValues...)
+}
+
+// addToSliceErr just exists to make callerinfo consistent in
+// Psyringe.addToSlice.
+func (p *Psyringe) addToSliceErr(constructorsAndValues ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	for i, thing := range constructorsAndValues {
+		if thing == nil {
+			rollback()
+			return fmt.Errorf("cannot add nil (argument %d)", i)
+		}
+		if err := p.addToSlice(thing); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Psyringe) addToSlice(thing interface{}) error {
+	el, t, err := newSliceElement(thing)
+	if err != nil {
+		return errors.Wrapf(err, "adding %T to slice failed", thing)
+	}
+	st := reflect.SliceOf(t)
+	if err := p.checkAddAfterInject(st); err != nil {
+		return errors.Wrapf(err, "adding %T to slice failed", thing)
+	}
+	var elements []sliceElement
+	if existing, ok := p.slices[st]; ok {
+		elements = append(elements, existing.elements...)
+	}
+	elements = append(elements, el)
+	_, file, line, _ := runtime.Caller(3)
+	s := &sliceType{
+		it: &injectionType{
+			Ctor:               newSliceCtor(st, elements),
+			DebugAddedLocation: fmt.Sprintf("%s:%d", file, line),
+			Module:             p.addingModule,
+		},
+		elements: elements,
+	}
+	previous := p.slices[st]
+	p.setSlice(st, s)
+	p.debug(addedEvent(st, "", s.it))
+	if p.allowAddCycle {
+		return nil
+	}
+	if err := p.detectCycle(s.it.Ctor); err != nil {
+		p.setSlice(st, previous)
+		return errors.Wrapf(err, "adding %T to slice %s failed", thing, st)
+	}
+	return nil
+}
+
+// merged returns a sliceType of slice type st with the elements of s followed
+// by those of other, added where s was, see Merge.
+func (s *sliceType) merged(st reflect.Type, other *sliceType) *sliceType {
+	elements := append(append([]sliceElement(nil), s.elements...), other.elements...)
+	return &sliceType{
+		it: &injectionType{
+			Ctor:               newSliceCtor(st, elements),
+			DebugAddedLocation: s.it.DebugAddedLocation,
+			Module:             s.it.Module,
+		},
+		elements: elements,
+	}
+}
+
+// newSliceElement returns the element for a constructor, value or Binding
+// passed to AddToSlice, and its type.
+func newSliceElement(thing interface{}) (sliceElement, reflect.Type, error) {
+	var c *ctor
+	var v reflect.Value
+	if b, ok := thing.(Binding); ok {
+		var err error
+		if c, v, err = b.bound(); err != nil {
+			return sliceElement{}, nil, err
+		}
+	} else {
+		v = reflect.ValueOf(thing)
+		ctors := newCtors(v.Type(), v)
+		if len(ctors) > 1 {
+			return sliceElement{}, nil, fmt.Errorf("constructor has %d outputs", len(ctors))
+		}
+		if len(ctors) == 1 {
+			c = ctors[0]
+		}
+	}
+	if c == nil {
+		return sliceElement{value: v}, v.Type(), nil
+	}
+	if c.returnsCleanup {
+		return sliceElement{}, nil, fmt.Errorf("constructors added to a slice cannot return a cleanup func")
+	}
+	return sliceElement{ctor: c}, c.outType, nil
+}
+
+// newSliceCtor returns a ctor for slice type st, whose parameters are those of
+// each element's constructor in turn. It calls each constructor and returns
+// the slice of elements, followed by each element again so that Close can
+// close them.
+func newSliceCtor(st reflect.Type, elements []sliceElement) *ctor {
+	var inTypes []reflect.Type
+	var optionalIn []bool
+	for _, el := range elements {
+		if el.ctor == nil {
+			continue
+		}
+		inTypes = append(inTypes, el.ctor.inTypes...)
+		for i := range el.ctor.inTypes {
+			optionalIn = append(optionalIn, el.ctor.optional(i))
+		}
+	}
+	construct := func(ctx context.Context, in []reflect.Value) ([]reflect.Value, func(), error) {
+		slice := reflect.MakeSlice(st, len(elements), len(elements))
+		for i, el := range elements {
+			if el.ctor == nil {
+				slice.Index(i).Set(el.value)
+				continue
+			}
+			n := len(el.ctor.inTypes)
+			out, _, err := el.ctor.construct(ctx, in[:n])
+			in = in[n:]
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "element %d (%s) failed", i, el.ctor.funcType)
+			}
+			slice.Index(i).Set(out[0])
+		}
+		values := []reflect.Value{slice}
+		for i, el := range elements {
+			if el.ctor != nil {
+				values = append(values, slice.Index(i))
+			}
+		}
+		return values, nil, nil
+	}
+	return &ctor{
+		funcType: reflect.FuncOf(inTypes, []reflect.Type{st}, false),
+		outType:  st,
+		inTypes:  inTypes,
+		ctorFunc: &ctorFunc{
+			outTypes:   []reflect.Type{st},
+			construct:  construct,
+			optionalIn: optionalIn,
+		},
+	}
+}
+
+// testSlices adds a problem to report for each parameter of each slice
+// element's constructor which cannot be satisfied.
+func (p *Psyringe) testSlices(report *TestReport) {
+	for _, st := range p.slices.Keys() {
+		for i, el := range p.slices[st].elements {
+			if el.ctor == nil {
+				continue
+			}
+			for _, err := range el.ctor.testParametersAreRegisteredIn(p) {
+				report.add(el.ctor, "", errors.Wrapf(err, "element %d of %s", i, st))
+			}
+		}
+	}
+}
+
+// sliceTypes holds the sliceTypes added to a Psyringe by AddToSlice, by slice
+// type.
+type sliceTypes map[reflect.Type]*sliceType
+
+// Keys returns the slice types in sts, sorted like injectionTypes.Keys.
+func (sts sliceTypes) Keys() []reflect.Type {
+	return sts.injectionTypes().Keys()
+}
+
+// Copy returns a copy of sts, sharing the same sliceType entries.
+func (sts sliceTypes) Copy() sliceTypes {
+	c := make(sliceTypes, len(sts)+1)
+	for t, s := range sts {
+		c[t] = s
+	}
+	return c
+}
+
+// hasSlice reports whether p or any of its ancestors has elements of slice
+// type t added by AddToSlice.
+func (p *Psyringe) hasSlice(t reflect.Type) bool {
+	return p.sliceScope(t) != nil
+}
+
+// sliceScope returns p, or the nearest of its ancestors, with elements of
+// slice type t added by AddToSlice, or nil if none has.
+func (p *Psyringe) sliceScope(t reflect.Type) *Psyringe {
+	for q := p; q != nil; q = q.parent {
+		if _, ok := q.slices[t]; ok {
+			return q
+		}
+	}
+	return nil
+}
+
+// injectionTypesWithSlices returns the injection types added to p, including
+// each slice type with elements added by AddToSlice, unless a value or
+// constructor of the same slice type was added, which takes precedence, see
+// find. It is for walking everything added to p; p must be read-locked.
+func (p *Psyringe) injectionTypesWithSlices() injectionTypes {
+	if len(p.slices) == 0 {
+		return p.injectionTypes
+	}
+	its := p.injectionTypes.Copy()
+	for t, s := range p.slices {
+		if !its.Contains(t) {
+			its[t] = s.it
+		}
+	}
+	return its
+}
+
+// injectionTypes returns the injection type of each slice type in sts.
+func (sts sliceTypes) injectionTypes() injectionTypes {
+	its := make(injectionTypes, len(sts))
+	for t, s := range sts {
+		its[t] = s.it
+	}
+	return its
+}
@@ -0,0 +1,219 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	checker     interface{ Check() string }
+	diskChecker struct{ path string }
+	netChecker  struct{ closed bool }
+	checkerList struct{ names []string }
+)
+
+func (c diskChecker) Check() string  { return "disk " + c.path }
+func (c *netChecker) Check() string  { return "net" }
+func (c *netChecker) Close() error   { c.closed = true; return nil }
+func (l checkerList) String() string { return strings.Join(l.names, ",") }
+
+func newCheckerList(cs []checker) checkerList {
+	var l checkerList
+	for _, c := range cs {
+		l.names = append(l.names, c.Check())
+	}
+	return l
+}
+
+func TestPsyringe_AddToSlice(t *testing.T) {
+	p := New("/tmp", newCheckerList)
+	err := p.AddToSlice(
+		Bind((*checker)(nil), func(path string) diskChecker { return diskChecker{path} }),
+		Bind((*checker)(nil), &netChecker{}),
+		Bind((*checker)(nil), diskChecker{"/"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Checkers []checker
+		List     checkerList
+	}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := target.List.String(), "disk /tmp,net,disk /"; got != want {
+		t.Errorf("got checkers %q; want %q", got, want)
+	}
+	if len(target.Checkers) != 3 {
+		t.Errorf("got %d checkers injected; want 3", len(target.Checkers))
+	}
+
+	// Adding to a clone leaves the original alone.
+	q := p.Clone()
+	if err := q.AddToSlice(Bind((*checker)(nil), diskChecker{"/home"})); err != nil {
+		t.Fatal(err)
+	}
+	for want, r := range map[int]*Psyringe{3: p, 4: q} {
+		got, err := Get[[]checker](r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != want {
+			t.Errorf("got %d checkers; want %d", len(got), want)
+		}
+	}
+}
+
+func TestPsyringe_AddToSlice_addedSliceWins(t *testing.T) {
+	added := []checker{diskChecker{"added"}}
+	p := New(added)
+	if err := p.AddToSlice(Bind((*checker)(nil), diskChecker{"element"})); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get[[]checker](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != added[0] {
+		t.Errorf("got %v; want the slice added with Add", got)
+	}
+}
+
+func TestPsyringe_AddToSlice_errors(t *testing.T) {
+	p := New()
+	if err := p.AddToSlice(func(n int) (diskChecker, error) {
+		return diskChecker{}, fmt.Errorf("no disk %d", n)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Test()
+	want := "element 0 of []psyringe.diskChecker: unable to satisfy constructor " +
+		"func(int) (psyringe.diskChecker, error): unable to satisfy param 0: no constructor or value for int"
+	if err == nil || err.Error() != want {
+		t.Errorf("got Test error %v; want %q", err, want)
+	}
+
+	p.Add(1)
+	_, err = Get[[]diskChecker](p)
+	want = "element 0 (func(int) (psyringe.diskChecker, error)) failed: no disk 1"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v; want it to contain %q", err, want)
+	}
+
+	cases := []struct {
+		thing interface{}
+		want  string
+	}{
+		{nil, "cannot add nil (argument 0)"},
+		{func() (int, string) { return 0, "" }, "adding func() (int, string) to slice failed: constructor has 2 outputs"},
+		{func() (int, func()) { return 0, nil }, "adding func() (int, func()) to slice failed: constructors added to a slice cannot return a cleanup func"},
+	}
+	for _, c := range cases {
+		if err := New().AddToSlice(c.thing); err == nil || err.Error() != c.want {
+			t.Errorf("got error %v; want %q", err, c.want)
+		}
+	}
+}
+
+func TestPsyringe_AddToSlice_close(t *testing.T) {
+	p := New()
+	if err := p.AddToSlice(
+		Bind((*checker)(nil), func() *netChecker { return &netChecker{} }),
+		Bind((*checker)(nil), diskChecker{"/"}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	checkers, err := Get[[]checker](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !checkers[0].(*netChecker).closed {
+		t.Errorf("constructed element not closed")
+	}
+}
+
+func TestPsyringe_AddToSlice_walkers(t *testing.T) {
+	var calls Counter
+	p := New(func(s []string) int { return len(s) })
+	p.AddToSlice("a", func() string { return fmt.Sprint("b", calls.Increment()) })
+	sliceType := reflect.TypeOf([]string(nil))
+
+	if !containsType(p.KnownTypes(), sliceType) {
+		t.Errorf("KnownTypes %v does not include []string", p.KnownTypes())
+	}
+	if !containsType(p.Graph().Nodes(), sliceType) {
+		t.Errorf("Graph nodes %v do not include []string", p.Graph().Nodes())
+	}
+	if got := p.DependentsOf(sliceType); len(got) != 1 || got[0] != reflect.TypeOf(0) {
+		t.Errorf("got dependents of []string %v; want [int]", got)
+	}
+	if unused, err := p.Unused(); err != nil || !containsType(unused, sliceType) {
+		t.Errorf("got unused %v, %v; want []string included", unused, err)
+	}
+	if s := p.String(); !strings.Contains(s, "[]string [ctor]") {
+		t.Errorf("String does not list []string:\n%s", s)
+	}
+	if data, err := p.MarshalGraphJSON(); err != nil || !strings.Contains(string(data), `"type": "[]string"`) {
+		t.Errorf("MarshalGraphJSON does not include []string: %v\n%s", err, data)
+	}
+
+	if err := p.RealiseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 1 {
+		t.Errorf("RealiseAll called the element constructor %d times; want 1", calls.Value())
+	}
+	if err := p.Invalidate(sliceType); err != nil {
+		t.Fatal(err)
+	}
+	var target struct{ Strings []string }
+	p.MustInject(&target)
+	if got, want := target.Strings, []string{"a", "b2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Invalidate: got %q; want %q", got, want)
+	}
+
+	restore, err := p.ReplaceRestorable([]string{"replaced"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.Strings = nil
+	p.MustInject(&target)
+	if got, want := target.Strings, []string{"replaced"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after ReplaceRestorable: got %q; want %q", got, want)
+	}
+	restore()
+	target.Strings = nil
+	p.MustInject(&target)
+	if got, want := target.Strings, []string{"a", "b2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after restore: got %q; want %q", got, want)
+	}
+
+	if err := p.Scope("child").Remove(sliceType); err == nil || !strings.Contains(err.Error(), "added in scope <root>") {
+		t.Errorf("got error %v removing from child; want added in scope <root>", err)
+	}
+	if err := p.Remove(sliceType); err != nil {
+		t.Fatal(err)
+	}
+	if p.HasType(sliceType) {
+		t.Errorf("[]string still known after Remove")
+	}
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, got := range types {
+		if got == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package psyringe
+
+import "reflect"
+
+// Strict turns strict mode on or off for this Psyringe, and any clones or
+// scopes subsequently created from it.
+//
+// In strict mode, Inject returns an UnfilledField error for each exported
+// field which it has no value or constructor for, unless the field is tagged
+// `inject:"optional"` (or `inject:"-"`, which is never injected). The
+// NoValueForStructField hook is still called first, and if it returns an
+// error, that error is returned instead.
+func (p *Psyringe) Strict(strict bool) {
+	defer p.lock()()
+	p.strict = strict
+}
+
+// UnfilledField is the error returned by Inject in strict mode when there is no
+// value or constructor for a field not tagged `inject:"optional"`.
+type UnfilledField struct {
+	// ParentTypeName is the name of the type of struct that owns the field.
+	ParentTypeName string
+	// Field is the field that could not be injected.
+	Field reflect.StructField
+}
+
+// Error returns a message like "unable to inject field *pkg.T.Field (type)".
+func (e UnfilledField) Error() string {
+	return "unable to inject field " + e.ParentTypeName + "." + e.Field.Name +
+		" (" + e.Field.Type.String() + ")"
+}
+
+// unfilledField returns an UnfilledField error if p is strict and field is not
+// optional, otherwise nil.
+func (p *Psyringe) unfilledField(parentTypeName string, field reflect.StructField, opt injectOption) error {
+	if !p.strict || opt == injectOptional {
+		return nil
+	}
+	return UnfilledField{ParentTypeName: parentTypeName, Field: field}
+}
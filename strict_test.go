@@ -0,0 +1,99 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type strictTarget struct {
+	Int      int
+	String   string
+	Float    float64 `inject:"optional"`
+	Bool     bool
+	unexport []byte
+}
+
+func TestPsyringe_Strict(t *testing.T) {
+	p := New(1)
+	p.Strict(true)
+	target := &strictTarget{}
+	err := p.Inject(target)
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want InjectErrors", err, err)
+	}
+	want := []string{
+		"inject into *psyringe.strictTarget target failed: unable to inject field *psyringe.strictTarget.Bool (bool)",
+		"inject into *psyringe.strictTarget target failed: unable to inject field *psyringe.strictTarget.String (string)",
+	}
+	if len(ie.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ie.Errors()), len(want), ie)
+	}
+	for i, err := range ie.Errors() {
+		if err.Error() != want[i] {
+			t.Errorf("got error %d %q; want %q", i, err, want[i])
+		}
+	}
+	if target.Int != 1 {
+		t.Errorf("got Int %d; want 1", target.Int)
+	}
+}
+
+func TestPsyringe_Strict_off(t *testing.T) {
+	p := New(1)
+	p.Strict(true)
+	p.Strict(false)
+	if err := p.Inject(&strictTarget{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPsyringe_Strict_inherited(t *testing.T) {
+	p := New(1, "a string")
+	p.Strict(true)
+	for name, q := range map[string]*Psyringe{
+		"clone": p.Clone(),
+		"scope": p.Scope("child"),
+	} {
+		err := q.Inject(&strictTarget{})
+		uf, ok := errors.Cause(err).(UnfilledField)
+		if !ok {
+			t.Errorf("%s: got error %v; want UnfilledField", name, err)
+			continue
+		}
+		if uf.Field.Name != "Bool" || uf.ParentTypeName != "*psyringe.strictTarget" {
+			t.Errorf("%s: got unfilled field %s.%s; want *psyringe.strictTarget.Bool",
+				name, uf.ParentTypeName, uf.Field.Name)
+		}
+	}
+}
+
+func TestPsyringe_Strict_hookError(t *testing.T) {
+	p := New(1, "a string")
+	p.Strict(true)
+	var mu sync.Mutex
+	var fields []string
+	p.Hooks.NoValueForStructField = func(parent string, field reflect.StructField) error {
+		mu.Lock()
+		defer mu.Unlock()
+		fields = append(fields, field.Name)
+		if field.Name == "Bool" {
+			return fmt.Errorf("hook error")
+		}
+		return nil
+	}
+	err := p.Inject(&strictTarget{})
+	want := "inject into *psyringe.strictTarget target failed: hook error"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	sort.Strings(fields)
+	if !reflect.DeepEqual(fields, []string{"Bool", "Float"}) {
+		t.Errorf("got hook called for %v; want [Bool Float]", fields)
+	}
+}
@@ -0,0 +1,106 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// defaultStringLimit is how many injection types String lists by default.
+const defaultStringLimit = 50
+
+// SetStringLimit sets how many injection types String lists before
+// truncating the list. Pass a negative limit to list them all, or 0 for the
+// default of 50.
+func (p *Psyringe) SetStringLimit(limit int) {
+	defer p.lock()()
+	p.stringLimit = limit
+}
+
+// WithStringLimit is an Option doing the same as SetStringLimit.
+func WithStringLimit(limit int) Option {
+	return func(p *Psyringe) { p.stringLimit = limit }
+}
+
+// String returns a summary of p for debugging, so that fmt.Println(p) shows
+// what it holds, like:
+//
+//	psyringe "app" (scope app)
+//	1 value, 2 constructors
+//	  *main.Server [ctor]
+//	  *sql.DB [realized]
+//	  main.Config [value]
+//
+// The first line has p's name, if it has one, see SetName. Each injection type
+// added to p, not its ancestors, is listed, sorted, marked [value] if it was
+// added as a value, [realized] if its constructor has been called
+// successfully, or [ctor] otherwise. Named injection types follow their name.
+// Once SetStringLimit injection types are listed, the rest are summarised as
+// "... and N more".
+func (p *Psyringe) String() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var buf bytes.Buffer
+	buf.WriteString("psyringe ")
+	if p.name != "" {
+		fmt.Fprintf(&buf, "%q ", p.name)
+	}
+	fmt.Fprintf(&buf, "(scope %s)\n", p.scopePath())
+
+	var lines []string
+	values, ctors := 0, 0
+	describe := func(prefix string, its injectionTypes) {
+		for _, t := range its.Keys() {
+			it := its[t]
+			kind := "value"
+			switch {
+			case it.Ctor == nil:
+				values++
+			case p.realised(it.Ctor):
+				ctors++
+				kind = "realized"
+			default:
+				ctors++
+				kind = "ctor"
+			}
+			lines = append(lines, fmt.Sprintf("%s%s [%s]", prefix, t, kind))
+		}
+	}
+	describe("", p.injectionTypesWithSlices())
+	names := make([]string, 0, len(p.named))
+	for name := range p.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		describe(fmt.Sprintf("%q ", name), p.named[name])
+	}
+	fmt.Fprintf(&buf, "%s, %s\n", plural(values, "value"), plural(ctors, "constructor"))
+
+	limit := p.stringLimit
+	if limit == 0 {
+		limit = defaultStringLimit
+	}
+	for i, line := range lines {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(&buf, "  ... and %d more\n", len(lines)-limit)
+			break
+		}
+		fmt.Fprintf(&buf, "  %s\n", line)
+	}
+	return buf.String()
+}
+
+// realised returns true if c has been called successfully by p.
+func (p *Psyringe) realised(c *ctor) bool {
+	call := p.calls.lookup(c.ctorFunc)
+	return call != nil && call.realised()
+}
+
+// plural returns n and noun, made plural unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
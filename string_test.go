@@ -0,0 +1,61 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_String(t *testing.T) {
+	type Config struct{}
+	p := New(
+		WithName("app"),
+		Config{},
+		func() *bytes.Buffer { return &bytes.Buffer{} },
+		func(*bytes.Buffer) fmt.Stringer { return nil },
+	)
+	p.AddNamed("dbHost", "localhost")
+	var target struct{ Buffer *bytes.Buffer }
+	p.MustInject(&target)
+
+	const want = `psyringe "app" (scope app)
+2 values, 2 constructors
+  *bytes.Buffer [realized]
+  psyringe.Config [value]
+  fmt.Stringer [ctor]
+  "dbHost" string [value]
+`
+	if got := fmt.Sprint(p); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	child := New().Scope("request")
+	const wantChild = "psyringe (scope <root>/request)\n0 values, 0 constructors\n"
+	if got := child.String(); got != wantChild {
+		t.Errorf("got:\n%s\nwant:\n%s", got, wantChild)
+	}
+}
+
+func TestPsyringe_String_limit(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		C struct{}
+	)
+	p := New(WithStringLimit(2), A{}, B{}, C{})
+	const want = `psyringe (scope <root>)
+3 values, 0 constructors
+  psyringe.A [value]
+  psyringe.B [value]
+  ... and 1 more
+`
+	if got := p.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	p.SetStringLimit(-1)
+	if got := p.String(); !strings.HasSuffix(got, "  psyringe.C [value]\n") {
+		t.Errorf("got:\n%s\nwant every injection type", got)
+	}
+}
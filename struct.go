@@ -0,0 +1,101 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// AddStruct adds a constructor for each example, which must be a struct or a
+// pointer to one, saving writing constructors which only copy their arguments
+// into a new struct. The injection type is that of the example, and the
+// constructor injects a new value of it, so its exported fields are its
+// parameters. Fields are treated as they are when injecting: those tagged
+// `inject:"-"` are left zero, as are those tagged `inject:"optional"` when
+// there is nothing to inject into them. Test reports any other field with no
+// injection type, like a missing constructor parameter.
+//
+// Fields selecting a named injection type with a psyringe tag are not
+// supported, and AddStruct returns an error for them.
+func (p *Psyringe) AddStruct(examples ...interface{}) error {
+	return p.addStructErr(examples...)
+}
+
+// addStructErr just exists to make callerinfo consistent in
+// Psyringe.addStruct.
+func (p *Psyringe) addStructErr(examples ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	for i, example := range examples {
+		if example == nil {
+			rollback()
+			return fmt.Errorf("cannot add nil (argument %d)", i)
+		}
+		if err := p.addStruct(example); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Psyringe) addStruct(example interface{}) error {
+	t := reflect.TypeOf(example)
+	ctors, err := structCtors(t)
+	if err != nil {
+		return errors.Wrapf(err, "adding struct %s failed", t)
+	}
+	return errors.Wrapf(p.addCtors(ctors), "adding struct %s failed", t)
+}
+
+// structCtors returns the ctors for a constructor of t, a struct or pointer to
+// struct, whose parameters are its injected fields.
+func structCtors(t reflect.Type) ([]*ctor, error) {
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not a struct or pointer to struct")
+	}
+	var fields []int
+	var inTypes []reflect.Type
+	var optional []bool
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		opt, err := parseInjectTag(t.String(), field)
+		if err != nil {
+			return nil, err
+		}
+		if opt == injectNever || field.PkgPath != "" {
+			continue
+		}
+		if name := field.Tag.Get(nameTag); name != "" {
+			return nil, fmt.Errorf("field %s selects injection type named %q, which AddStruct does not support", field.Name, name)
+		}
+		fields = append(fields, i)
+		inTypes = append(inTypes, field.Type)
+		optional = append(optional, opt == injectOptional)
+	}
+	funcType := reflect.FuncOf(inTypes, []reflect.Type{t}, false)
+	fn := reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
+		v := reflect.New(st)
+		for i, field := range fields {
+			v.Elem().Field(field).Set(in[i])
+		}
+		if t.Kind() == reflect.Ptr {
+			return []reflect.Value{v}
+		}
+		return []reflect.Value{v.Elem()}
+	})
+	ctors := newCtors(funcType, fn)
+	// A leading context.Context field is passed the context of the current
+	// injection, like a constructor's first parameter, so is not among the
+	// ctor's inTypes.
+	ctors[0].optionalIn = optional[len(optional)-len(ctors[0].inTypes):]
+	return ctors, nil
+}
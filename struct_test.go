@@ -0,0 +1,105 @@
+package psyringe
+
+import (
+	"strings"
+	"testing"
+)
+
+type (
+	structDB    struct{ DSN string }
+	structCache struct{ Size int }
+	structLog   struct{}
+	service     struct {
+		DB     *structDB
+		Cache  structCache
+		Log    *structLog `inject:"optional"`
+		Ignore string     `inject:"-"`
+		hidden *structDB
+	}
+)
+
+func TestPsyringe_AddStruct(t *testing.T) {
+	db := &structDB{"dsn"}
+	p := New(db, structCache{Size: 10})
+	if err := p.AddStruct(service{}, &service{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Test(); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Value   service
+		Pointer *service
+	}
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []service{target.Value, *target.Pointer} {
+		want := service{DB: db, Cache: structCache{Size: 10}}
+		if got != want {
+			t.Errorf("got %+v; want %+v", got, want)
+		}
+	}
+}
+
+func TestPsyringe_AddStruct_optional(t *testing.T) {
+	l := &structLog{}
+	p := New(&structDB{}, structCache{}, l)
+	if err := p.AddStruct(service{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get[service](p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Log != l {
+		t.Errorf("optional field not injected when there is a value for it")
+	}
+}
+
+func TestPsyringe_AddStruct_missing(t *testing.T) {
+	p := New(&structDB{})
+	if err := p.AddStruct(service{}); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Test()
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	want := "unable to satisfy param 1: no constructor or value for psyringe.structCache"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q; want it to contain %q", err, want)
+	}
+	if _, err := Get[service](p); err == nil {
+		t.Errorf("got nil error from Get")
+	}
+}
+
+func TestPsyringe_AddStruct_errors(t *testing.T) {
+	type named struct {
+		DB *structDB `psyringe:"primary"`
+	}
+	type badTag struct {
+		DB *structDB `inject:"sometimes"`
+	}
+	cases := []struct {
+		example interface{}
+		want    string
+	}{
+		{nil, "cannot add nil (argument 0)"},
+		{1, "adding struct int failed: not a struct or pointer to struct"},
+		{new(*service), "adding struct **psyringe.service failed: not a struct or pointer to struct"},
+		{named{}, `adding struct psyringe.named failed: field DB selects injection type named "primary", which AddStruct does not support`},
+		{badTag{}, `adding struct psyringe.badTag failed: invalid inject tag "sometimes" on field psyringe.badTag.DB`},
+	}
+	for _, c := range cases {
+		p := New()
+		err := p.AddStruct(c.example)
+		if err == nil || err.Error() != c.want {
+			t.Errorf("got error %v; want %q", err, c.want)
+		}
+		if len(p.KnownTypes()) != 0 {
+			t.Errorf("got known types %v after error", p.KnownTypes())
+		}
+	}
+}
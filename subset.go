@@ -0,0 +1,130 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// SubsetFor returns a new Psyringe containing only the constructors and values
+// needed to inject each target, which must be pointers to structs. It includes
+// the injection type of each field which Inject would fill, and transitively
+// every injection type their constructors depend on, including those added to
+// p's ancestors. This is useful in tests, to work with a small graph taken from
+// a large application Psyringe.
+//
+// The subset has the same Hooks and settings as p, but is a root scope, and
+// shares no constructed values with p: its constructors are called afresh.
+//
+// If any constructor needed has a parameter p cannot satisfy, SubsetFor
+// returns a NoConstructorOrValue error naming the constructor and parameter. Fields
+// with no injection type are left out, as Inject would leave them unset.
+func (p *Psyringe) SubsetFor(targets ...interface{}) (*Psyringe, error) {
+	defer p.rlockChain()()
+	s := &subset{from: p, into: newPsyringe()}
+	s.into.Hooks = p.Hooks
+	s.into.interfaceBinding = p.interfaceBinding
+	s.into.strict = p.strict
+	for i, target := range targets {
+		v := reflect.ValueOf(target)
+		if target == nil || v.Kind() != reflect.Ptr || v.Type().Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("target %d (%T) must be a pointer to struct", i, target)
+		}
+		if err := s.addTarget(v.Type().Elem()); err != nil {
+			return nil, errors.Wrapf(err, "subset for %T failed", target)
+		}
+	}
+	return s.into, nil
+}
+
+// subset collects the injection types needed by SubsetFor.
+type subset struct {
+	from, into *Psyringe
+}
+
+// addTarget adds the injection types needed to inject struct type t.
+func (s *subset) addTarget(t reflect.Type) error {
+	plan := s.from.plan(t)
+	for _, fp := range plan.fields {
+		if fp.err != nil {
+			return fp.err
+		}
+		if fp.source == nil {
+			continue
+		}
+		if fp.name == "" {
+			if err := s.add(fp.field.Type, s.from); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.addNamed(fp.name, fp.field.Type, fp.source); err != nil {
+			return err
+		}
+		if fp.source.Ctor != nil {
+			if err := s.addParams(fp.source.Ctor, s.from.ancestor(fp.depth)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// add adds the injection type scope would use for a value of type t, and
+// everything it depends on. It returns NoConstructorOrValue if there is none.
+func (s *subset) add(t reflect.Type, scope *Psyringe) error {
+	it, depth := scope.find(t)
+	if it == nil {
+		bound, err := scope.boundType(t, scope.scopeChainInjectionTypes()...)
+		if err != nil {
+			return err
+		}
+		if bound == nil {
+			return NoConstructorOrValue{Type: t, Scope: scope.errScope()}
+		}
+		t = bound
+		it, depth = scope.find(t)
+	}
+	if existing, ok := s.into.injectionTypes[t]; ok {
+		if existing != it {
+			return fmt.Errorf("injection type %s is needed from more than one scope", t)
+		}
+		return nil
+	}
+	s.into.injectionTypes[t] = it
+	if it.Ctor == nil {
+		return nil
+	}
+	return s.addParams(it.Ctor, scope.ancestor(depth))
+}
+
+// addNamed adds named injection type t.
+func (s *subset) addNamed(name string, t reflect.Type, it *injectionType) error {
+	if existing, ok := s.into.named[name][t]; ok {
+		if existing != it {
+			return fmt.Errorf("injection type %s named %q is needed from more than one scope", t, name)
+		}
+		return nil
+	}
+	if s.into.named[name] == nil {
+		s.into.named[name] = injectionTypes{}
+	}
+	s.into.named[name][t] = it
+	return nil
+}
+
+// addParams adds the injection types satisfying each parameter of c, which
+// was added to scope.
+func (s *subset) addParams(c *ctor, scope *Psyringe) error {
+	for paramIndex, paramType := range c.inTypes {
+		err := s.add(paramType, scope)
+		if missing, ok := missingParam(err, c, paramIndex); ok {
+			return missing
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to satisfy constructor %s: unable to satisfy param %d", c.funcType, paramIndex)
+		}
+	}
+	return nil
+}
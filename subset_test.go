@@ -0,0 +1,64 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPsyringe_SubsetFor(t *testing.T) {
+	var intCalls Counter
+	root := New(
+		func() int { return int(intCalls.Increment()) },
+		func(i int) string { return "s" },
+		bytes.NewBufferString("unused"),
+		1.5,
+	)
+	root.AddNamed("name", func(s string) []byte { return []byte(s) })
+	child := root.Scope("child")
+	child.Add(func(f float64) int8 { return int8(f) })
+	var warm struct{ Int int }
+	root.MustInject(&warm)
+
+	var target struct {
+		String string
+		Int8   int8
+		Named  []byte        `psyringe:"name"`
+		Ignore *bytes.Buffer `inject:"-"`
+		Other  bool
+	}
+	sub, err := child.SubsetFor(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []reflect.Type{reflect.TypeOf(1.5), reflect.TypeOf(1), reflect.TypeOf(int8(0)), reflect.TypeOf("")}
+	if got := sub.KnownTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got known types %v; want %v", got, want)
+	}
+	sub.MustInject(&target)
+	if target.String != "s" || target.Int8 != 1 || string(target.Named) != "s" {
+		t.Errorf("got %+v", target)
+	}
+	// The int constructor is called afresh, root's value is not shared.
+	if got := intCalls.Value(); got != 2 {
+		t.Errorf("got %d int calls; want 2", got)
+	}
+}
+
+func TestPsyringe_SubsetFor_missing(t *testing.T) {
+	p := New(func(i int) string { return "" })
+	var target struct{ String string }
+	_, err := p.SubsetFor(&target)
+	want := "subset for *struct { String string } failed: unable to satisfy constructor func(int) string: unable to satisfy param 0: no constructor or value for int"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+	if _, ok := errors.Cause(err).(NoConstructorOrValue); !ok {
+		t.Errorf("got cause %T; want NoConstructorOrValue", errors.Cause(err))
+	}
+	if _, err := p.SubsetFor(target); err == nil {
+		t.Errorf("got nil error for non-pointer target")
+	}
+}
@@ -1,6 +1,7 @@
 package psyringe
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -10,6 +11,9 @@ import (
 // TestPsyringe is a Psyringe for use in testing only.
 // It allows individual constructors to be replaced,
 // which can be useful in testing scenarios.
+//
+// Deprecated: Use psyringetest.Wrap, whose TestPsyringe has the same methods,
+// and helpers taking a *testing.T, such as ReplaceScoped and Spy.
 type TestPsyringe struct {
 	*Psyringe
 }
@@ -22,19 +26,25 @@ type TestPsyringe struct {
 // When replacing a constructor that has already been called, the old value that
 // was generated is blown away and the replacement constructor will be called
 // next time it's called on to inject.
+//
+// It is like Psyringe.Replace, but panics instead of returning an error.
 func (tp *TestPsyringe) Replace(constructorsAndValues ...interface{}) {
-	for _, thing := range constructorsAndValues {
-		t := testGetInjectionType(thing)
-		if _, exists := tp.Psyringe.injectionTypes[t]; !exists {
-			panic(fmt.Errorf("attempt to replace injection type %s; but no such type added", t))
-		}
-		delete(tp.Psyringe.injectionTypes, t)
-		if err := tp.Psyringe.add(thing); err != nil {
-			panic(err)
-		}
+	if err := tp.Psyringe.replace(constructorsAndValues...); err != nil {
+		panic(err)
 	}
 }
 
+// ReplaceRestorable is like Psyringe.ReplaceRestorable, but panics instead of
+// returning an error.
+func (tp *TestPsyringe) ReplaceRestorable(constructorsAndValues ...interface{}) (restore func()) {
+	r := tp.Psyringe.newReplacement(constructorsAndValues)
+	if err := tp.Psyringe.replace(constructorsAndValues...); err != nil {
+		panic(err)
+	}
+	r.resetDependents()
+	return r.restore
+}
+
 // Realise takes a pointer (target) and tries to populate it with a value of the
 // same type from the graph. It uses the same mechanism as populating a struct
 // field when Inject is called, except the NoValueForStructField hook is never
@@ -48,11 +58,16 @@ func (tp *TestPsyringe) Replace(constructorsAndValues ...interface{}) {
 // This can be used in tests to examine a single item in the graph.
 //
 // Note: Realise can only be used for pointer types.
+//
+// Deprecated: Use Psyringe.Realise, which accepts multiple targets and always
+// realises the type target points to. This method additionally tries the type
+// of target itself first, and is kept for compatibility.
 func (tp *TestPsyringe) Realise(target interface{}) error {
 	return tp.realise(target)
 }
 
 func (tp *TestPsyringe) realise(target interface{}) error {
+	defer tp.Psyringe.rlockChain()()
 	targetVal := reflect.ValueOf(target)
 	if targetVal.Kind() != reflect.Ptr {
 		return fmt.Errorf("target must be a pointer, was a %T", target)
@@ -67,7 +82,7 @@ func (tp *TestPsyringe) realise(target interface{}) error {
 		Type: targetType,
 	}
 	val, got, err := tp.Psyringe.getValueForStructField(
-		newHooks(), fakeParentTypeName, fakeStructField)
+		context.Background(), newHooks(), fakeParentTypeName, fakeStructField)
 	if err != nil {
 		return err
 	}
@@ -77,7 +92,7 @@ func (tp *TestPsyringe) realise(target interface{}) error {
 		fakeStructField.Type = targetType.Elem()
 		var errElem error
 		val, got, errElem = tp.Psyringe.getValueForStructField(
-			newHooks(), fakeParentTypeName, fakeStructField)
+			context.Background(), newHooks(), fakeParentTypeName, fakeStructField)
 		if errElem != nil {
 			return errors.Wrapf(err, "attempting to realise %s", targetType.Elem())
 		}
@@ -93,12 +108,3 @@ func (tp *TestPsyringe) realise(target interface{}) error {
 	}
 	return nil
 }
-
-func testGetInjectionType(constructorOrValue interface{}) reflect.Type {
-	v := reflect.ValueOf(constructorOrValue)
-	t := v.Type()
-	if c := newCtor(t, v); c != nil {
-		return c.outType
-	}
-	return t
-}
@@ -106,3 +106,20 @@ func TestTestPsyringe_Realise(t *testing.T) {
 		})
 	}
 }
+
+func TestTestPsyringe_ReplaceRestorable(t *testing.T) {
+	var calls Counter
+	p := New(func() int { return int(calls.Increment()) })
+	if got := MustGet[int](p); got != 1 {
+		t.Fatalf("got %d; want 1", got)
+	}
+	tp := TestPsyringe{p}
+	restore := tp.ReplaceRestorable(2)
+	if got := MustGet[int](tp.Psyringe); got != 2 {
+		t.Errorf("got %d; want 2", got)
+	}
+	restore()
+	if got := MustGet[int](tp.Psyringe); got != 1 {
+		t.Errorf("got %d after restore; want 1", got)
+	}
+}
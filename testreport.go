@@ -0,0 +1,94 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TestReport is the error returned by Test when it finds any problems. Its
+// Error method lists every problem, one per line, each prefixed with the name
+// of the Psyringe tested, if it has one.
+type TestReport struct {
+	// Problems are the problems found, ordered by kind of problem and then by
+	// injection type name.
+	Problems []TestProblem
+	// Psyringe is the name of the Psyringe tested, see SetName, or "".
+	Psyringe string
+}
+
+// TestProblem is a single problem found by Test.
+type TestProblem struct {
+	// Constructor is the type of the constructor with the problem.
+	Constructor reflect.Type
+	// Name is the name the constructor was added with by AddNamed, or empty.
+	Name string
+	// Err is the problem itself. It is either a DependencyCycle, a
+	// NoConstructorOrValue naming Constructor and its parameter which could not
+	// be satisfied, or an error caused by AmbiguousInterfaceBinding naming that
+	// parameter.
+	Err error
+}
+
+// Error returns a message describing the problem.
+func (tp TestProblem) Error() string {
+	if _, ok := tp.Err.(DependencyCycle); ok {
+		return tp.Err.Error()
+	}
+	var missing NoConstructorOrValue
+	if errors.As(tp.Err, &missing) && missing.Constructor == tp.Constructor {
+		// The error names the constructor itself.
+		if tp.Name == "" {
+			return tp.Err.Error()
+		}
+		const format = "unable to satisfy constructor %s named %q: unable to satisfy param %d: %s"
+		return fmt.Sprintf(format, tp.Constructor, tp.Name, missing.Param, missing.typeMessage())
+	}
+	if tp.Name != "" {
+		return fmt.Sprintf("unable to satisfy constructor %s named %q: %s", tp.Constructor, tp.Name, tp.Err)
+	}
+	return fmt.Sprintf("unable to satisfy constructor %s: %s", tp.Constructor, tp.Err)
+}
+
+// Unwrap returns Err.
+func (tp TestProblem) Unwrap() error {
+	return tp.Err
+}
+
+// Unwrap returns each problem, so that errors.Is and errors.As consider all of
+// them. For example, errors.As can extract a DependencyCycle from the error
+// returned by Test.
+func (tr TestReport) Unwrap() []error {
+	errs := make([]error, len(tr.Problems))
+	for i, p := range tr.Problems {
+		errs[i] = p
+	}
+	return errs
+}
+
+// Error returns each problem on its own line.
+func (tr TestReport) Error() string {
+	messages := make([]string, len(tr.Problems))
+	for i, p := range tr.Problems {
+		messages[i] = p.Error()
+		if tr.Psyringe != "" {
+			messages[i] = fmt.Sprintf("psyringe %q: %s", tr.Psyringe, messages[i])
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add adds a problem with constructor c, named name, to tr.
+func (tr *TestReport) add(c *ctor, name string, err error) {
+	tr.Problems = append(tr.Problems, TestProblem{Constructor: c.funcType, Name: name, Err: err})
+}
+
+// errOrNil returns nil if tr has no problems, and tr otherwise.
+func (tr TestReport) errOrNil() error {
+	if len(tr.Problems) == 0 {
+		return nil
+	}
+	return tr
+}
@@ -0,0 +1,107 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// TestTargets checks that every exported field of each target, which must be
+// pointers to structs, would be injected by Inject, without calling any
+// constructors. Fields tagged `inject:"optional"` or `inject:"-"` are not
+// checked. Each field which would not be injected causes an UnfilledField
+// error, as in strict mode.
+//
+// Like Test, TestTargets is intended for use in your own tests, to ensure that
+// your targets are fully wired. It does not check that the constructors needed
+// can be satisfied, use Test for that.
+func (p *Psyringe) TestTargets(targets ...interface{}) error {
+	defer p.rlockChain()()
+	return p.forEachTarget(context.Background(), "test", targets, p.testTarget)
+}
+
+// testTarget returns an error for each field of target which would not be
+// injected, ordered by field name.
+func (p *Psyringe) testTarget(target interface{}) []error {
+	v := reflect.ValueOf(target)
+	if target == nil || v.Kind() != reflect.Ptr {
+		return invalidTarget(TargetNotPointer, reflect.TypeOf(target))
+	}
+	t := v.Type().Elem()
+	if t.Kind() != reflect.Struct {
+		return invalidTarget(TargetNotStruct, v.Type())
+	}
+	return p.testFields(t, nil)
+}
+
+// testFields returns an error for each field of struct type t which would not
+// be injected, ordered by field name. Embedded structs which are not injection
+// types are checked too, unless they are in outer, the types of the structs
+// they are embedded in.
+func (p *Psyringe) testFields(t reflect.Type, outer []reflect.Type) []error {
+	for _, o := range outer {
+		if o == t {
+			return []error{fmt.Errorf("%s contains itself", t)}
+		}
+	}
+	outer = append(outer[:len(outer):len(outer)], t)
+	parentName := reflect.PtrTo(t).String()
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		fields[i] = t.Field(i)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+	var errs []error
+	for _, field := range fields {
+		opt, err := parseInjectTag(parentName, field)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if opt == injectNever || field.PkgPath != "" {
+			continue
+		}
+		ok, err := p.canInjectField(field)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "field %s (%s)", field.Name, field.Type))
+			continue
+		}
+		if !ok && field.Anonymous && field.Tag.Get(nameTag) == "" && deepInjectable(field.Type) {
+			st := field.Type
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			for _, err := range p.testFields(st, outer) {
+				errs = append(errs, errors.Wrapf(err, "embedded field %s (%s)", field.Name, field.Type))
+			}
+			continue
+		}
+		if !ok && opt != injectOptional {
+			errs = append(errs, UnfilledField{ParentTypeName: parentName, Field: field})
+		}
+	}
+	return errs
+}
+
+// canInjectField reports whether p or its ancestors have an injection type for
+// field, without getting its value.
+func (p *Psyringe) canInjectField(field reflect.StructField) (bool, error) {
+	if name := field.Tag.Get(nameTag); name != "" {
+		_, ok := p.namedRegistrationScope(name, field.Type)
+		return ok, nil
+	}
+	if _, ok := p.injectionTypeRegistrationScope(field.Type); ok || p.hasSlice(field.Type) || p.providesFunc(field.Type) || p.providesLazy(field.Type) {
+		return true, nil
+	}
+	bound, err := p.boundType(field.Type, p.scopeChainInjectionTypes()...)
+	if bound == nil && err == nil {
+		_, how := p.adaptedType(field.Type)
+		return how != notAdapted, nil
+	}
+	return bound != nil, err
+}
@@ -0,0 +1,58 @@
+package psyringe
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type testTargetsTarget struct {
+	Int      int
+	String   string `psyringe:"other"`
+	Float    float64
+	Bool     bool `inject:"optional"`
+	Uint     uint `inject:"-"`
+	Reader   io.Reader
+	unexport complex64
+}
+
+func TestPsyringe_TestTargets(t *testing.T) {
+	var calls Counter
+	root := New(func() int { calls.Increment(); return 1 })
+	root.AddNamed("other", func() string { calls.Increment(); return "" })
+	root.EnableInterfaceBinding()
+	p := root.Scope("child")
+	p.Add(func() *bytes.Buffer { calls.Increment(); return nil })
+	p.Add(func() float64 { calls.Increment(); return 0 })
+
+	if err := p.TestTargets(&testTargetsTarget{}, (*testTargetsTarget)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 0 {
+		t.Errorf("got %d constructor calls; want 0", calls.Value())
+	}
+}
+
+func TestPsyringe_TestTargets_errors(t *testing.T) {
+	p := New(1.0, &bytes.Buffer{}, &bytes.Reader{})
+	p.EnableInterfaceBinding()
+	err := p.TestTargets(&testTargetsTarget{}, testTargetsTarget{})
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want InjectErrors", err, err)
+	}
+	want := []string{
+		"test *psyringe.testTargetsTarget target failed: unable to inject field *psyringe.testTargetsTarget.Int (int)",
+		"test *psyringe.testTargetsTarget target failed: field Reader (io.Reader): ambiguous interface binding for io.Reader: implemented by *bytes.Buffer, *bytes.Reader",
+		"test *psyringe.testTargetsTarget target failed: unable to inject field *psyringe.testTargetsTarget.String (string)",
+		"test psyringe.testTargetsTarget target failed: target must be a pointer",
+	}
+	if len(ie.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ie.Errors()), len(want), ie)
+	}
+	for i, err := range ie.Errors() {
+		if err.Error() != want[i] {
+			t.Errorf("got error %d %q; want %q", i, err, want[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package psyringe
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// SetConstructorTimeout limits how long each call to a constructor of this
+// Psyringe, and any clones or scopes subsequently created from it, may take.
+// A constructor which takes longer fails with a ConstructorTimeout error, as
+// does anything depending on it, and the context passed to a constructor
+// taking one is cancelled. Pass 0, the default, for no limit.
+//
+// The call itself cannot be stopped, so it is left running in the
+// background. Whatever it eventually returns is discarded rather than used:
+// its cleanup func is called, or else any of its values implementing
+// io.Closer are closed.
+//
+// To limit how long injection takes overall, pass a context with a deadline
+// to InjectCtx instead.
+func (p *Psyringe) SetConstructorTimeout(d time.Duration) {
+	defer p.lock()()
+	p.constructorTimeout = d
+}
+
+// WithConstructorTimeout is an Option doing the same as
+// SetConstructorTimeout.
+func WithConstructorTimeout(d time.Duration) Option {
+	return func(p *Psyringe) { p.constructorTimeout = d }
+}
+
+// constructWithin calls c's constructor, giving up with a ConstructorTimeout
+// after timeout, if it is positive.
+func (c *ctor) constructWithin(ctx context.Context, timeout time.Duration, args []reflect.Value) ([]reflect.Value, func(), error) {
+	if timeout <= 0 {
+		return c.construct(ctx, args)
+	}
+	type result struct {
+		values  []reflect.Value
+		cleanup func()
+		err     error
+	}
+	results := make(chan result, 1)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		defer cancel()
+		values, cleanup, err := c.construct(ctx, args)
+		results <- result{values, cleanup, err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.values, r.cleanup, r.err
+	case <-timer.C:
+		go func() {
+			// Discard the late result.
+			if r := <-results; r.err == nil {
+				late := &ctorCall{values: r.values, cleanup: r.cleanup}
+				late.close()
+			}
+		}()
+		return nil, nil, ConstructorTimeout{OutType: c.outType, FuncType: c.funcType, Timeout: timeout}
+	}
+}
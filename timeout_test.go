@@ -0,0 +1,72 @@
+package psyringe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type slowConn struct{ closed chan struct{} }
+
+func (c *slowConn) Close() error { close(c.closed); return nil }
+
+func TestPsyringe_SetConstructorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	conn := &slowConn{closed: make(chan struct{})}
+	ctxDone := make(chan struct{})
+	p := New(
+		func(ctx context.Context) *slowConn {
+			<-ctx.Done()
+			close(ctxDone)
+			<-release
+			return conn
+		},
+		func(c *slowConn) string { return "connected" },
+	)
+	p.SetConstructorTimeout(10 * time.Millisecond)
+	var target struct{ Status string }
+	err := p.Inject(&target)
+	const want = "inject into *struct { Status string } target failed: getting field Status (string) failed: " +
+		"invoking string constructor (func(*psyringe.slowConn) string) failed: getting argument 0 failed: " +
+		"*psyringe.slowConn constructor (func(context.Context) *psyringe.slowConn) timed out after 10ms"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+	var timeout ConstructorTimeout
+	if !errors.As(err, &timeout) || timeout.Timeout != 10*time.Millisecond {
+		t.Errorf("errors.As found no ConstructorTimeout of 10ms in %q", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(err, context.DeadlineExceeded) = false; want true")
+	}
+	select {
+	case <-ctxDone:
+	case <-time.After(time.Second):
+		t.Errorf("constructor's context not done")
+	}
+
+	// The late value is closed rather than used.
+	close(release)
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Errorf("value returned after timeout not closed")
+	}
+	if p.IsRealised(reflect.TypeOf(conn)) {
+		t.Errorf("value returned after timeout was kept")
+	}
+}
+
+func TestPsyringe_SetConstructorTimeout_fastEnough(t *testing.T) {
+	p := New(WithConstructorTimeout(time.Second), func(ctx context.Context) (int, error) {
+		return 1, ctx.Err()
+	})
+	if got, err := Get[int](p); err != nil || got != 1 {
+		t.Errorf("got %d, %v; want 1, nil", got, err)
+	}
+	if got, err := Get[int](p.Scope("child")); err != nil || got != 1 {
+		t.Errorf("scope: got %d, %v; want 1, nil", got, err)
+	}
+}
@@ -0,0 +1,62 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// AddTransient adds transient constructors to this Psyringe. Unlike those added
+// by Add, transient constructors are called every time one of their values is
+// needed: each struct field and each constructor parameter of their injection
+// type gets a fresh value. A constructor with multiple outputs is called once
+// for each output needed.
+//
+// Transient constructors may depend on values of any other injection type. A
+// normal constructor which depends on a transient one is still called at most
+// once, so it receives a single value, constructed when it was called. That
+// value is kept by clones of the Psyringe made after it was constructed, like
+// any other, but the transient constructor itself is called afresh by every
+// clone and child scope.
+//
+// Transient values are never closed by Close, so transient constructors must
+// not return a cleanup func.
+func (p *Psyringe) AddTransient(constructors ...interface{}) error {
+	return p.addTransientErr(constructors...)
+}
+
+// addTransientErr just exists to make callerinfo consistent in
+// Psyringe.addTransient.
+func (p *Psyringe) addTransientErr(constructors ...interface{}) error {
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	rollback := p.unshare()
+	for i, constructor := range constructors {
+		if constructor == nil {
+			rollback()
+			return fmt.Errorf("cannot add nil (argument %d)", i)
+		}
+		if err := p.addTransient(constructor); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Psyringe) addTransient(constructor interface{}) error {
+	v := reflect.ValueOf(constructor)
+	t := v.Type()
+	ctors := newCtors(t, v)
+	if ctors == nil {
+		return fmt.Errorf("adding transient %s failed: not a constructor", t)
+	}
+	if ctors[0].returnsCleanup {
+		return fmt.Errorf("adding transient constructor %s failed: transient constructors cannot return a cleanup func", t)
+	}
+	ctors[0].transient = true
+	return errors.Wrapf(p.addCtors(ctors), "adding transient constructor %s failed", t)
+}
@@ -0,0 +1,79 @@
+package psyringe
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPsyringe_AddTransient(t *testing.T) {
+	var calls Counter
+	p := New()
+	if err := p.AddTransient(func() int { return int(calls.Increment()) }); err != nil {
+		t.Fatal(err)
+	}
+	var target struct{ A, B int }
+	p.MustInject(&target)
+	if calls.Value() != 2 || target.A == target.B {
+		t.Errorf("got %d calls injecting %+v; want 2 calls, different values", calls.Value(), target)
+	}
+	p.MustInject(&target)
+	if calls.Value() != 4 {
+		t.Errorf("got %d calls; want 4", calls.Value())
+	}
+	if loc := p.injectionTypes[reflect.TypeOf(0)].DebugAddedLocation; !strings.Contains(loc, "transient_test.go") {
+		t.Errorf("got added location %q; want in transient_test.go", loc)
+	}
+}
+
+func TestPsyringe_AddTransient_dependencies(t *testing.T) {
+	var intCalls, stringCalls Counter
+	// The transient int depends on a singleton string, which is constructed
+	// once, and a singleton *bytes.Buffer captures a single int.
+	p := New(
+		func() string { stringCalls.Increment(); return "a" },
+		func(i int) *bytes.Buffer { return bytes.NewBufferString(strings.Repeat("b", i)) },
+	)
+	if err := p.AddTransient(func(s string) int { return int(intCalls.Increment()) }); err != nil {
+		t.Fatal(err)
+	}
+	var d dependent
+	p.MustInject(&d)
+	p.MustInject(&d)
+	if intCalls.Value() != 3 || stringCalls.Value() != 1 {
+		t.Errorf("got %d int calls and %d string calls; want 3 and 1", intCalls.Value(), stringCalls.Value())
+	}
+	if d.Buffer.Len() != 1 && d.Buffer.Len() != 2 {
+		t.Errorf("got buffer %q; want one or two bs", d.Buffer)
+	}
+	buffer := d.Buffer
+
+	// Clones call transient constructors afresh, but keep realised
+	// singletons.
+	clone := p.Clone()
+	clone.MustInject(&d)
+	if intCalls.Value() != 4 || d.Buffer != buffer {
+		t.Errorf("clone: got %d int calls, buffer %p; want 4 calls, buffer %p", intCalls.Value(), d.Buffer, buffer)
+	}
+}
+
+func TestPsyringe_AddTransient_errors(t *testing.T) {
+	p := New(1)
+	testCases := []struct {
+		constructor interface{}
+		want        string
+	}{
+		{"hello", "adding transient string failed: not a constructor"},
+		{func() (string, func()) { return "", nil },
+			"adding transient constructor func() (string, func()) failed: transient constructors cannot return a cleanup func"},
+		{func() int { return 0 },
+			"adding transient constructor func() int failed: injection type int already registered at "},
+	}
+	for _, tc := range testCases {
+		err := p.AddTransient(tc.constructor)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("got error %v; want %q", err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SetTTL makes the value of an injection type added to this Psyringe by a
+// constructor go stale ttl after the constructor returned it, so that the
+// constructor is called again the next time the value is needed. The example
+// is either a reflect.Type, or an example value of the injection type. A
+// constructor with multiple outputs goes stale for all of them. Pass 0 for no
+// TTL, the default.
+//
+// Only the value itself is constructed again: values of other constructors,
+// constructed from the stale value, are not, and keep using it. Fields and
+// parameters of provider func or Lazy types get the fresh value each time they
+// are called. The stale value is not closed, since it may still be in use.
+//
+// It returns an error if the injection type was not added to this Psyringe,
+// or was added as a value.
+func (p *Psyringe) SetTTL(example interface{}, ttl time.Duration) error {
+	if example == nil {
+		return fmt.Errorf("cannot set TTL of nil")
+	}
+	t, ok := example.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(example)
+	}
+	if err := p.frozenErr(); err != nil {
+		return err
+	}
+	defer p.lock()()
+	it, ok := p.injectionTypes[t]
+	if !ok {
+		return fmt.Errorf("cannot set TTL of %s: not added", t)
+	}
+	if it.Ctor == nil {
+		return fmt.Errorf("cannot set TTL of %s: added as a value", t)
+	}
+	// Copied, since clones share ttls.
+	ttls := make(map[reflect.Type]time.Duration, len(p.ttls)+len(it.Ctor.outTypes))
+	for out, d := range p.ttls {
+		ttls[out] = d
+	}
+	for _, out := range it.Ctor.outTypes {
+		if ttl > 0 {
+			ttls[out] = ttl
+		} else {
+			delete(ttls, out)
+		}
+	}
+	p.ttls = ttls
+	return nil
+}
+
+// renewExpired returns call, the call of f, unless it succeeded more than ttl
+// ago, in which case it is replaced by a new call, which is returned instead.
+func (cs *ctorCalls) renewExpired(f *ctorFunc, call *ctorCall, ttl time.Duration) *ctorCall {
+	if ttl <= 0 || !call.realised() || time.Since(call.at) < ttl {
+		return call
+	}
+	cs.Lock()
+	// Another goroutine may have renewed it already.
+	if cs.calls[f] == call {
+		delete(cs.calls, f)
+	}
+	cs.Unlock()
+	return cs.get(f)
+}
@@ -0,0 +1,50 @@
+package psyringe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPsyringe_SetTTL(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() int { return int(calls.Increment()) },
+		func(i int) string { return "built once" },
+	)
+	if err := p.SetTTL(0, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	var target struct {
+		Int    int
+		String string
+		Fresh  func() int
+	}
+	p.MustInject(&target)
+	p.MustInject(&target)
+	if target.Int != 1 {
+		t.Errorf("got %d; want 1", target.Int)
+	}
+	time.Sleep(100 * time.Millisecond)
+	p.MustInject(&target)
+	if target.Int != 2 {
+		t.Errorf("got %d after TTL; want 2", target.Int)
+	}
+	if got := target.Fresh(); got != 2 {
+		t.Errorf("provider got %d; want 2", got)
+	}
+	if calls.Value() != 2 {
+		t.Errorf("constructor called %d times; want 2", calls.Value())
+	}
+}
+
+func TestPsyringe_SetTTL_errors(t *testing.T) {
+	p := New(1.5)
+	for example, want := range map[interface{}]string{
+		1.5: "cannot set TTL of float64: added as a value",
+		"":  "cannot set TTL of string: not added",
+	} {
+		if err := p.SetTTL(example, time.Second); err == nil || err.Error() != want {
+			t.Errorf("got error %v; want %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package psyringe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Unused returns every injection type added to p or any of its ancestors
+// which injecting into targets would never need, sorted by name. Each target
+// is either a pointer to a struct, which may be nil, or the reflect.Type of
+// one. It is intended for your own tests, to catch constructors nothing
+// injects any more:
+//
+//	unused, err := p.Unused((*Server)(nil), (*Worker)(nil))
+//	if err != nil || len(unused) != 0 {
+//		t.Errorf("unused: %v, %v", unused, err)
+//	}
+//
+// An injection type is needed if a field of a target gets it, including a
+// field tagged `inject:"optional"`, or gets a provider func or Lazy for it,
+// or if the constructor of a needed injection type has a parameter getting
+// it, and so on. Embedded structs are followed as Inject follows them. Other
+// injection types of a constructor with multiple outputs count as needed too,
+// since the constructor cannot be removed. Fields with no injection type, left
+// to the NoValueForStructField hook, are otherwise ignored, as are values got
+// from a Resolver, which cannot be known in advance. Named injection types
+// are never included.
+//
+// It returns an error for a target which is not a pointer to a struct, or with
+// an invalid tag, or an ambiguous interface binding, but not for missing
+// injection types, see Test and TestTargets for those.
+func (p *Psyringe) Unused(targets ...interface{}) ([]reflect.Type, error) {
+	defer p.rlockChain()()
+	u := &unusedFinder{
+		p:       p,
+		needed:  map[reflect.Type]bool{},
+		ctors:   map[*ctorFunc]bool{},
+		visited: map[planned]bool{},
+	}
+	var errs InjectErrors
+	for _, target := range targets {
+		t, ok := target.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(target)
+		}
+		for _, err := range u.target(t) {
+			errs = append(errs, errors.Wrapf(err, "finding types used by %s target failed", t))
+		}
+	}
+	if err := errs.errOrNil(); err != nil {
+		return nil, err
+	}
+	unused := []reflect.Type{}
+	for _, v := range p.visibleInjectionTypes(false) {
+		if u.needed[v.t] || (v.it.Ctor != nil && u.ctors[v.it.Ctor.ctorFunc]) {
+			continue
+		}
+		unused = append(unused, v.t)
+	}
+	sortTypes(unused)
+	return unused, nil
+}
+
+// unusedFinder records the injection types needed by targets, for Unused.
+type unusedFinder struct {
+	p *Psyringe
+	// needed holds each injection type needed.
+	needed map[reflect.Type]bool
+	// ctors holds each constructor needed.
+	ctors map[*ctorFunc]bool
+	// visited holds each constructor call whose parameters have been
+	// followed.
+	visited map[planned]bool
+}
+
+// target records the injection types needed by the fields of target type t,
+// returning an error for each field which cannot be followed.
+func (u *unusedFinder) target(t reflect.Type) []error {
+	if t == nil || t.Kind() != reflect.Ptr {
+		return invalidTarget(TargetNotPointer, t)
+	}
+	if t.Elem().Kind() != reflect.Struct {
+		return invalidTarget(TargetNotStruct, t)
+	}
+	return u.fields(t.Elem(), nil)
+}
+
+// fields records the injection types needed by the fields of struct type t,
+// which is embedded in outer, following them as Psyringe.injectField does.
+func (u *unusedFinder) fields(t reflect.Type, outer []reflect.Type) []error {
+	p := u.p
+	for _, o := range outer {
+		if o == t {
+			return []error{fmt.Errorf("%s contains itself", t)}
+		}
+	}
+	outer = append(outer[:len(outer):len(outer)], t)
+	var errs []error
+	for _, fp := range p.plan(t).fields {
+		field := fp.field
+		switch {
+		case fp.err != nil:
+			errs = append(errs, fp.err)
+		case fp.opt == injectNever || field.PkgPath != "" || p.providesFromContext(field.Type) || p.providesSelf(field.Type):
+		case fp.name != "":
+			if fp.source != nil && fp.source.Ctor != nil {
+				if err := u.ctor(fp.source.Ctor, p.ancestor(fp.depth)); err != nil {
+					errs = append(errs, errors.Wrapf(err, "field %s (%s named %q)", field.Name, field.Type, fp.name))
+				}
+			}
+		case field.Anonymous && fp.source == nil && deepInjectable(field.Type):
+			st := field.Type
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			for _, err := range u.fields(st, outer) {
+				errs = append(errs, errors.Wrapf(err, "embedded field %s (%s)", field.Name, field.Type))
+			}
+		default:
+			if err := u.need(p, field.Type); err != nil {
+				errs = append(errs, errors.Wrapf(err, "field %s (%s)", field.Name, field.Type))
+			}
+		}
+	}
+	return errs
+}
+
+// need records injection type t as needed in scope s, and anything its
+// constructor needs. A provider func or Lazy for t needs t.
+func (u *unusedFinder) need(s *Psyringe, t reflect.Type) error {
+	it, depth := s.find(t)
+	if it == nil {
+		bound, err := s.boundType(t, s.scopeChainInjectionTypes()...)
+		if err != nil {
+			return err
+		}
+		if bound != nil {
+			return u.need(s, bound)
+		}
+		if at, _ := s.adaptedType(t); at != nil {
+			return u.need(s, at)
+		}
+		if elem, _, ok := providerType(t); ok {
+			return u.need(s, elem)
+		}
+		if t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(tlazy) {
+			return u.need(s, reflect.New(t).Interface().(lazy).lazyType())
+		}
+		return nil
+	}
+	u.needed[t] = true
+	if it.Ctor == nil {
+		return nil
+	}
+	return u.ctor(it.Ctor, s.ancestor(depth))
+}
+
+// ctor records the constructor c, called in scope s, as needed, and anything
+// its parameters need.
+func (u *unusedFinder) ctor(c *ctor, s *Psyringe) error {
+	u.ctors[c.ctorFunc] = true
+	key := planned{scope: s, f: c.ctorFunc}
+	if u.visited[key] {
+		return nil
+	}
+	u.visited[key] = true
+	for i, in := range c.inTypes {
+		if err := u.need(s, in); err != nil {
+			return errors.Wrapf(err, "argument %d of %s constructor (%s)", i, c.outType, c.funcType)
+		}
+	}
+	return nil
+}
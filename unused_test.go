@@ -0,0 +1,88 @@
+package psyringe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	unusedA    struct{}
+	unusedB    struct{}
+	unusedC    struct{}
+	unusedDead struct{}
+	unusedLazy struct{}
+)
+
+type UnusedEmbedded struct {
+	C *unusedC `inject:"optional"`
+}
+
+type unusedTarget struct {
+	UnusedEmbedded
+	A      *unusedA
+	Lazy   Lazy[*unusedLazy]
+	Named  int `psyringe:"port"`
+	NoType float64
+}
+
+func TestPsyringe_Unused(t *testing.T) {
+	p := New(
+		func(b *unusedB) *unusedA { return &unusedA{} },
+		func(s string) (*unusedB, *unusedDead) { return &unusedB{}, &unusedDead{} },
+		func() *unusedC { return &unusedC{} },
+		&unusedLazy{},
+		"a string",
+		true,
+		func(n uint) []byte { return nil },
+	)
+	p.AddNamed("port", func(n uint) int { return int(n) })
+	p.Add(uint(80))
+
+	for _, target := range []interface{}{(*unusedTarget)(nil), reflect.TypeOf(&unusedTarget{})} {
+		got, err := p.Unused(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []reflect.Type{reflect.TypeOf([]byte(nil)), reflect.TypeOf(true)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unused(%v) = %v; want %v", target, got, want)
+		}
+	}
+
+	got, err := p.Unused()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 9 {
+		t.Errorf("Unused() = %v; want all 9 types", got)
+	}
+
+	_, err = p.Unused(unusedTarget{})
+	if err == nil || !strings.Contains(err.Error(), "target must be a pointer") {
+		t.Errorf("got error %v; want target must be a pointer", err)
+	}
+}
+
+func TestPsyringe_Unused_scope(t *testing.T) {
+	root := New(func() *unusedA { return &unusedA{} }, &unusedB{})
+	child := root.Scope("child")
+	if err := child.AddShadow(func(b *unusedB) *unusedA { return &unusedA{} }); err != nil {
+		t.Fatal(err)
+	}
+	var target struct{ A *unusedA }
+	got, err := child.Unused(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v; want nothing unused", got)
+	}
+	got, err = root.Unused(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []reflect.Type{reflect.TypeOf(&unusedB{})}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Warmup calls the constructors of the injection types given, and of all their
+// dependencies, concurrently as Inject would, so that later injections use the
+// cached values. Each argument is either a reflect.Type, or any other value
+// whose type is the injection type to warm up. For example, to warm up
+// injection type *sql.DB, pass (*sql.DB)(nil).
+//
+// It is an error if any type given is not a known injection type. If more than
+// one type fails, all errors are returned together as InjectErrors, ordered by
+// type name. If any argument is nil, nothing is warmed up, and an
+// InvalidTargetError for each is returned instead.
+func (p *Psyringe) Warmup(types ...interface{}) error {
+	var nils InjectErrors
+	for i, t := range types {
+		if t == nil {
+			nils = append(nils, errors.Wrapf(InvalidTargetError{Kind: TargetNil}, "warming up argument %d failed", i))
+		}
+	}
+	if err := nils.errOrNil(); err != nil {
+		return err
+	}
+	defer p.rlockChain()()
+	targets := make([]warmupTarget, len(types))
+	for i, t := range types {
+		if rt, ok := t.(reflect.Type); ok {
+			targets[i].t = rt
+			continue
+		}
+		targets[i].t = reflect.TypeOf(t)
+	}
+	return p.warmup(targets)
+}
+
+// RealiseAll calls every constructor added to this Psyringe, including named
+// constructors, which has not already been called. Constructors are called
+// concurrently as Inject would, and errors are returned as by Warmup.
+func (p *Psyringe) RealiseAll() error {
+	defer p.rlockChain()()
+	var targets []warmupTarget
+	for _, t := range p.injectionTypesWithSlices().AddedAsCtors().Keys() {
+		targets = append(targets, warmupTarget{t: t})
+	}
+	for name, its := range p.named {
+		for _, t := range its.AddedAsCtors().Keys() {
+			targets = append(targets, warmupTarget{t: t, name: name})
+		}
+	}
+	return p.warmup(targets)
+}
+
+// warmupTarget is an injection type to be warmed up, with its name if it is a
+// named injection type.
+type warmupTarget struct {
+	t    reflect.Type
+	name string
+}
+
+func (wt warmupTarget) String() string {
+	if wt.name == "" {
+		return wt.t.String()
+	}
+	return fmt.Sprintf("%s named %q", wt.t, wt.name)
+}
+
+// warmup gets the value of each target concurrently, and returns any errors
+// ordered by target.
+func (p *Psyringe) warmup(targets []warmupTarget) error {
+	ctx := p.limited(context.Background())
+	targetErrs := make([]error, len(targets))
+	each(ctx, len(targets), func(i int) {
+		wt := targets[i]
+		p.debug(DebugEvent{Kind: DebugWarmingUp, Type: wt.t, Name: wt.name})
+		var ok bool
+		var err error
+		if wt.name == "" {
+			_, ok, err = p.resolve(ctx, wt.t)
+		} else {
+			_, ok, err = p.resolveNamed(ctx, wt.name, wt.t)
+		}
+		if !ok {
+			err = NoConstructorOrValue{Type: wt.t, Scope: p.errScope()}
+		}
+		if err != nil {
+			targetErrs[i] = errors.Wrapf(err, "warming up %s failed", wt)
+		}
+	})
+	order := make([]int, len(targets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return targets[order[i]].String() < targets[order[j]].String()
+	})
+	var errs InjectErrors
+	for _, i := range order {
+		if targetErrs[i] != nil {
+			errs = append(errs, targetErrs[i])
+		}
+	}
+	return errs.errOrNil()
+}
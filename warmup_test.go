@@ -0,0 +1,154 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPsyringe_Warmup(t *testing.T) {
+	var bufCalls, intCalls Counter
+	p := New(
+		func() *bytes.Buffer {
+			bufCalls.Increment()
+			return bytes.NewBufferString("hello")
+		},
+		func(b *bytes.Buffer) int {
+			intCalls.Increment()
+			return b.Len()
+		},
+		func() string { panic("string constructor should not be called") },
+	)
+
+	if err := p.Warmup(reflect.TypeOf(0)); err != nil {
+		t.Fatal(err)
+	}
+	if bufCalls.Value() != 1 || intCalls.Value() != 1 {
+		t.Fatalf("got %d *bytes.Buffer and %d int constructor calls; want 1 each",
+			bufCalls.Value(), intCalls.Value())
+	}
+
+	var target struct {
+		Buffer *bytes.Buffer
+		Int    int
+	}
+	if err := p.Realise(&target.Buffer, &target.Int); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Warmup((*bytes.Buffer)(nil), 0); err != nil {
+		t.Fatal(err)
+	}
+	if bufCalls.Value() != 1 || intCalls.Value() != 1 {
+		t.Errorf("got %d *bytes.Buffer and %d int constructor calls; want 1 each",
+			bufCalls.Value(), intCalls.Value())
+	}
+	if target.Int != 5 {
+		t.Errorf("got int %d; want 5", target.Int)
+	}
+}
+
+func TestPsyringe_Warmup_errors(t *testing.T) {
+	p := New(
+		func() (int, error) { return 0, fmt.Errorf("int error") },
+		func() (string, error) { return "", fmt.Errorf("string error") },
+	)
+	err := p.Warmup("", 0, 1.0)
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want InjectErrors", err, err)
+	}
+	want := []string{
+		"warming up float64 failed: no constructor or value for float64",
+		"warming up int failed: invoking int constructor (func() (int, error)) failed: int error",
+		"warming up string failed: invoking string constructor (func() (string, error)) failed: string error",
+	}
+	if len(ie.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ie.Errors()), len(want), ie)
+	}
+	for i, err := range ie.Errors() {
+		if err.Error() != want[i] {
+			t.Errorf("got error %d %q; want %q", i, err, want[i])
+		}
+	}
+
+	// Errors are memoized like any other constructor outcome.
+	var target struct{ Int int }
+	err = p.Inject(&target)
+	wantInject := "inject into *struct { Int int } target failed: getting field Int (int) failed: invoking int constructor (func() (int, error)) failed: int error"
+	if err == nil || err.Error() != wantInject {
+		t.Errorf("got error %v; want %q", err, wantInject)
+	}
+}
+
+func TestPsyringe_RealiseAll(t *testing.T) {
+	var calls Counter
+	p := New(
+		func() *bytes.Buffer {
+			calls.Increment()
+			return bytes.NewBufferString("hello")
+		},
+		func(b *bytes.Buffer) int {
+			calls.Increment()
+			return b.Len()
+		},
+		"a string",
+	)
+	p.AddNamed("other", func(b *bytes.Buffer) *bytes.Buffer {
+		calls.Increment()
+		return bytes.NewBufferString("other")
+	})
+	if err := p.RealiseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 3 {
+		t.Errorf("got %d constructor calls; want 3", calls.Value())
+	}
+	if err := p.RealiseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Value() != 3 {
+		t.Errorf("got %d constructor calls after second RealiseAll; want 3", calls.Value())
+	}
+}
+
+func TestPsyringe_RealiseAll_errors(t *testing.T) {
+	p := New(func() (int, error) { return 0, fmt.Errorf("int error") })
+	p.AddNamed("n", func() (int, error) { return 0, fmt.Errorf("named error") })
+	err := p.RealiseAll()
+	ie, ok := err.(InjectErrors)
+	if !ok {
+		t.Fatalf("got error %v (%T); want InjectErrors", err, err)
+	}
+	want := []string{
+		"warming up int failed: invoking int constructor (func() (int, error)) failed: int error",
+		"warming up int named \"n\" failed: invoking int constructor (func() (int, error)) failed: named error",
+	}
+	if len(ie.Errors()) != len(want) {
+		t.Fatalf("got %d errors; want %d:\n%s", len(ie.Errors()), len(want), ie)
+	}
+	for i, err := range ie.Errors() {
+		if err.Error() != want[i] {
+			t.Errorf("got error %d %q; want %q", i, err, want[i])
+		}
+	}
+}
+
+func TestPsyringe_Warmup_nil(t *testing.T) {
+	var calls Counter
+	p := New(func() int { return int(calls.Increment()) })
+	err := p.Warmup(nil, 0, nil)
+	const want = "warming up argument 0 failed: target is nil\nwarming up argument 2 failed: target is nil"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+	var ite InvalidTargetError
+	if !errors.As(err.(InjectErrors).Errors()[1], &ite) || ite.Kind != TargetNil {
+		t.Errorf("got %#v; want InvalidTargetError", err)
+	}
+	if calls.Value() != 0 {
+		t.Errorf("warmed up despite nil argument")
+	}
+}
@@ -0,0 +1,74 @@
+package psyringe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Warning wraps an error returned by a constructor which only partly
+// succeeded, so that its value is used rather than it failing:
+//
+//	func NewCache(db *DB) (*Cache, error) {
+//		c := &Cache{db: db}
+//		if err := c.Warm(); err != nil {
+//			return c, psyringe.Warning{Err: err}
+//		}
+//		return c, nil
+//	}
+//
+// Any error implementing InjectionWarning, returning true, is treated the same
+// way, as is any error wrapping one. The warning is passed to the
+// ConstructorWarning hook, and included in the report from InjectWithReport,
+// but injection carries on as if the constructor had returned no error.
+type Warning struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error.
+func (w Warning) Error() string {
+	if w.Err == nil {
+		return "warning"
+	}
+	return w.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (w Warning) Unwrap() error {
+	return w.Err
+}
+
+// InjectionWarning returns true.
+func (w Warning) InjectionWarning() bool {
+	return true
+}
+
+// injectionWarning is implemented by errors which may be warnings, see
+// Warning.
+type injectionWarning interface {
+	InjectionWarning() bool
+}
+
+// isWarning reports whether err is a warning, see Warning.
+func isWarning(err error) bool {
+	var w injectionWarning
+	return errors.As(err, &w) && w.InjectionWarning()
+}
+
+// warningsKey is the context key for the injectReport collecting warnings,
+// see InjectWithReport.
+type warningsKey struct{}
+
+// warn passes err, a warning returned by c's constructor, to the
+// ConstructorWarning hook and any report being collected in ctx.
+func (c *ctor) warn(ctx context.Context, s *Psyringe, err error) {
+	if s.Hooks.ConstructorWarning != nil {
+		s.Hooks.ConstructorWarning(c.outType, err)
+	}
+	if r, ok := ctx.Value(warningsKey{}).(*injectReport); ok {
+		r.Lock()
+		r.warnings = append(r.warnings, fmt.Sprintf("%s: %s", c.outType, err))
+		r.Unlock()
+	}
+}
@@ -0,0 +1,138 @@
+package psyringe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testWarning struct{ warn bool }
+
+func (w testWarning) Error() string          { return "test warning" }
+func (w testWarning) InjectionWarning() bool { return w.warn }
+
+func TestPsyringe_Inject_warning(t *testing.T) {
+	cases := map[string]error{
+		"Warning":         Warning{Err: fmt.Errorf("cache is cold")},
+		"wrapped Warning": errors.Wrap(Warning{Err: fmt.Errorf("cache is cold")}, "warming"),
+		"custom":          testWarning{warn: true},
+	}
+	for name, warning := range cases {
+		t.Run(name, func(t *testing.T) {
+			var calls Counter
+			var mu sync.Mutex
+			var warned []reflect.Type
+			var constructedErr error
+			p := New(func() (*bytes.Buffer, error) {
+				calls.Increment()
+				return bytes.NewBufferString("hello"), warning
+			})
+			p.Hooks.ConstructorWarning = func(outType reflect.Type, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				warned = append(warned, outType)
+				if err != warning {
+					t.Errorf("got warning %v; want %v", err, warning)
+				}
+			}
+			p.Hooks.ValueConstructed = func(_ reflect.Type, _ reflect.Value, _ time.Duration, err error) error {
+				constructedErr = err
+				return nil
+			}
+			var target struct{ Buffer *bytes.Buffer }
+			if err := p.Inject(&target); err != nil {
+				t.Fatal(err)
+			}
+			if target.Buffer == nil || target.Buffer.String() != "hello" {
+				t.Errorf("got %v; want hello buffer", target.Buffer)
+			}
+			if err := p.Inject(&target); err != nil {
+				t.Fatal(err)
+			}
+			if calls.Value() != 1 {
+				t.Errorf("constructor called %d times; want 1", calls.Value())
+			}
+			if len(warned) != 1 || warned[0] != reflect.TypeOf((*bytes.Buffer)(nil)) {
+				t.Errorf("got warnings for %v; want one for *bytes.Buffer", warned)
+			}
+			if constructedErr != nil {
+				t.Errorf("ValueConstructed got error %v; want nil", constructedErr)
+			}
+		})
+	}
+}
+
+func TestPsyringe_Inject_warningNotWarning(t *testing.T) {
+	cases := map[string]error{
+		"plain error":            fmt.Errorf("failed"),
+		"InjectionWarning false": testWarning{warn: false},
+	}
+	for name, ctorErr := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(func() (*bytes.Buffer, error) { return bytes.NewBuffer(nil), ctorErr })
+			warned := false
+			p.Hooks.ConstructorWarning = func(reflect.Type, error) { warned = true }
+			var target struct{ Buffer *bytes.Buffer }
+			if err := p.Inject(&target); errors.Cause(err) != ctorErr {
+				t.Errorf("got error %v; want %v", err, ctorErr)
+			}
+			if warned {
+				t.Errorf("ConstructorWarning hook called for an error")
+			}
+		})
+	}
+}
+
+func TestPsyringe_Inject_warningBound(t *testing.T) {
+	p := New(Bind((*io.Writer)(nil), func() (*bytes.Buffer, error) {
+		return bytes.NewBufferString("hello"), Warning{Err: fmt.Errorf("partial")}
+	}))
+	var target struct{ Writer io.Writer }
+	if err := p.Inject(&target); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := target.Writer.(*bytes.Buffer); !ok || b.String() != "hello" {
+		t.Errorf("got %v; want hello buffer", target.Writer)
+	}
+}
+
+func TestPsyringe_InjectWithReport_warnings(t *testing.T) {
+	p := New(
+		func() (*bytes.Buffer, error) {
+			return bytes.NewBufferString("hello"), Warning{Err: fmt.Errorf("cache is cold")}
+		},
+		"a string",
+	)
+	var target struct {
+		Buffer *bytes.Buffer
+		String string
+	}
+	report, err := p.InjectWithReport(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"*bytes.Buffer: cache is cold"}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("got warnings %q; want %q", report.Warnings, want)
+	}
+	if !strings.HasSuffix(report.String(), "\nwarning: *bytes.Buffer: cache is cold\n") {
+		t.Errorf("report does not end with warning:\n%s", report)
+	}
+
+	// The constructor has already been called, so there is nothing to warn
+	// about this time.
+	report, err = p.InjectWithReport(&target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("got warnings %q; want none", report.Warnings)
+	}
+}